@@ -20,6 +20,9 @@ import (
 // Version is set by main package from embedded version file
 var Version string
 
+// shutdownTimeout is how long each shutdown step may take.
+const shutdownTimeout = 5 * time.Second
+
 // Start initializes and runs the application with enhanced logging for startup failure scenarios.
 func Start() error {
 	logger := server.SetupLogger()
@@ -62,6 +65,10 @@ func Start() error {
 	go services.WebSocketHub.Run()
 	logger.Info("WebSocket hub started")
 
+	// Start background character refreshes and farm monitoring
+	services.Scheduler.Start()
+	services.FarmService.Start()
+
 	r := server.SetupHandlers(cfg.SecretKey, logger, services, cfg.BasePath)
 	srv, listener, err := createServerWithListener(r, cfg.Port, logger)
 	if err != nil {
@@ -149,15 +156,25 @@ func runServer(srv *http.Server, listener net.Listener, logger interfaces.Logger
 
 	logger.Info("Initiating graceful shutdown")
 
-	// Shutdown WebSocket hub first
+	// Stop the background work first so nothing broadcasts to the hub or
+	// saves data after they are gone.
+	if services != nil && services.Scheduler != nil {
+		logger.Info("Stopping scheduler...")
+		if err := shutdownWithTimeout(services.Scheduler.Shutdown); err != nil {
+			logger.WithError(err).Warn("Scheduler tasks still running at shutdown")
+		}
+	}
+	if services != nil && services.FarmService != nil {
+		logger.Info("Stopping farm monitor...")
+		if err := shutdownWithTimeout(services.FarmService.Shutdown); err != nil {
+			logger.WithError(err).Warn("Farm check still running at shutdown")
+		}
+	}
 	if services != nil && services.WebSocketHub != nil {
 		logger.Info("Shutting down WebSocket hub...")
 		services.WebSocketHub.Shutdown()
 	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	if err := srv.Shutdown(ctx); err != nil {
+	if err := shutdownWithTimeout(srv.Shutdown); err != nil {
 		logger.WithError(err).Error("Error during server shutdown")
 		return fmt.Errorf("server forced to shutdown: %w", err)
 	}
@@ -165,3 +182,11 @@ func runServer(srv *http.Server, listener net.Listener, logger interfaces.Logger
 	logger.Infof("Server shutdown completed gracefully after %s", elapsed)
 	return nil
 }
+
+// shutdownWithTimeout runs a shutdown step, giving it shutdownTimeout to
+// finish.
+func shutdownWithTimeout(shutdown func(context.Context) error) error {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	return shutdown(ctx)
+}
@@ -143,7 +143,7 @@ func (h *AuthHandler) CallBack() http.HandlerFunc {
 			return
 		}
 
-		user, err := h.esiAPIService.GetUserInfo(token)
+		user, err := h.esiAPIService.GetUserInfo(r.Context(), token)
 		if err != nil {
 			h.logger.Errorf("Failed to get user info: %v", err)
 			handleErrorWithRedirect(w, r, "/")
@@ -163,7 +163,7 @@ func (h *AuthHandler) CallBack() http.HandlerFunc {
 		// Refresh the character to fetch ESI data
 		h.logger.Infof("Fetching ESI data for character %s (ID: %d)", user.CharacterName, user.CharacterID)
 		if h.characterService != nil {
-			updated, err := h.characterService.RefreshCharacterData(user.CharacterID)
+			updated, err := h.characterService.RefreshCharacterData(r.Context(), user.CharacterID)
 			if err != nil {
 				h.logger.Errorf("Failed to fetch ESI data: %v", err)
 			} else if updated {
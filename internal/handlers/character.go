@@ -15,6 +15,7 @@ type CharacterHandler struct {
 	characterService interfaces.CharacterService
 	esiAPIService    interfaces.ESIAPIService
 	cache            interfaces.HTTPCacheService
+	wsHub            *WebSocketHub
 }
 
 func NewCharacterHandler(
@@ -22,12 +23,14 @@ func NewCharacterHandler(
 	cs interfaces.CharacterService,
 	esi interfaces.ESIAPIService,
 	c interfaces.HTTPCacheService,
+	wsHub *WebSocketHub,
 ) *CharacterHandler {
 	return &CharacterHandler{
 		logger:           l,
 		characterService: cs,
 		esiAPIService:    esi,
 		cache:            c,
+		wsHub:            wsHub,
 	}
 }
 
@@ -37,7 +40,7 @@ func (h *CharacterHandler) GetCharacter() http.HandlerFunc {
 		vars := mux.Vars(r)
 		characterID := vars["id"]
 
-		character, err := h.esiAPIService.GetCharacter(characterID)
+		character, err := h.esiAPIService.GetCharacter(r.Context(), characterID)
 		if err != nil {
 			respondError(w, "Character not found", http.StatusNotFound)
 			return
@@ -63,7 +66,7 @@ func (h *CharacterHandler) UpdateCharacterRESTful() http.HandlerFunc {
 			return
 		}
 
-		if update.Role == nil && update.MCT == nil {
+		if update.Role == nil && update.MCT == nil && update.Farm == nil {
 			respondError(w, "No updates provided", http.StatusBadRequest)
 			return
 		}
@@ -107,7 +110,7 @@ func (h *CharacterHandler) RefreshCharacter() http.HandlerFunc {
 		}
 
 		// Use EVE data service to refresh the character
-		updated, err := h.characterService.RefreshCharacterData(characterID)
+		updated, err := h.characterService.RefreshCharacterData(r.Context(), characterID)
 		if err != nil {
 			if err.Error() == "character not found" {
 				respondError(w, "Character not found", http.StatusNotFound)
@@ -134,3 +137,36 @@ func (h *CharacterHandler) RefreshCharacter() http.HandlerFunc {
 		})
 	}
 }
+
+// RESTful endpoint: POST /api/characters/refresh-all
+// Refreshes every character, streaming each one's progress over the WebSocket
+// as "character:refresh-progress". The body is optional.
+func (h *CharacterHandler) RefreshAllCharacters() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Concurrency int `json:"concurrency"`
+		}
+		if r.ContentLength != 0 {
+			if err := decodeJSONBody(r, &req); err != nil {
+				respondError(w, "Invalid request body", http.StatusBadRequest)
+				return
+			}
+		}
+
+		progress := func(update model.CharacterRefreshProgress) {
+			if h.wsHub != nil {
+				h.wsHub.BroadcastUpdate("character:refresh-progress", update)
+			}
+		}
+
+		result, err := h.characterService.RefreshAllCharacters(r.Context(), req.Concurrency, progress)
+		if err != nil {
+			respondServiceError(w, err)
+			return
+		}
+
+		InvalidateCache(h.cache, "accounts:")
+
+		respondJSON(w, result)
+	}
+}
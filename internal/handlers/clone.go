@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/guarzo/canifly/internal/services/interfaces"
+)
+
+type CloneHandler struct {
+	logger       interfaces.Logger
+	cloneService interfaces.CloneService
+}
+
+func NewCloneHandler(l interfaces.Logger, c interfaces.CloneService) *CloneHandler {
+	return &CloneHandler{
+		logger:       l,
+		cloneService: c,
+	}
+}
+
+// GetCloneInventory handles GET /api/clones
+func (h *CloneHandler) GetCloneInventory() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		inventory, err := h.cloneService.GetCloneInventory()
+		if err != nil {
+			h.logger.Errorf("Failed to build clone inventory: %v", err)
+			respondError(w, "Failed to fetch accounts", http.StatusInternalServerError)
+			return
+		}
+		respondJSON(w, inventory)
+	}
+}
@@ -53,6 +53,7 @@ func (h *ConfigHandler) GetConfig() http.HandlerFunc {
 				"roles":          config.Roles,
 				"userSelections": config.DropDownSelections,
 				"lastBackupDir":  config.LastBackupDir,
+				"injectorPrices": config.InjectorPrices,
 			}
 
 			// Return in the format the frontend expects
@@ -70,12 +71,17 @@ func (h *ConfigHandler) UpdateConfig() http.HandlerFunc {
 			SettingsDir    *string                   `json:"settingsDir,omitempty"`
 			UserSelections *model.DropDownSelections `json:"userSelections,omitempty"`
 			Roles          *[]string                 `json:"roles,omitempty"`
+			InjectorPrices *model.InjectorPrices     `json:"injectorPrices,omitempty"`
 		}
 
 		if err := decodeJSONBody(r, &request); err != nil {
 			respondError(w, "Invalid request body", http.StatusBadRequest)
 			return
 		}
+		if request.InjectorPrices != nil && (request.InjectorPrices.Large < 0 || request.InjectorPrices.Small < 0) {
+			respondError(w, "Injector prices cannot be negative", http.StatusBadRequest)
+			return
+		}
 
 		// Update settings directory if provided
 		if request.SettingsDir != nil {
@@ -101,6 +107,14 @@ func (h *ConfigHandler) UpdateConfig() http.HandlerFunc {
 			}
 		}
 
+		// Update injector prices if provided
+		if request.InjectorPrices != nil {
+			if err := h.configService.SaveInjectorPrices(*request.InjectorPrices); err != nil {
+				respondError(w, fmt.Sprintf("Failed to save injector prices: %v", err), http.StatusInternalServerError)
+				return
+			}
+		}
+
 		// Invalidate config cache after successful update
 		InvalidateCache(h.cache, "config:")
 
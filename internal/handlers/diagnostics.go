@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/guarzo/canifly/internal/services/interfaces"
+)
+
+type DiagnosticsHandler struct {
+	logger   interfaces.Logger
+	governor interfaces.ESIGovernor
+}
+
+func NewDiagnosticsHandler(l interfaces.Logger, g interfaces.ESIGovernor) *DiagnosticsHandler {
+	return &DiagnosticsHandler{
+		logger:   l,
+		governor: g,
+	}
+}
+
+// GetESIStatus handles GET /api/diagnostics/esi
+func (h *DiagnosticsHandler) GetESIStatus() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		respondJSON(w, h.governor.State())
+	}
+}
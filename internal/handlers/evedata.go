@@ -154,7 +154,7 @@ func (h *EveDataHandler) GetEveProfiles(w http.ResponseWriter, r *http.Request)
 			h.logger.Debug("Getting EVE profiles")
 
 			// Load character settings (EVE profiles)
-			eveProfiles, err := h.profileService.LoadCharacterSettings()
+			eveProfiles, err := h.profileService.LoadCharacterSettings(r.Context())
 			if err != nil {
 				h.logger.Errorf("Failed to load character settings: %v", err)
 				return nil, err // Return nil and error so WithCache can handle it properly
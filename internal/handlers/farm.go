@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/guarzo/canifly/internal/services/interfaces"
+)
+
+type FarmHandler struct {
+	logger      interfaces.Logger
+	farmService interfaces.FarmService
+}
+
+func NewFarmHandler(l interfaces.Logger, f interfaces.FarmService) *FarmHandler {
+	return &FarmHandler{
+		logger:      l,
+		farmService: f,
+	}
+}
+
+// GetFarmSummary handles GET /api/farms
+func (h *FarmHandler) GetFarmSummary() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		summary, err := h.farmService.GetFarmSummary()
+		if err != nil {
+			h.logger.Errorf("Failed to build farm summary: %v", err)
+			respondError(w, "Failed to fetch accounts", http.StatusInternalServerError)
+			return
+		}
+		respondJSON(w, summary)
+	}
+}
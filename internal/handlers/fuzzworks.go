@@ -79,8 +79,9 @@ func (h *FuzzworksHandler) GetStatus() http.HandlerFunc {
 
 		// Try to read the metadata
 		var metadata struct {
-			InvTypes     *fuzzworks.FileMetadata `json:"inv_types"`
-			SolarSystems *fuzzworks.FileMetadata `json:"solar_systems"`
+			InvTypes       *fuzzworks.FileMetadata `json:"inv_types"`
+			SolarSystems   *fuzzworks.FileMetadata `json:"solar_systems"`
+			TypeAttributes *fuzzworks.FileMetadata `json:"type_attributes"`
 		}
 
 		// Read metadata file if it exists
@@ -91,7 +92,7 @@ func (h *FuzzworksHandler) GetStatus() http.HandlerFunc {
 		}
 
 		status := map[string]interface{}{
-			"hasData": metadata.InvTypes != nil || metadata.SolarSystems != nil,
+			"hasData": metadata.InvTypes != nil || metadata.SolarSystems != nil || metadata.TypeAttributes != nil,
 		}
 
 		if metadata.InvTypes != nil {
@@ -110,6 +111,14 @@ func (h *FuzzworksHandler) GetStatus() http.HandlerFunc {
 			}
 		}
 
+		if metadata.TypeAttributes != nil {
+			status["typeAttributes"] = map[string]interface{}{
+				"lastUpdated": metadata.TypeAttributes.DownloadTime,
+				"fileSize":    metadata.TypeAttributes.FileSize,
+				"etag":        metadata.TypeAttributes.ETag,
+			}
+		}
+
 		respondJSON(w, status)
 	}
 }
@@ -0,0 +1,67 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+
+	"github.com/guarzo/canifly/internal/services/interfaces"
+)
+
+type InjectorHandler struct {
+	logger          interfaces.Logger
+	injectorService interfaces.InjectorService
+	accountService  interfaces.AccountManagementService
+}
+
+func NewInjectorHandler(l interfaces.Logger, i interfaces.InjectorService, a interfaces.AccountManagementService) *InjectorHandler {
+	return &InjectorHandler{
+		logger:          l,
+		injectorService: i,
+		accountService:  a,
+	}
+}
+
+// EstimateInjectors handles GET /api/characters/{id}/plans/{name}/injectors
+func (h *InjectorHandler) EstimateInjectors() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		characterID, err := strconv.ParseInt(vars["id"], 10, 64)
+		if err != nil {
+			respondError(w, "Invalid character ID", http.StatusBadRequest)
+			return
+		}
+
+		accounts, err := h.accountService.FetchAccounts()
+		if err != nil {
+			respondError(w, "Failed to fetch accounts", http.StatusInternalServerError)
+			return
+		}
+
+		estimate, err := h.injectorService.EstimateInjectors(accounts, characterID, vars["name"])
+		if err != nil {
+			respondServiceError(w, err)
+			return
+		}
+		respondJSON(w, estimate)
+	}
+}
+
+// RankInjectionCandidates handles GET /api/skill-plans/{name}/injection-candidates
+func (h *InjectorHandler) RankInjectionCandidates() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		accounts, err := h.accountService.FetchAccounts()
+		if err != nil {
+			respondError(w, "Failed to fetch accounts", http.StatusInternalServerError)
+			return
+		}
+
+		estimates, err := h.injectorService.RankInjectionCandidates(accounts, mux.Vars(r)["name"])
+		if err != nil {
+			respondServiceError(w, err)
+			return
+		}
+		respondJSON(w, estimates)
+	}
+}
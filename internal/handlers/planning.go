@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/services/interfaces"
+)
+
+type PlanningHandler struct {
+	logger          interfaces.Logger
+	planningService interfaces.PlanningService
+	accountService  interfaces.AccountManagementService
+}
+
+func NewPlanningHandler(l interfaces.Logger, p interfaces.PlanningService, a interfaces.AccountManagementService) *PlanningHandler {
+	return &PlanningHandler{
+		logger:          l,
+		planningService: p,
+		accountService:  a,
+	}
+}
+
+// AssignTraining handles POST /api/planning/assign
+func (h *PlanningHandler) AssignTraining() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request model.PlanningRequest
+		if err := decodeJSONBody(r, &request); err != nil {
+			respondError(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		accounts, err := h.accountService.FetchAccounts()
+		if err != nil {
+			respondError(w, "Failed to fetch accounts", http.StatusInternalServerError)
+			return
+		}
+
+		result, err := h.planningService.AssignTraining(accounts, request)
+		if err != nil {
+			respondServiceError(w, err)
+			return
+		}
+		respondJSON(w, result)
+	}
+}
@@ -2,12 +2,17 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/guarzo/canifly/internal/model"
 	"github.com/guarzo/canifly/internal/services/interfaces"
 )
 
@@ -36,9 +41,13 @@ func (h *SkillPlanHandler) ListSkillPlans() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Parse pagination parameters
 		paginationParams := ParsePaginationParams(r)
+		tag := r.URL.Query().Get("tag")
+		doctrine := r.URL.Query().Get("doctrine")
+		sortByProgress := r.URL.Query().Get("sort") == "progress"
 
-		// Check cache first (cache key includes pagination params)
-		cacheKey := fmt.Sprintf("skillplans:list:page:%d:limit:%d", paginationParams.Page, paginationParams.Limit)
+		// Check cache first (cache key includes pagination, filter and sort params)
+		cacheKey := fmt.Sprintf("skillplans:list:page:%d:limit:%d:tag:%s:doctrine:%s:progress:%t",
+			paginationParams.Page, paginationParams.Limit, tag, doctrine, sortByProgress)
 
 		cacheHandler := WithCache(
 			h.cache,
@@ -59,8 +68,9 @@ func (h *SkillPlanHandler) ListSkillPlans() http.HandlerFunc {
 					h.skillPlanService.GetSkillTypes(),
 				)
 
-				// Apply pagination to skill plans
-				paginatedResponse := PaginateSkillPlans(skillPlans, paginationParams)
+				// Apply filters, then pagination to skill plans
+				skillPlans = FilterSkillPlans(skillPlans, tag, doctrine)
+				paginatedResponse := PaginateSkillPlans(skillPlans, paginationParams, sortByProgress)
 
 				return paginatedResponse, nil
 			},
@@ -91,13 +101,214 @@ func (h *SkillPlanHandler) GetSkillPlan() http.HandlerFunc {
 			return
 		}
 
+		plan := h.skillPlanService.GetSkillPlans()[planName]
 		respondJSON(w, map[string]interface{}{
-			"name":    planName,
-			"content": content,
+			"name":     planName,
+			"content":  content,
+			"icon":     plan.Icon,
+			"steps":    plan.Steps,
+			"metadata": plan.Metadata,
 		})
 	}
 }
 
+// GetExpandedSkillPlan handles GET /api/skill-plans/{name}/expanded
+func (h *SkillPlanHandler) GetExpandedSkillPlan() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		planName := vars["name"]
+
+		plan, found := h.skillPlanService.GetExpandedSkillPlan(planName)
+		if !found {
+			respondError(w, fmt.Sprintf("Skill plan %s not found", planName), http.StatusNotFound)
+			return
+		}
+
+		respondJSON(w, map[string]interface{}{
+			"name":   plan.Name,
+			"icon":   plan.Icon,
+			"skills": plan.Skills,
+		})
+	}
+}
+
+// RemapAdvice handles POST /api/characters/{id}/remap-advice
+func (h *SkillPlanHandler) RemapAdvice() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		characterID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			respondError(w, "Invalid character ID", http.StatusBadRequest)
+			return
+		}
+
+		var request struct {
+			Plan string `json:"plan"`
+		}
+		if err := decodeJSONBody(r, &request); err != nil || request.Plan == "" {
+			respondError(w, "Missing plan name", http.StatusBadRequest)
+			return
+		}
+
+		character, status, ok := h.findCharacter(w, characterID)
+		if !ok {
+			return
+		}
+
+		advice, err := h.skillPlanService.GetRemapAdvice(*character, status, request.Plan)
+		if err != nil {
+			respondServiceError(w, err)
+			return
+		}
+
+		respondJSON(w, advice)
+	}
+}
+
+// TrainingScenario handles POST /api/characters/{id}/training-scenario
+func (h *SkillPlanHandler) TrainingScenario() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		characterID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			respondError(w, "Invalid character ID", http.StatusBadRequest)
+			return
+		}
+
+		var request struct {
+			Plan string `json:"plan"`
+			model.TrainingScenario
+		}
+		if err := decodeJSONBody(r, &request); err != nil || request.Plan == "" {
+			respondError(w, "Missing plan name", http.StatusBadRequest)
+			return
+		}
+		if request.ImplantBonus < 0 || request.AcceleratorBonus < 0 {
+			respondError(w, "Bonuses must not be negative", http.StatusBadRequest)
+			return
+		}
+
+		character, status, ok := h.findCharacter(w, characterID)
+		if !ok {
+			return
+		}
+
+		comparison, err := h.skillPlanService.CompareTrainingScenario(*character, status, request.Plan, request.TrainingScenario)
+		if err != nil {
+			respondServiceError(w, err)
+			return
+		}
+
+		respondJSON(w, comparison)
+	}
+}
+
+// NextSkills handles POST /api/characters/{id}/next-skills
+func (h *SkillPlanHandler) NextSkills() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		characterID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+		if err != nil {
+			respondError(w, "Invalid character ID", http.StatusBadRequest)
+			return
+		}
+
+		// The body is optional; without one every plan counts equally.
+		var request model.SkillRecommendationRequest
+		if r.ContentLength != 0 {
+			if err := decodeJSONBody(r, &request); err != nil {
+				respondError(w, "Invalid request body", http.StatusBadRequest)
+				return
+			}
+		}
+		if request.Limit < 0 {
+			respondError(w, "Limit must not be negative", http.StatusBadRequest)
+			return
+		}
+
+		character, status, ok := h.findCharacter(w, characterID)
+		if !ok {
+			return
+		}
+
+		respondJSON(w, h.skillPlanService.RecommendNextSkills(*character, status, request))
+	}
+}
+
+// ExportSkillQueue handles GET /api/characters/{id}/plans/{name}/queue-export
+func (h *SkillPlanHandler) ExportSkillQueue() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		characterID, err := strconv.ParseInt(vars["id"], 10, 64)
+		if err != nil {
+			respondError(w, "Invalid character ID", http.StatusBadRequest)
+			return
+		}
+
+		character, status, ok := h.findCharacter(w, characterID)
+		if !ok {
+			return
+		}
+
+		queue, err := h.skillPlanService.ExportSkillQueue(*character, status, vars["name"])
+		if err != nil {
+			respondServiceError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(queue))
+	}
+}
+
+// findCharacter looks a character up across all accounts, returning it with
+// its account's Alpha/Omega status, and responds with an error when it cannot
+// be found.
+func (h *SkillPlanHandler) findCharacter(w http.ResponseWriter, characterID int64) (*model.Character, model.AccountStatus, bool) {
+	accounts, err := h.accountService.FetchAccounts()
+	if err != nil {
+		respondError(w, "Failed to fetch accounts", http.StatusInternalServerError)
+		return nil, "", false
+	}
+
+	for _, account := range accounts {
+		for i := range account.Characters {
+			if account.Characters[i].Character.CharacterID == characterID {
+				return &account.Characters[i].Character, account.Status, true
+			}
+		}
+	}
+	respondError(w, "Character not found", http.StatusNotFound)
+	return nil, "", false
+}
+
+// rejectInvalidPlan validates plan content and, unless the request has
+// ?force=true, responds with the validation report when it has problems.
+// It returns true when the request has been answered.
+func (h *SkillPlanHandler) rejectInvalidPlan(w http.ResponseWriter, r *http.Request, name, content string) bool {
+	if r.URL.Query().Get("force") == "true" {
+		return false
+	}
+	validation := h.skillPlanService.ValidateSkillPlan(name, content)
+	if validation.Valid {
+		return false
+	}
+
+	h.logger.Warnf("Rejected skill plan %s with %d problem(s)", name, len(validation.Diagnostics))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":      fmt.Sprintf("Skill plan %s has problems; resubmit with ?force=true to save anyway", name),
+		"validation": validation,
+	})
+	return true
+}
+
+// ValidateSkillPlans handles GET /api/skill-plans/validate
+func (h *SkillPlanHandler) ValidateSkillPlans() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		respondJSON(w, h.skillPlanService.AuditSkillPlans())
+	}
+}
+
 // CreateSkillPlan handles POST /api/skill-plans
 func (h *SkillPlanHandler) CreateSkillPlan() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -121,8 +332,16 @@ func (h *SkillPlanHandler) CreateSkillPlan() http.HandlerFunc {
 			return
 		}
 
-		if err := h.skillPlanService.ParseAndSaveSkillPlan(request.Content, request.Name); err != nil {
+		if h.rejectInvalidPlan(w, r, request.Name, request.Content) {
+			return
+		}
+
+		if err := h.skillPlanService.ParseAndSaveSkillPlan(request.Content, request.Name, model.PlanSourceUser); err != nil {
 			h.logger.Errorf("Failed to create skill plan: %v", err)
+			if isClientError(err) {
+				respondServiceError(w, err)
+				return
+			}
 			respondError(w, "Failed to create skill plan", http.StatusInternalServerError)
 			return
 		}
@@ -186,37 +405,22 @@ func (h *SkillPlanHandler) UpdateSkillPlan() http.HandlerFunc {
 			return
 		}
 
-		// Safe update: save to temporary name first
-		tempName := fmt.Sprintf("_temp_%s_%d", planName, time.Now().UnixNano())
-
-		// Save the new content with a temporary name
-		if err := h.skillPlanService.ParseAndSaveSkillPlan(request.Content, tempName); err != nil {
-			h.logger.Errorf("Failed to save updated skill plan: %v", err)
-			respondError(w, "Failed to update skill plan", http.StatusInternalServerError)
-			return
-		}
-
-		// Delete the old plan only after successful save
-		if err := h.skillPlanService.DeleteSkillPlan(planName); err != nil {
-			h.logger.Errorf("Failed to delete old skill plan: %v", err)
-			// Try to clean up the temp file
-			_ = h.skillPlanService.DeleteSkillPlan(tempName)
-			respondError(w, "Failed to update skill plan", http.StatusInternalServerError)
+		if h.rejectInvalidPlan(w, r, planName, request.Content) {
 			return
 		}
 
-		// Rename temp to original name
-		if err := h.skillPlanService.ParseAndSaveSkillPlan(request.Content, planName); err != nil {
-			h.logger.Errorf("Failed to save skill plan with original name: %v", err)
-			// Try to clean up the temp file
-			_ = h.skillPlanService.DeleteSkillPlan(tempName)
+		// The plan file is replaced atomically, and the new content is validated
+		// under the plan's own name so self-includes are caught before writing.
+		if err := h.skillPlanService.ParseAndSaveSkillPlan(request.Content, planName, model.PlanSourceUser); err != nil {
+			h.logger.Errorf("Failed to save updated skill plan: %v", err)
+			if isClientError(err) {
+				respondServiceError(w, err)
+				return
+			}
 			respondError(w, "Failed to update skill plan", http.StatusInternalServerError)
 			return
 		}
 
-		// Clean up temp file
-		_ = h.skillPlanService.DeleteSkillPlan(tempName)
-
 		// Invalidate cache after successful update
 		InvalidateCache(h.cache, "skillplans:")
 		InvalidateCache(h.cache, "eve:skillplans")
@@ -271,6 +475,248 @@ func (h *SkillPlanHandler) DeleteSkillPlanRESTful() http.HandlerFunc {
 	}
 }
 
+// maxPlanImportSize caps the size of an uploaded plan file.
+const maxPlanImportSize = 10 << 20
+
+// ImportSkillPlan handles POST /api/skill-plans/import
+// The EVEMon plan (.xml or .emp) is sent either as the raw request body or as
+// the "file" field of a multipart form. The optional "name" query parameter or
+// form field overrides the name stored in the file.
+func (h *SkillPlanHandler) ImportSkillPlan() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, maxPlanImportSize)
+
+		name := r.URL.Query().Get("name")
+		var data []byte
+		var err error
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+			file, _, formErr := r.FormFile("file")
+			if formErr != nil {
+				respondError(w, "Missing plan file", http.StatusBadRequest)
+				return
+			}
+			defer file.Close()
+			if formName := r.FormValue("name"); formName != "" {
+				name = formName
+			}
+			data, err = io.ReadAll(file)
+		} else {
+			data, err = io.ReadAll(r.Body)
+		}
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respondError(w, "Plan file is too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		if err != nil || len(data) == 0 {
+			respondError(w, "Invalid plan file", http.StatusBadRequest)
+			return
+		}
+
+		planName, err := h.skillPlanService.ImportEVEMonPlan(data, name)
+		if err != nil {
+			h.logger.Errorf("Failed to import skill plan: %v", err)
+			respondServiceError(w, err)
+			return
+		}
+
+		InvalidateCache(h.cache, "skillplans:")
+		InvalidateCache(h.cache, "eve:skillplans")
+
+		if h.wsHub != nil {
+			h.wsHub.BroadcastUpdate("skillplan:created", map[string]interface{}{
+				"name": planName,
+			})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"name": planName,
+		})
+	}
+}
+
+// ExportSkillPlan handles GET /api/skill-plans/{name}/export?format=evemon|text
+func (h *SkillPlanHandler) ExportSkillPlan() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		planName := mux.Vars(r)["name"]
+
+		var (
+			data        []byte
+			err         error
+			contentType string
+			extension   string
+		)
+		switch format := r.URL.Query().Get("format"); format {
+		case "evemon":
+			data, err = h.skillPlanService.ExportEVEMonPlan(planName)
+			contentType, extension = "application/xml", "xml"
+		case "", "text":
+			data, err = h.skillPlanService.ExportTextPlan(planName)
+			contentType, extension = "text/plain; charset=utf-8", "txt"
+		default:
+			respondError(w, fmt.Sprintf("Unsupported export format %s", format), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			respondServiceError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", planName+"."+extension))
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write(data)
+	}
+}
+
+// SkillPlanHistory handles GET /api/skill-plans/{name}/history
+func (h *SkillPlanHandler) SkillPlanHistory() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		versions, err := h.skillPlanService.GetSkillPlanHistory(mux.Vars(r)["name"])
+		if err != nil {
+			respondServiceError(w, err)
+			return
+		}
+		respondJSON(w, versions)
+	}
+}
+
+// DiffSkillPlan handles GET /api/skill-plans/{name}/diff?from=&to=
+func (h *SkillPlanHandler) DiffSkillPlan() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		diff, err := h.skillPlanService.DiffSkillPlan(mux.Vars(r)["name"], query.Get("from"), query.Get("to"))
+		if err != nil {
+			respondServiceError(w, err)
+			return
+		}
+		respondJSON(w, diff)
+	}
+}
+
+// RestoreSkillPlan handles POST /api/skill-plans/{name}/restore
+func (h *SkillPlanHandler) RestoreSkillPlan() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		planName := mux.Vars(r)["name"]
+
+		var request struct {
+			Version string `json:"version"`
+		}
+		if err := decodeJSONBody(r, &request); err != nil || request.Version == "" {
+			respondError(w, "Version is required", http.StatusBadRequest)
+			return
+		}
+
+		if err := h.skillPlanService.RestoreSkillPlan(planName, request.Version); err != nil {
+			h.logger.Errorf("Failed to restore skill plan %s to version %s: %v", planName, request.Version, err)
+			respondServiceError(w, err)
+			return
+		}
+
+		InvalidateCache(h.cache, "skillplans:")
+		InvalidateCache(h.cache, "eve:skillplans")
+
+		if h.wsHub != nil {
+			h.wsHub.BroadcastUpdate("skillplan:updated", map[string]interface{}{
+				"name": planName,
+			})
+		}
+
+		respondJSON(w, map[string]interface{}{
+			"name":    planName,
+			"version": request.Version,
+		})
+	}
+}
+
+// CreateFittingPlan handles POST /api/fittings/plan
+func (h *SkillPlanHandler) CreateFittingPlan() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Fitting string `json:"fitting"`
+			Name    string `json:"name"`
+		}
+		if err := decodeJSONBody(r, &request); err != nil || request.Fitting == "" {
+			respondError(w, "Fitting is required", http.StatusBadRequest)
+			return
+		}
+
+		plan, unknownItems, err := h.skillPlanService.SaveFittingPlan(request.Fitting, request.Name)
+		if err != nil {
+			h.logger.Errorf("Failed to create skill plan from fitting: %v", err)
+			respondServiceError(w, err)
+			return
+		}
+
+		InvalidateCache(h.cache, "skillplans:")
+		InvalidateCache(h.cache, "eve:skillplans")
+
+		if h.wsHub != nil {
+			h.wsHub.BroadcastUpdate("skillplan:created", map[string]interface{}{
+				"name": plan.Name,
+			})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"name":         plan.Name,
+			"steps":        plan.Steps,
+			"unknownItems": unknownItems,
+		})
+	}
+}
+
+// EvaluateFitting handles POST /api/fittings/evaluate
+func (h *SkillPlanHandler) EvaluateFitting() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Fitting string `json:"fitting"`
+		}
+		if err := decodeJSONBody(r, &request); err != nil || request.Fitting == "" {
+			respondError(w, "Fitting is required", http.StatusBadRequest)
+			return
+		}
+
+		accounts, err := h.accountService.FetchAccounts()
+		if err != nil {
+			respondError(w, "Failed to fetch accounts", http.StatusInternalServerError)
+			return
+		}
+
+		evaluation, err := h.skillPlanService.EvaluateFitting(accounts, request.Fitting)
+		if err != nil {
+			respondServiceError(w, err)
+			return
+		}
+
+		respondJSON(w, evaluation)
+	}
+}
+
+// ShipStatus handles GET /api/ships/{typeName}/status
+func (h *SkillPlanHandler) ShipStatus() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		typeName := mux.Vars(r)["typeName"]
+
+		accounts, err := h.accountService.FetchAccounts()
+		if err != nil {
+			respondError(w, "Failed to fetch accounts", http.StatusInternalServerError)
+			return
+		}
+
+		status, err := h.skillPlanService.GetShipStatus(accounts, typeName)
+		if err != nil {
+			respondServiceError(w, err)
+			return
+		}
+
+		respondJSON(w, status)
+	}
+}
+
 // CopySkillPlan handles POST /api/skill-plans/{name}/copy
 func (h *SkillPlanHandler) CopySkillPlan() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -310,8 +756,12 @@ func (h *SkillPlanHandler) CopySkillPlan() http.HandlerFunc {
 		}
 
 		// Create copy
-		if err := h.skillPlanService.ParseAndSaveSkillPlan(string(content), request.NewName); err != nil {
+		if err := h.skillPlanService.ParseAndSaveSkillPlan(string(content), request.NewName, model.PlanSourceCopy); err != nil {
 			h.logger.Errorf("Failed to create skill plan copy: %v", err)
+			if isClientError(err) {
+				respondServiceError(w, err)
+				return
+			}
 			respondError(w, "Failed to copy skill plan", http.StatusInternalServerError)
 			return
 		}
@@ -1,6 +1,9 @@
 package handlers
 
 import (
+	"sort"
+	"strings"
+
 	"github.com/guarzo/canifly/internal/model"
 )
 
@@ -10,11 +13,53 @@ type SkillPlanEntry struct {
 	Status model.SkillPlanWithStatus `json:"status"`
 }
 
-// PaginateSkillPlans applies pagination to skill plan map
-func PaginateSkillPlans(skillPlans map[string]model.SkillPlanWithStatus, params PaginationParams) PaginatedResponse {
+// FilterSkillPlans keeps the plans tagged with tag and belonging to doctrine,
+// ignoring case. An empty tag or doctrine does not filter.
+func FilterSkillPlans(skillPlans map[string]model.SkillPlanWithStatus, tag, doctrine string) map[string]model.SkillPlanWithStatus {
+	if tag == "" && doctrine == "" {
+		return skillPlans
+	}
+
+	filtered := make(map[string]model.SkillPlanWithStatus)
+	for name, plan := range skillPlans {
+		if tag != "" && !plan.Metadata.HasTag(tag) {
+			continue
+		}
+		if doctrine != "" && !strings.EqualFold(plan.Metadata.Doctrine, doctrine) {
+			continue
+		}
+		filtered[name] = plan
+	}
+	return filtered
+}
+
+// sortCharactersByProgress orders a plan's characters closest to qualifying
+// first: highest progress, then least SP remaining, then by name.
+func sortCharactersByProgress(characters []model.CharacterSkillPlanStatus) []model.CharacterSkillPlanStatus {
+	sorted := make([]model.CharacterSkillPlanStatus, len(characters))
+	copy(sorted, characters)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if a.Progress != b.Progress {
+			return a.Progress > b.Progress
+		}
+		if remainingA, remainingB := a.RequiredSP-a.TrainedSP, b.RequiredSP-b.TrainedSP; remainingA != remainingB {
+			return remainingA < remainingB
+		}
+		return a.CharacterName < b.CharacterName
+	})
+	return sorted
+}
+
+// PaginateSkillPlans applies pagination to skill plan map. With sortByProgress,
+// each plan's characters are ordered closest to qualifying first.
+func PaginateSkillPlans(skillPlans map[string]model.SkillPlanWithStatus, params PaginationParams, sortByProgress bool) PaginatedResponse {
 	// Convert map to slice for pagination
 	entries := make([]SkillPlanEntry, 0, len(skillPlans))
 	for name, status := range skillPlans {
+		if sortByProgress {
+			status.Characters = sortCharactersByProgress(status.Characters)
+		}
 		entries = append(entries, SkillPlanEntry{
 			Name:   name,
 			Status: status,
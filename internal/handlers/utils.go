@@ -2,7 +2,9 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	flyErrors "github.com/guarzo/canifly/internal/errors"
 	"github.com/guarzo/canifly/internal/services/interfaces"
 	"io"
 	"net/http"
@@ -51,6 +53,23 @@ func respondError(w http.ResponseWriter, message string, statusCode int) {
 	json.NewEncoder(w).Encode(map[string]string{"error": message})
 }
 
+// respondServiceError sends a JSON error response, using the status code of a
+// CustomError and 500 for anything else
+func respondServiceError(w http.ResponseWriter, err error) {
+	var customErr *flyErrors.CustomError
+	if errors.As(err, &customErr) {
+		respondError(w, customErr.Message, customErr.StatusCode)
+		return
+	}
+	respondError(w, err.Error(), http.StatusInternalServerError)
+}
+
+// isClientError reports whether err is a CustomError with a 4xx status code
+func isClientError(err error) bool {
+	var customErr *flyErrors.CustomError
+	return errors.As(err, &customErr) && customErr.StatusCode >= 400 && customErr.StatusCode < 500
+}
+
 // respondJSON sends a JSON success response
 func respondJSON(w http.ResponseWriter, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
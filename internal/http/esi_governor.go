@@ -0,0 +1,159 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/services/interfaces"
+)
+
+var _ interfaces.ESIGovernor = (*ESIGovernor)(nil)
+
+const (
+	// errorLimitReserve is how many errors of ESI's budget are kept unused:
+	// once only this many remain, calls wait for the window to reset.
+	errorLimitReserve = 10
+	// defaultRetryAfter is the wait after a 420/429 without Retry-After.
+	defaultRetryAfter = 60 * time.Second
+	// statusErrorLimited is ESI's 420 Error Limited response.
+	statusErrorLimited = 420
+)
+
+// ESIGovernor tracks ESI's error budget and rate limits across every request
+// and holds calls back before they could get the IP banned.
+type ESIGovernor struct {
+	mu             sync.Mutex
+	errorsRemain   int // -1 until ESI reports it
+	errorsReset    time.Time
+	retryAfter     time.Time
+	throttledCalls int64
+	rateLimited    int64
+
+	now   func() time.Time
+	sleep func(context.Context, time.Duration) error
+}
+
+var sharedGovernor = NewESIGovernor()
+
+// SharedESIGovernor is the governor used by every EsiHttpClient.
+func SharedESIGovernor() *ESIGovernor {
+	return sharedGovernor
+}
+
+// NewESIGovernor returns a governor that hasn't seen any ESI response yet.
+func NewESIGovernor() *ESIGovernor {
+	return &ESIGovernor{errorsRemain: -1, now: time.Now, sleep: sleepContext}
+}
+
+// Wait blocks until an ESI call may be made, or until ctx is done, in which
+// case it returns ctx's error.
+func (g *ESIGovernor) Wait(ctx context.Context) error {
+	counted := false
+	for {
+		g.mu.Lock()
+		until, _ := g.pausedUntil(g.now())
+		if until.IsZero() {
+			g.mu.Unlock()
+			return nil
+		}
+		if !counted {
+			g.throttledCalls++
+			counted = true
+		}
+		wait := until.Sub(g.now())
+		g.mu.Unlock()
+		if err := g.sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// sleepContext sleeps for d, returning early with ctx's error if ctx is done.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// Observe records the error budget and any rate limiting from a response.
+func (g *ESIGovernor) Observe(resp *http.Response) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	now := g.now()
+	if remain, err := strconv.Atoi(resp.Header.Get("X-ESI-Error-Limit-Remain")); err == nil {
+		g.errorsRemain = remain
+		if reset, err := strconv.Atoi(resp.Header.Get("X-ESI-Error-Limit-Reset")); err == nil {
+			g.errorsReset = now.Add(time.Duration(reset) * time.Second)
+		}
+	}
+
+	if resp.StatusCode == statusErrorLimited || resp.StatusCode == http.StatusTooManyRequests {
+		g.rateLimited++
+		until := now.Add(retryAfter(resp.Header, now))
+		if until.After(g.retryAfter) {
+			g.retryAfter = until
+		}
+	}
+}
+
+// State reports what the governor has seen of ESI's limits.
+func (g *ESIGovernor) State() model.ESIGovernorState {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	state := model.ESIGovernorState{
+		ThrottledCalls: g.throttledCalls,
+		RateLimited:    g.rateLimited,
+	}
+	if g.errorsRemain >= 0 {
+		remain, reset := g.errorsRemain, g.errorsReset
+		state.ErrorLimitRemain = &remain
+		state.ErrorLimitReset = &reset
+	}
+	if !g.retryAfter.IsZero() {
+		retryAfter := g.retryAfter
+		state.RetryAfter = &retryAfter
+	}
+	if until, reason := g.pausedUntil(g.now()); !until.IsZero() {
+		state.Paused = true
+		state.PausedUntil = &until
+		state.Reason = reason
+	}
+	return state
+}
+
+// pausedUntil is when calls may resume, or zero if they may be made now.
+// Callers hold g.mu.
+func (g *ESIGovernor) pausedUntil(now time.Time) (time.Time, string) {
+	var until time.Time
+	var reason string
+	if now.Before(g.retryAfter) {
+		until, reason = g.retryAfter, "rate limited by ESI"
+	}
+	if g.errorsRemain >= 0 && g.errorsRemain <= errorLimitReserve && now.Before(g.errorsReset) && g.errorsReset.After(until) {
+		until, reason = g.errorsReset, "ESI error limit nearly exhausted"
+	}
+	return until, reason
+}
+
+// retryAfter reads a Retry-After header given in seconds or as a date.
+func retryAfter(header http.Header, now time.Time) time.Duration {
+	value := header.Get("Retry-After")
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return defaultRetryAfter
+}
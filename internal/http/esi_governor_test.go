@@ -0,0 +1,98 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeClockGovernor returns a governor whose sleeps advance its clock and
+// are recorded.
+func fakeClockGovernor() (*ESIGovernor, *[]time.Duration) {
+	g := NewESIGovernor()
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	var slept []time.Duration
+	g.now = func() time.Time { return now }
+	g.sleep = func(_ context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		now = now.Add(d)
+		return nil
+	}
+	return g, &slept
+}
+
+func response(status int, headers map[string]string) *http.Response {
+	resp := &http.Response{StatusCode: status, Header: http.Header{}}
+	for name, value := range headers {
+		resp.Header.Set(name, value)
+	}
+	return resp
+}
+
+func TestESIGovernor_HealthyBudget(t *testing.T) {
+	g, slept := fakeClockGovernor()
+	g.Observe(response(http.StatusOK, map[string]string{
+		"X-ESI-Error-Limit-Remain": "100",
+		"X-ESI-Error-Limit-Reset":  "40",
+	}))
+
+	require.NoError(t, g.Wait(context.Background()))
+	assert.Empty(t, *slept)
+
+	state := g.State()
+	require.NotNil(t, state.ErrorLimitRemain)
+	assert.Equal(t, 100, *state.ErrorLimitRemain)
+	assert.False(t, state.Paused)
+}
+
+func TestESIGovernor_PausesOnLowBudget(t *testing.T) {
+	g, slept := fakeClockGovernor()
+	g.Observe(response(http.StatusBadRequest, map[string]string{
+		"X-ESI-Error-Limit-Remain": "8",
+		"X-ESI-Error-Limit-Reset":  "30",
+	}))
+
+	state := g.State()
+	assert.True(t, state.Paused)
+	assert.Equal(t, "ESI error limit nearly exhausted", state.Reason)
+
+	require.NoError(t, g.Wait(context.Background()))
+	assert.Equal(t, []time.Duration{30 * time.Second}, *slept)
+	assert.False(t, g.State().Paused)
+	assert.Equal(t, int64(1), g.State().ThrottledCalls)
+}
+
+func TestESIGovernor_RespectsRetryAfter(t *testing.T) {
+	g, slept := fakeClockGovernor()
+	g.Observe(response(http.StatusTooManyRequests, map[string]string{"Retry-After": "12"}))
+	g.Observe(response(statusErrorLimited, nil))
+
+	state := g.State()
+	assert.Equal(t, int64(2), state.RateLimited)
+	assert.Equal(t, "rate limited by ESI", state.Reason)
+
+	// The 420 without Retry-After waits the default minute, the longer of the two.
+	require.NoError(t, g.Wait(context.Background()))
+	assert.Equal(t, []time.Duration{defaultRetryAfter}, *slept)
+}
+
+func TestESIGovernor_WaitIsCancelled(t *testing.T) {
+	g := NewESIGovernor()
+	g.Observe(response(http.StatusTooManyRequests, map[string]string{"Retry-After": "3600"}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	waited := make(chan error, 1)
+	go func() { waited <- g.Wait(ctx) }()
+	cancel()
+
+	select {
+	case err := <-waited:
+		assert.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("Wait did not return when its context was cancelled")
+	}
+}
@@ -3,12 +3,17 @@ package http
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"math/rand"
 	"net/http"
+	"strings"
 	"time"
 
 	"golang.org/x/oauth2"
@@ -18,12 +23,31 @@ import (
 )
 
 const (
-	maxRetries        = 5
-	baseDelay         = 1 * time.Second
-	maxDelay          = 32 * time.Second
+	maxRetries = 5
+	baseDelay  = 1 * time.Second
+	maxDelay   = 32 * time.Second
+	// DefaultExpiration is how long a response is kept after ESI's Expires,
+	// so that it can still be revalidated with its ETag.
 	DefaultExpiration = 24 * time.Hour
 )
 
+// cachedResponse is a cached ESI response body with the validators ESI sent
+// with it. It is stored in the cache as JSON, so the metadata is saved and
+// reloaded along with the body.
+type cachedResponse struct {
+	Body         json.RawMessage `json:"esiBody"`
+	Expires      time.Time       `json:"esiExpires"`
+	ETag         string          `json:"esiETag,omitempty"`
+	LastModified string          `json:"esiLastModified,omitempty"`
+}
+
+// esiResponse is a response from ESI; a 304 has no body.
+type esiResponse struct {
+	status int
+	body   []byte
+	header http.Header
+}
+
 var _ interfaces.EsiHttpClient = (*EsiHttpClient)(nil)
 
 type EsiHttpClient struct {
@@ -32,6 +56,7 @@ type EsiHttpClient struct {
 	Logger       interfaces.Logger
 	AuthClient   interfaces.AuthClient
 	CacheService interfaces.CacheService
+	Governor     *ESIGovernor
 }
 
 // NewEsiHttpClient initializes and returns an EsiHttpClient instance
@@ -44,48 +69,151 @@ func NewEsiHttpClient(baseURL string, logger interfaces.Logger, auth interfaces.
 		Logger:       logger,
 		AuthClient:   auth,
 		CacheService: cache,
+		Governor:     SharedESIGovernor(),
 	}
 }
 
 // GetJSON retrieves JSON data from the specified endpoint. It supports optional caching and token usage.
 // If `useCache` is true, it will attempt to return cached data before making a request.
 // If a token is provided, it will include it in the request and attempt token refresh if Unauthorized.
-func (c *EsiHttpClient) GetJSON(endpoint string, token *oauth2.Token, useCache bool, target interface{}) error {
+// Waits for the governor and between retries end early once ctx is done.
+func (c *EsiHttpClient) GetJSON(ctx context.Context, endpoint string, token *oauth2.Token, useCache bool, target interface{}) error {
 	url := fmt.Sprintf("%s%s", c.BaseURL, endpoint)
-	return c.GetJSONFromURL(url, token, useCache, target)
+	return c.GetJSONFromURL(ctx, url, token, useCache, target)
 }
 
-func (c *EsiHttpClient) GetJSONFromURL(url string, token *oauth2.Token, useCache bool, target interface{}) error {
-	// Check cache first
-	if useCache && c.CacheService != nil {
-		if cachedData, found := c.CacheService.Get(url); found {
-			c.Logger.Debugf("using cached data for %s", url)
-			return json.Unmarshal(cachedData, target)
+// GetJSONFromURL is GetJSON for a full URL. Cached responses are kept per URL
+// and per token owner, and are fresh until ESI's Expires header. Once
+// expired, they are revalidated with If-None-Match / If-Modified-Since, and a
+// 304 serves the cached body.
+func (c *EsiHttpClient) GetJSONFromURL(ctx context.Context, url string, token *oauth2.Token, useCache bool, target interface{}) error {
+	useCache = useCache && c.CacheService != nil
+	key := cacheKey(url, token)
+
+	var cached *cachedResponse
+	if useCache {
+		if data, found := c.CacheService.Get(key); found {
+			cached = decodeCachedResponse(data)
+			if cached.Expires.IsZero() || time.Now().Before(cached.Expires) {
+				c.Logger.Debugf("using cached data for %s", url)
+				return json.Unmarshal(cached.Body, target)
+			}
+			c.Logger.Debugf("revalidating cached data for %s", url)
 		} else {
 			c.Logger.Debugf("no cached data found for %s", url)
 		}
 	}
 
+	header := http.Header{}
+	if cached != nil {
+		if cached.ETag != "" {
+			header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
 	// Define the operation for retry
+	var resp *esiResponse
 	operation := func() ([]byte, error) {
-		return c.doRequestWithToken("GET", url, nil, token)
+		r, err := c.doRequestWithToken(ctx, "GET", url, nil, token, header)
+		if err != nil {
+			return nil, err
+		}
+		resp = r
+		return r.body, nil
 	}
 
-	bodyBytes, err := c.retryWithExponentialBackoff(operation)
-	if err != nil {
+	if _, err := c.retryWithExponentialBackoff(ctx, operation); err != nil {
 		return err
 	}
 
+	entry := &cachedResponse{
+		Body:         resp.body,
+		Expires:      expiresAt(resp.header),
+		ETag:         resp.header.Get("ETag"),
+		LastModified: resp.header.Get("Last-Modified"),
+	}
+	if resp.status == http.StatusNotModified && cached != nil {
+		c.Logger.Debugf("cached data for %s not modified", url)
+		entry.Body = cached.Body
+		if entry.ETag == "" {
+			entry.ETag = cached.ETag
+		}
+		if entry.LastModified == "" {
+			entry.LastModified = cached.LastModified
+		}
+	}
+
 	// Cache the response if needed
-	if useCache && c.CacheService != nil {
-		c.CacheService.Set(url, bodyBytes, DefaultExpiration)
+	if useCache {
+		if data, err := json.Marshal(entry); err == nil {
+			c.CacheService.Set(key, data, time.Until(entry.Expires)+DefaultExpiration)
+		}
+	}
+
+	return json.Unmarshal(entry.Body, target)
+}
+
+// cacheKey is the URL, plus the token's owner for authenticated requests.
+func cacheKey(url string, token *oauth2.Token) string {
+	if owner := tokenOwner(token); owner != "" {
+		return url + "|" + owner
 	}
+	return url
+}
 
-	return json.Unmarshal(bodyBytes, target)
+// tokenOwner identifies whose token this is: the subject of the ESI access
+// token JWT, which stays the same when the token is refreshed, or failing
+// that a hash of the access token.
+func tokenOwner(token *oauth2.Token) string {
+	if token == nil || token.AccessToken == "" {
+		return ""
+	}
+	if parts := strings.Split(token.AccessToken, "."); len(parts) == 3 {
+		if payload, err := base64.RawURLEncoding.DecodeString(parts[1]); err == nil {
+			var claims struct {
+				Sub string `json:"sub"`
+			}
+			if json.Unmarshal(payload, &claims) == nil && claims.Sub != "" {
+				return claims.Sub
+			}
+		}
+	}
+	sum := sha256.Sum256([]byte(token.AccessToken))
+	return hex.EncodeToString(sum[:8])
+}
+
+// decodeCachedResponse reads a cache entry. Entries cached before ESI's
+// headers were kept hold the bare body and stay fresh until they drop out of
+// the cache.
+func decodeCachedResponse(data []byte) *cachedResponse {
+	var cached cachedResponse
+	if err := json.Unmarshal(data, &cached); err != nil || cached.Body == nil || cached.Expires.IsZero() {
+		return &cachedResponse{Body: data}
+	}
+	return &cached
+}
+
+// expiresAt is when a response expires according to its Expires header,
+// measured against its Date header so local clock skew doesn't matter. A
+// response without Expires is already expired.
+func expiresAt(header http.Header) time.Time {
+	now := time.Now()
+	expires, err := http.ParseTime(header.Get("Expires"))
+	if err != nil {
+		return now
+	}
+	if date, err := http.ParseTime(header.Get("Date")); err == nil {
+		return now.Add(expires.Sub(date))
+	}
+	return expires
 }
 
 // doRequestWithToken performs a request and handles token refresh if necessary.
-func (c *EsiHttpClient) doRequestWithToken(method, url string, body interface{}, token *oauth2.Token) ([]byte, error) {
+// A 304 Not Modified is returned as a response, not an error.
+func (c *EsiHttpClient) doRequestWithToken(ctx context.Context, method, url string, body interface{}, token *oauth2.Token, header http.Header) (*esiResponse, error) {
 	var reqBody io.Reader
 	if body != nil {
 		jsonData, err := json.Marshal(body)
@@ -96,23 +224,36 @@ func (c *EsiHttpClient) doRequestWithToken(method, url string, body interface{},
 		reqBody = bytes.NewReader(jsonData)
 	}
 
-	req, err := http.NewRequest(method, url, reqBody)
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
 	if err != nil {
 		c.Logger.WithError(err).Error("Failed to create request")
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
+	for name, values := range header {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
 	req.Header.Set("Content-Type", "application/json")
 	if token != nil && token.AccessToken != "" {
 		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token.AccessToken))
 	}
 
+	if c.Governor != nil {
+		if err := c.Governor.Wait(ctx); err != nil {
+			return nil, fmt.Errorf("waiting for ESI: %w", err)
+		}
+	}
 	resp, err := c.HTTPClient.Do(req)
 	if err != nil {
 		c.Logger.WithError(err).Error("Failed to execute request")
 		return nil, fmt.Errorf("failed to execute request: %w", err)
 	}
 	defer resp.Body.Close()
+	if c.Governor != nil {
+		c.Governor.Observe(resp)
+	}
 
 	if (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden) && token != nil && token.RefreshToken != "" {
 		// Attempt token refresh
@@ -122,7 +263,11 @@ func (c *EsiHttpClient) doRequestWithToken(method, url string, body interface{},
 		}
 		token.AccessToken = newToken.AccessToken
 		// Retry once with the new token
-		return c.doRequestWithToken(method, url, body, token)
+		return c.doRequestWithToken(ctx, method, url, body, token, header)
+	}
+
+	if resp.StatusCode == http.StatusNotModified {
+		return &esiResponse{status: resp.StatusCode, header: resp.Header}, nil
 	}
 
 	if resp.StatusCode < 200 || resp.StatusCode > 299 {
@@ -141,11 +286,12 @@ func (c *EsiHttpClient) doRequestWithToken(method, url string, body interface{},
 		return nil, fmt.Errorf("failed to read response body: %w", err)
 	}
 
-	return respBody, nil
+	return &esiResponse{status: resp.StatusCode, body: respBody, header: resp.Header}, nil
 }
 
 // retryWithExponentialBackoff attempts the given operation multiple times with exponential backoff on certain HTTP errors.
-func (c *EsiHttpClient) retryWithExponentialBackoff(operation func() ([]byte, error)) ([]byte, error) {
+// Rate limit responses are retried too, once the governor lets calls through again.
+func (c *EsiHttpClient) retryWithExponentialBackoff(ctx context.Context, operation func() ([]byte, error)) ([]byte, error) {
 	delay := baseDelay
 	for i := 0; i < maxRetries; i++ {
 		result, err := operation()
@@ -164,7 +310,9 @@ func (c *EsiHttpClient) retryWithExponentialBackoff(operation func() ([]byte, er
 		}
 
 		jitter := time.Duration(rand.Int63n(int64(delay)))
-		time.Sleep(delay + jitter)
+		if err := sleepContext(ctx, delay+jitter); err != nil {
+			return nil, err
+		}
 
 		delay *= 2
 		if delay > maxDelay {
@@ -179,7 +327,9 @@ func (c *EsiHttpClient) retryWithExponentialBackoff(operation func() ([]byte, er
 func shouldRetry(err error, customErr **flyErrors.CustomError) bool {
 	if errors.As(err, customErr) {
 		switch (*customErr).StatusCode {
-		case http.StatusServiceUnavailable, http.StatusGatewayTimeout, http.StatusInternalServerError:
+		case http.StatusServiceUnavailable, http.StatusGatewayTimeout, http.StatusInternalServerError,
+			statusErrorLimited, http.StatusTooManyRequests:
+			// The governor holds the retry back until ESI's Retry-After.
 			return true
 		}
 	}
@@ -1,12 +1,16 @@
 package http_test
 
 import (
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"io"
+	"maps"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -35,7 +39,7 @@ func TestAPIClient_GetJSON_SuccessNoCache(t *testing.T) {
 	client := flyHttp.NewEsiHttpClient(ts.URL, logger, authClient, cache)
 
 	var result map[string]string
-	err := client.GetJSON("/", nil, false, &result)
+	err := client.GetJSON(context.Background(), "/", nil, false, &result)
 	require.NoError(t, err)
 	assert.Equal(t, "world", result["hello"])
 }
@@ -54,7 +58,7 @@ func TestAPIClient_GetJSON_UsesCache(t *testing.T) {
 	client := flyHttp.NewEsiHttpClient("http://example.com", logger, authClient, cache)
 	var result map[string]string
 
-	err := client.GetJSON("/data", nil, true, &result)
+	err := client.GetJSON(context.Background(), "/data", nil, true, &result)
 	require.NoError(t, err)
 	assert.Equal(t, "value", result["cached"], "Should use cached data")
 
@@ -95,7 +99,7 @@ func TestAPIClient_GetJSON_TokenRefreshOnUnauthorized(t *testing.T) {
 	}
 
 	var result map[string]string
-	err := client.GetJSON("/", token, false, &result)
+	err := client.GetJSON(context.Background(), "/", token, false, &result)
 	require.NoError(t, err)
 	assert.Equal(t, "true", result["refreshed"])
 
@@ -127,7 +131,7 @@ func TestAPIClient_GetJSON_RetryOnServiceUnavailable(t *testing.T) {
 	client := flyHttp.NewEsiHttpClient(ts.URL, logger, authClient, cache)
 
 	var result map[string]string
-	err := client.GetJSON("/", nil, false, &result)
+	err := client.GetJSON(context.Background(), "/", nil, false, &result)
 	require.NoError(t, err)
 	assert.Equal(t, 3, callCount, "should have retried twice and succeeded on the third call")
 	assert.Equal(t, "ok", result["status"])
@@ -149,7 +153,7 @@ func TestAPIClient_GetJSON_FailsAfterMaxRetries(t *testing.T) {
 	client := flyHttp.NewEsiHttpClient(ts.URL, logger, authClient, cache)
 
 	var result map[string]string
-	err := client.GetJSON("/", nil, false, &result)
+	err := client.GetJSON(context.Background(), "/", nil, false, &result)
 	require.Error(t, err)
 
 	var cErr *flyErrors.CustomError
@@ -179,7 +183,97 @@ func TestAPIClient_GetJSON_NoToken_NoAuthHeaders(t *testing.T) {
 	client := flyHttp.NewEsiHttpClient(ts.URL, logger, authClient, cache)
 
 	var result map[string]bool
-	err := client.GetJSON("/", nil, false, &result)
+	err := client.GetJSON(context.Background(), "/", nil, false, &result)
 	require.NoError(t, err)
 	assert.True(t, result["success"])
 }
+
+// memoryCache is a CacheService holding entries in a map, the way
+// PersistentCacheService saves them: bytes only, without expirations.
+type memoryCache map[string][]byte
+
+func (c memoryCache) Get(key string) ([]byte, bool) {
+	data, found := c[key]
+	return data, found
+}
+
+func (c memoryCache) Set(key string, value []byte, _ time.Duration) { c[key] = value }
+func (c memoryCache) LoadCache() error                              { return nil }
+func (c memoryCache) SaveCache() error                              { return nil }
+
+// esiServer answers with ETag "v1" and an Expires maxAge after Date, and with
+// 304 when the request carries that ETag.
+func esiServer(t *testing.T, maxAge time.Duration, calls *int, revalidations *int) *httptest.Server {
+	t.Helper()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*calls++
+		now := time.Now().UTC()
+		w.Header().Set("Date", now.Format(http.TimeFormat))
+		w.Header().Set("Expires", now.Add(maxAge).Format(http.TimeFormat))
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			*revalidations++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		_, _ = w.Write([]byte(`{"owner":"` + r.Header.Get("Authorization") + `"}`))
+	}))
+	t.Cleanup(ts.Close)
+	return ts
+}
+
+// jwt builds an unsigned access token for a character.
+func jwt(sub string) string {
+	payload := base64.RawURLEncoding.EncodeToString([]byte(`{"sub":"` + sub + `"}`))
+	return "header." + payload + ".signature"
+}
+
+func TestAPIClient_GetJSON_HonorsExpires(t *testing.T) {
+	var calls, revalidations int
+	ts := esiServer(t, time.Hour, &calls, &revalidations)
+	client := flyHttp.NewEsiHttpClient(ts.URL, &testutil.MockLogger{}, &testutil.MockAuthClient{}, memoryCache{})
+
+	var result map[string]string
+	require.NoError(t, client.GetJSON(context.Background(), "/status/", nil, true, &result))
+	require.NoError(t, client.GetJSON(context.Background(), "/status/", nil, true, &result))
+	assert.Equal(t, 1, calls, "a response is fresh until Expires")
+}
+
+func TestAPIClient_GetJSON_RevalidatesExpired(t *testing.T) {
+	var calls, revalidations int
+	ts := esiServer(t, 0, &calls, &revalidations)
+	cache := memoryCache{}
+	client := flyHttp.NewEsiHttpClient(ts.URL, &testutil.MockLogger{}, &testutil.MockAuthClient{}, cache)
+	token := &oauth2.Token{AccessToken: jwt("CHARACTER:EVE:1")}
+
+	var result map[string]string
+	require.NoError(t, client.GetJSON(context.Background(), "/skills/", token, true, &result))
+
+	// A restarted client only has the saved bytes.
+	restarted := flyHttp.NewEsiHttpClient(ts.URL, &testutil.MockLogger{}, &testutil.MockAuthClient{}, memoryCache(maps.Clone(cache)))
+	result = nil
+	require.NoError(t, restarted.GetJSON(context.Background(), "/skills/", token, true, &result))
+
+	assert.Equal(t, 2, calls)
+	assert.Equal(t, 1, revalidations)
+	assert.Equal(t, "Bearer "+token.AccessToken, result["owner"], "a 304 serves the cached body")
+}
+
+func TestAPIClient_GetJSON_CachesPerTokenOwner(t *testing.T) {
+	var calls, revalidations int
+	ts := esiServer(t, time.Hour, &calls, &revalidations)
+	client := flyHttp.NewEsiHttpClient(ts.URL, &testutil.MockLogger{}, &testutil.MockAuthClient{}, memoryCache{})
+	first := &oauth2.Token{AccessToken: jwt("CHARACTER:EVE:1")}
+	second := &oauth2.Token{AccessToken: jwt("CHARACTER:EVE:2")}
+
+	var result map[string]string
+	require.NoError(t, client.GetJSON(context.Background(), "/skills/", first, true, &result))
+	require.NoError(t, client.GetJSON(context.Background(), "/skills/", second, true, &result))
+	assert.Equal(t, "Bearer "+second.AccessToken, result["owner"])
+
+	// A refreshed token for the same character uses the same entry.
+	refreshed := &oauth2.Token{AccessToken: jwt("CHARACTER:EVE:1") + "x"}
+	require.NoError(t, client.GetJSON(context.Background(), "/skills/", refreshed, true, &result))
+	assert.Equal(t, "Bearer "+first.AccessToken, result["owner"])
+	assert.Equal(t, 2, calls)
+}
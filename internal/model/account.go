@@ -42,19 +42,25 @@ type CharacterIdentity struct {
 	Role            string
 	MCT             bool
 	Training        string
+	Farm            bool // a skill farm character, tracked for extraction
 }
 
 type Character struct {
 	UserInfoResponse
 	CharacterSkillsResponse `json:"CharacterSkillsResponse"`
-	Location                int64  `json:"Location"`
-	LocationName            string `json:"LocationName"`
+	Location                int64                `json:"Location"`
+	LocationName            string               `json:"LocationName"`
+	Attributes              *CharacterAttributes `json:"Attributes,omitempty"`
+	Implants                []int32              `json:"Implants,omitempty"`
+	Clones                  *CharacterClones     `json:"Clones,omitempty"`
 
 	SkillQueue         []SkillQueue                `json:"SkillQueue"`
 	QualifiedPlans     map[string]bool             `json:"QualifiedPlans"`
 	PendingPlans       map[string]bool             `json:"PendingPlans"`
 	PendingFinishDates map[string]*time.Time       `json:"PendingFinishDates"`
 	MissingSkills      map[string]map[string]int32 `json:"MissingSkills"`
+	OmegaPlans         map[string]bool             `json:"OmegaPlans,omitempty"`
+	UnallocatedPlans   map[string]bool             `json:"UnallocatedPlans,omitempty"`
 }
 
 // UserInfoResponse represents the user information returned by the EVE SSO
@@ -83,6 +89,18 @@ type CharacterSkillsResponse struct {
 	UnallocatedSP int32           `json:"unallocated_sp"`
 }
 
+// CharacterAttributes is the ESI /characters/{id}/attributes/ response.
+type CharacterAttributes struct {
+	Charisma                 int32      `json:"charisma"`
+	Intelligence             int32      `json:"intelligence"`
+	Memory                   int32      `json:"memory"`
+	Perception               int32      `json:"perception"`
+	Willpower                int32      `json:"willpower"`
+	BonusRemaps              int32      `json:"bonus_remaps,omitempty"`
+	LastRemapDate            *time.Time `json:"last_remap_date,omitempty"`
+	AccruedRemapCooldownDate *time.Time `json:"accrued_remap_cooldown_date,omitempty"`
+}
+
 type AuthStatus struct {
 	AccountName      string `json:"accountName"`
 	CallBackComplete bool   `json:"callBackComplete"`
@@ -5,4 +5,44 @@ package model
 type CharacterUpdate struct {
 	Role *string `json:"Role,omitempty"`
 	MCT  *bool   `json:"MCT,omitempty"`
+	Farm *bool   `json:"Farm,omitempty"`
 }
+
+// Character refresh statuses reported by a bulk refresh.
+const (
+	RefreshQueued  = "queued"
+	RefreshRunning = "running"
+	RefreshDone    = "done"
+	RefreshFailed  = "failed"
+)
+
+// CharacterRefreshProgress is a character's progress through a bulk refresh.
+type CharacterRefreshProgress struct {
+	CharacterID   int64  `json:"characterId"`
+	CharacterName string `json:"characterName"`
+	Status        string `json:"status"`
+	Error         string `json:"error,omitempty"`
+}
+
+// BulkRefreshResult summarizes a refresh of every character.
+type BulkRefreshResult struct {
+	Total     int                        `json:"total"`
+	Refreshed int                        `json:"refreshed"`
+	Failed    int                        `json:"failed"`
+	Failures  []CharacterRefreshProgress `json:"failures"`
+}
+
+// CharacterEndpoint is a part of a character's ESI data that can be refreshed
+// on its own.
+type CharacterEndpoint string
+
+// Character endpoints, named as in the changes of a character:refreshed event.
+const (
+	EndpointAffiliation CharacterEndpoint = "affiliation" // name, corporation and alliance
+	EndpointSkills      CharacterEndpoint = "skills"
+	EndpointSkillQueue  CharacterEndpoint = "skillQueue" // also MCT and training
+	EndpointLocation    CharacterEndpoint = "location"
+	EndpointAttributes  CharacterEndpoint = "attributes"
+	EndpointImplants    CharacterEndpoint = "implants"
+	EndpointClones      CharacterEndpoint = "clones"
+)
@@ -0,0 +1,61 @@
+package model
+
+import "time"
+
+// CharacterClones is a character's home station and jump clones as of its
+// last refresh, with clone locations resolved to names.
+type CharacterClones struct {
+	HomeLocation          ClonePlace
+	JumpClones            []JumpClone
+	LastCloneJumpDate     *time.Time
+	LastStationChangeDate *time.Time
+}
+
+// ClonePlace is the station or structure a clone is in.
+type ClonePlace struct {
+	LocationID   int64
+	LocationType string // "station" or "structure"
+	Name         string // empty if the structure couldn't be resolved
+	SystemID     int64
+	SystemName   string
+}
+
+// JumpClone is a jump clone and the implants plugged into it.
+type JumpClone struct {
+	JumpCloneID int64
+	Name        string
+	Location    ClonePlace
+	Implants    []int32
+}
+
+// CloneImplant is an implant type with its name from invTypes.
+type CloneImplant struct {
+	TypeID int32
+	Name   string
+}
+
+// CloneInventoryClone is a jump clone in the clone inventory.
+type CloneInventoryClone struct {
+	JumpCloneID int64
+	Name        string
+	Location    ClonePlace
+	Implants    []CloneImplant
+}
+
+// CharacterCloneInventory is one character's clones in the clone inventory.
+type CharacterCloneInventory struct {
+	CharacterID    int64
+	CharacterName  string
+	AccountName    string
+	HomeLocation   ClonePlace
+	ActiveImplants []CloneImplant // implants in the character's current clone
+	JumpClones     []CloneInventoryClone
+	NextJumpAt     *time.Time // when the next clone jump is allowed; nil if it is now
+	CanJump        bool
+}
+
+// CloneInventory is every character's jump clones across accounts.
+type CloneInventory struct {
+	Characters []CharacterCloneInventory
+	JumpClones int
+}
@@ -18,15 +18,16 @@ type UserSelection struct {
 
 // ConfigData are user settings and other app specific configuration
 type ConfigData struct {
-	Roles               []string `json:"Roles"`         // in app created roles for organizing data
-	SettingsDir         string   `json:"SettingsDir"`   // directory where the settings are kept
-	LastBackupDir       string   `json:"LastBackupDir"` // directory used for the previous backup
-	DropDownSelections           // dropdown selections within the app
-	AutoUpdateFuzzworks *bool    `json:"AutoUpdateFuzzworks,omitempty"` // auto-update Fuzzworks data on startup (defaults to true)
-	EVEClientID         string   `json:"EVEClientID,omitempty"`         // EVE Online application client ID
-	EVEClientSecret     string   `json:"EVEClientSecret,omitempty"`     // EVE Online application client secret
-	EVECallbackURL      string   `json:"EVECallbackURL,omitempty"`      // EVE Online callback URL
-	SkillPlansRepoURL   string   `json:"SkillPlansRepoURL,omitempty"`   // GitHub repository URL for skill plans
+	Roles               []string        `json:"Roles"`         // in app created roles for organizing data
+	SettingsDir         string          `json:"SettingsDir"`   // directory where the settings are kept
+	LastBackupDir       string          `json:"LastBackupDir"` // directory used for the previous backup
+	DropDownSelections                  // dropdown selections within the app
+	AutoUpdateFuzzworks *bool           `json:"AutoUpdateFuzzworks,omitempty"` // auto-update Fuzzworks data on startup (defaults to true)
+	EVEClientID         string          `json:"EVEClientID,omitempty"`         // EVE Online application client ID
+	EVEClientSecret     string          `json:"EVEClientSecret,omitempty"`     // EVE Online application client secret
+	EVECallbackURL      string          `json:"EVECallbackURL,omitempty"`      // EVE Online callback URL
+	SkillPlansRepoURL   string          `json:"SkillPlansRepoURL,omitempty"`   // GitHub repository URL for skill plans
+	InjectorPrices      *InjectorPrices `json:"InjectorPrices,omitempty"`      // ISK prices for skill injector estimates
 }
 
 func init() {
@@ -3,6 +3,7 @@
 package model
 
 import (
+	"strings"
 	"time"
 )
 
@@ -30,7 +31,7 @@ type RawFileInfo struct {
 type Station struct {
 	SystemID int64  `json:"system_id"`
 	ID       int64  `json:"station_id"`
-	Name     string `json:"station_name"`
+	Name     string `json:"name"`
 }
 
 type Structure struct {
@@ -45,37 +46,223 @@ type CharacterLocation struct {
 	StructureID   int64 `json:"structure_id"`
 }
 
+// CloneLocation is ESI's /characters/{id}/clones/ response.
 type CloneLocation struct {
 	HomeLocation struct {
 		LocationID   int64  `json:"location_id"`
 		LocationType string `json:"location_type"`
 	} `json:"home_location"`
-	JumpClones []struct {
-		Implants     []int  `json:"implants"`
-		JumpCloneID  int64  `json:"jump_clone_id"`
-		LocationID   int64  `json:"location_id"`
-		LocationType string `json:"location_type"`
-	} `json:"jump_clones"`
+	JumpClones            []JumpCloneResponse `json:"jump_clones"`
+	LastCloneJumpDate     *time.Time          `json:"last_clone_jump_date,omitempty"`
+	LastStationChangeDate *time.Time          `json:"last_station_change_date,omitempty"`
+}
+
+// JumpCloneResponse is a jump clone in ESI's clones response.
+type JumpCloneResponse struct {
+	Implants     []int32 `json:"implants"`
+	JumpCloneID  int64   `json:"jump_clone_id"`
+	LocationID   int64   `json:"location_id"`
+	LocationType string  `json:"location_type"` // "station" or "structure"
+	Name         string  `json:"name,omitempty"`
 }
 
 // SkillPlanWithStatus holds detailed information about each eve plan
 type SkillPlanWithStatus struct {
-	Name                string
-	TypeId              int64 // used for image lookup
-	Skills              map[string]Skill
-	QualifiedCharacters []string
-	PendingCharacters   []string
-	MissingCharacters   []string
-	MissingSkills       map[string]map[string]int32 // Missing skills by character
-	Characters          []CharacterSkillPlanStatus  // List of characters with their status for this eve plan
+	Name                  string
+	TypeId                int64   // used for image lookup
+	Steps                 []Skill // Skills in plan order
+	Skills                map[string]Skill
+	Metadata              PlanMetadata
+	QualifiedCharacters   []string // characters meeting every recommended level
+	MinimumCharacters     []string // characters meeting every required level
+	PendingCharacters     []string
+	MissingCharacters     []string
+	OmegaCharacters       []string                        // Alpha characters the plan needs Omega for
+	UnallocatedCharacters []string                        // characters who qualify once their unallocated SP is spent
+	MissingSkills         map[string]map[string]int32     // Missing skills by character
+	TrainingEstimates     map[string]PlanTrainingEstimate // Remaining training by missing character
+	Characters            []CharacterSkillPlanStatus      // List of characters with their status for this eve plan
 }
 
 // CharacterSkillPlanStatus represents a character's status for a specific eve plan
 type CharacterSkillPlanStatus struct {
-	CharacterName     string
-	Status            string // "qualified", "pending", "missing"
-	MissingSkills     map[string]int32
-	PendingFinishDate *time.Time
+	CharacterName        string
+	Status               string // "qualified", "pending", "missing", "omega", "unallocated"
+	MeetsMinimum         bool
+	MeetsRecommended     bool
+	MissingSkills        map[string]int32
+	OmegaSkills          map[string]int32 // set for "omega" characters: skills above the Alpha cap
+	Allocation           []SPAllocation   // set for "unallocated" characters: where to spend unallocated SP
+	UnallocatedGapClosed float64          // share of the remaining SP unallocated SP covers, as a percentage
+	PendingFinishDate    *time.Time
+	TrainingEstimate     *PlanTrainingEstimate // set for "missing" characters
+	TrainedSP            int64                 // SP trained toward the plan's target levels
+	RequiredSP           int64                 // SP the plan's target levels need in total
+	Progress             float64               // TrainedSP as a percentage of RequiredSP
+}
+
+// SPAllocation is unallocated SP to apply to a skill to raise it from one
+// level to another.
+type SPAllocation struct {
+	SkillName   string
+	FromLevel   int32
+	ToLevel     int32
+	SkillPoints int64
+}
+
+// PlanTrainingEstimate is the remaining training a character needs to finish a plan.
+type PlanTrainingEstimate struct {
+	RemainingSP     int64
+	TrainingSeconds int64
+	Skills          []SkillTrainingEstimate
+}
+
+// SkillTrainingEstimate is the remaining training for a single missing skill.
+type SkillTrainingEstimate struct {
+	SkillName       string
+	CurrentLevel    int32
+	TargetLevel     int32
+	RemainingSP     int64
+	TrainingSeconds int64
+}
+
+// TrainingStep is one skill level a character still has to train. Queued
+// levels are already in the skill queue; QueuedUntil is nil when the queue is
+// paused, and the level still takes TrainingSeconds once it resumes. OmegaOnly levels are above an Alpha character's skill cap and
+// cannot be trained until the account is Omega.
+type TrainingStep struct {
+	SkillName       string
+	Level           int32
+	SkillPoints     int64
+	TrainingSeconds int64
+	Queued          bool
+	QueuedUntil     *time.Time
+	OmegaOnly       bool
+}
+
+// RemapAdvice is the attribute distribution that finishes a plan fastest for a
+// character, compared with the character's current attributes.
+type RemapAdvice struct {
+	CharacterName          string
+	PlanName               string
+	CurrentAttributes      CharacterAttributes
+	OptimalAttributes      CharacterAttributes
+	CurrentTrainingSeconds int64
+	OptimalTrainingSeconds int64
+	TimeSavedSeconds       int64
+	BonusRemaps            int32
+	NextRemapDate          *time.Time
+	RemapAvailable         bool
+}
+
+// TrainingScenario is a hypothetical set of attribute bonuses: implants giving
+// ImplantBonus to every attribute and a cerebral accelerator adding
+// AcceleratorBonus on top.
+type TrainingScenario struct {
+	ImplantBonus     int32 `json:"implantBonus"`
+	AcceleratorBonus int32 `json:"acceleratorBonus"`
+}
+
+// TrainingScenarioComparison compares a character's plan training with and
+// without a TrainingScenario.
+type TrainingScenarioComparison struct {
+	CharacterName    string
+	PlanName         string
+	Scenario         TrainingScenario
+	Current          PlanTrainingEstimate
+	WithScenario     PlanTrainingEstimate
+	TimeSavedSeconds int64
+}
+
+// SkillRecommendationRequest tunes next-skill recommendations. Weights scale
+// how much a plan counts: a plan's own weight wins over the highest weight of
+// its tags, unweighted plans count 1, and a weight of 0 leaves a plan out.
+type SkillRecommendationRequest struct {
+	Limit       int                `json:"limit"`
+	PlanWeights map[string]float64 `json:"planWeights"`
+	TagWeights  map[string]float64 `json:"tagWeights"`
+}
+
+// SkillRecommendation is a skill level worth training next. Score is the
+// weighted plan value gained per hour of training: one per plan the level
+// completes plus the fraction of each plan's SP it trains.
+type SkillRecommendation struct {
+	SkillName       string
+	Level           int32
+	SkillPoints     int64
+	TrainingSeconds int64
+	Score           float64
+	UnlocksPlans    []string
+	AdvancesPlans   []PlanAdvance
+}
+
+// PlanAdvance is how far a recommended skill level moves a plan forward.
+type PlanAdvance struct {
+	PlanName       string
+	ProgressGained float64 // percentage points of the plan's SP
+}
+
+// FittingEvaluation is the status of every character against the skills an
+// EFT fitting needs. UnknownItems lists fitting items not found in invTypes.
+type FittingEvaluation struct {
+	Plan         SkillPlanWithStatus
+	UnknownItems []string
+}
+
+// PlanSource records what wrote a version of a plan file.
+type PlanSource string
+
+const (
+	PlanSourceUser    PlanSource = "user"    // created or edited through the API
+	PlanSourceCopy    PlanSource = "copy"    // copied from another plan
+	PlanSourceImport  PlanSource = "import"  // imported from an EVEMon plan
+	PlanSourceFitting PlanSource = "fitting" // built from an EFT fitting
+	PlanSourceGitHub  PlanSource = "github"  // downloaded from the plans repository
+	PlanSourceRestore PlanSource = "restore" // restored from an earlier version
+	PlanSourceLocal   PlanSource = "local"   // already on disk before history was kept
+)
+
+// PlanVersion is a saved version of a plan file.
+type PlanVersion struct {
+	ID        string
+	Timestamp time.Time
+	Source    PlanSource
+}
+
+// PlanDiff is the difference in skills between two versions of a plan.
+type PlanDiff struct {
+	PlanName string
+	From     string
+	To       string
+	Added    []Skill
+	Removed  []Skill
+	Raised   []SkillLevelChange
+	Lowered  []SkillLevelChange
+}
+
+// SkillLevelChange is a skill whose planned level differs between versions.
+type SkillLevelChange struct {
+	Name      string
+	FromLevel int
+	ToLevel   int
+}
+
+// PlanDiagnostic is a problem found on one line of a skill plan. Suggestions
+// are the closest skill names by edit distance, and IsSkill reports whether the
+// line names a known skill.
+type PlanDiagnostic struct {
+	Line        int
+	Text        string
+	Problem     string
+	Suggestions []string
+	IsSkill     bool
+}
+
+// PlanValidation is the validation report of a single skill plan.
+type PlanValidation struct {
+	Name        string
+	Valid       bool
+	Diagnostics []PlanDiagnostic
 }
 
 type SkillResponse struct {
@@ -96,19 +283,80 @@ type SkillQueue struct {
 	TrainingStartSP int32      `json:"training_start_sp"`
 }
 
-// Skill represents a eve with a name and level.
+// Skill is a skill at a target level. Required, when set, is the lower level a
+// plan accepts as the minimum; Level is then the recommended level.
 type Skill struct {
-	Name  string `json:"Name"`
-	Level int    `json:"Level"`
+	Name     string `json:"Name"`
+	Level    int    `json:"Level"`
+	Required int    `json:"Required,omitempty"`
+}
+
+// MinimumLevel returns the level a character needs to meet a plan's minimum.
+func (s Skill) MinimumLevel() int {
+	if s.Required > 0 && s.Required < s.Level {
+		return s.Required
+	}
+	return s.Level
 }
 
-// SkillPlan represents a eve plan, with the plan name and a map of unique skills.
+// PlanMetadata is the front matter of a plan file.
+type PlanMetadata struct {
+	Description string   `json:"Description,omitempty"`
+	Tags        []string `json:"Tags,omitempty"`
+	Owner       string   `json:"Owner,omitempty"`
+	Doctrine    string   `json:"Doctrine,omitempty"`
+	ShipTypeID  int64    `json:"ShipTypeID,omitempty"` // ship shown as the plan's icon
+	Roles       []string `json:"Roles,omitempty"`
+}
+
+// HasTag reports whether the plan is tagged with tag, ignoring case.
+func (m PlanMetadata) HasTag(tag string) bool {
+	for _, t := range m.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// SkillPlan represents a eve plan: its skill steps in the order the author wrote
+// them, and a map of unique skills at the highest level listed, derived from Steps.
 type SkillPlan struct {
 	Name                string           `json:"Name"`
+	Steps               []Skill          `json:"Steps"`
 	Skills              map[string]Skill `json:"Skills"`
 	QualifiedCharacters []string         `json:"QualifiedCharacters"`
 	PendingCharacters   []string         `json:"PendingCharacters"`
 	Icon                string           `json:"icon,omitempty"` // Optional icon identifier
+	Metadata            PlanMetadata     `json:"Metadata"`
+}
+
+// SkillPrerequisite is a skill and minimum level required by a type, taken from
+// the SDE requiredSkillN / requiredSkillNLevel dogma attributes.
+type SkillPrerequisite struct {
+	SkillID int32 `json:"skillId"`
+	Level   int32 `json:"level"`
+}
+
+// SkillAttributes holds the SDE training parameters of a skill. The primary and
+// secondary attributes are dogma attribute IDs (164 charisma .. 168 willpower).
+type SkillAttributes struct {
+	Rank               int32 `json:"rank"`
+	PrimaryAttribute   int32 `json:"primaryAttribute"`
+	SecondaryAttribute int32 `json:"secondaryAttribute"`
+}
+
+// CloneGrade is an SDE clone grade: the highest level an Alpha clone of one
+// race can use of each skill. Skills not listed cannot be used at all.
+type CloneGrade struct {
+	Name   string            `json:"name"`
+	Skills []CloneGradeSkill `json:"skills"`
+}
+
+// CloneGradeSkill is a clone grade's cap on one skill.
+type CloneGradeSkill struct {
+	TypeID int32 `json:"typeID"`
+	Level  int32 `json:"level"`
 }
 
 // SkillType represents a eve with typeID, typeName, and description.
@@ -131,3 +379,16 @@ type UserFile struct {
 	Name   string `json:"name"`
 	Mtime  string `json:"mtime"`
 }
+
+// ESIGovernorState is the ESI error budget and rate limiting as last seen by
+// the request governor.
+type ESIGovernorState struct {
+	ErrorLimitRemain *int       // errors left in the current window; nil until ESI reports it
+	ErrorLimitReset  *time.Time // when the error window resets
+	RetryAfter       *time.Time // set by a 420/429 response's Retry-After
+	Paused           bool       // ESI calls are currently held back
+	PausedUntil      *time.Time
+	Reason           string // why calls are paused
+	ThrottledCalls   int64  // calls that had to wait
+	RateLimited      int64  // 420/429 responses received
+}
@@ -0,0 +1,26 @@
+package model
+
+import "time"
+
+// FarmCharacter is the extraction state of a character flagged as a skill farm.
+type FarmCharacter struct {
+	CharacterID      int64
+	CharacterName    string
+	AccountName      string
+	TotalSP          int64
+	ExtractableSP    int64      // SP above the extraction floor, in whole extractor chunks
+	Extractions      int        // skill extractors that can be used now
+	SPPerHour        float64    // current training rate; 0 when not training
+	QueueEmpty       bool       // nothing is training
+	QueueFinishDate  *time.Time // when the skill queue runs out
+	NextExtractionAt *time.Time // when the queue trains another extractor's worth; nil if it doesn't
+}
+
+// FarmSummary is every farm character with totals across accounts.
+type FarmSummary struct {
+	Characters    []FarmCharacter
+	TotalSP       int64
+	ExtractableSP int64
+	Extractions   int
+	EmptyQueues   int
+}
@@ -0,0 +1,91 @@
+package model
+
+import "time"
+
+// PlanningRequest asks for plans to be assigned to characters so every
+// coverage target is met as soon as possible.
+type PlanningRequest struct {
+	Targets []CoverageTarget `json:"targets"`
+	// TrainingSlots is the number of characters that can train at once per
+	// account name: 1, plus one per multiple character training certificate.
+	// Accounts not listed have one slot.
+	TrainingSlots map[string]int `json:"trainingSlots"`
+}
+
+// CoverageTarget asks for Count characters able to fly a plan, or any plan
+// whose metadata lists Role. Exactly one of Plan and Role is set.
+type CoverageTarget struct {
+	Plan  string `json:"plan,omitempty"`
+	Role  string `json:"role,omitempty"`
+	Count int    `json:"count"`
+}
+
+// PlanningResult is a training assignment: when each target is covered, and
+// what every character has to train.
+type PlanningResult struct {
+	Coverage  []TargetCoverage
+	Schedules []CharacterSchedule
+	CoveredAt *time.Time // when every target is met; nil if some cannot be
+}
+
+// TargetCoverage is the projected coverage of one target.
+type TargetCoverage struct {
+	Target     CoverageTarget
+	Characters []CoverageAssignment
+	Met        bool
+	CoveredAt  *time.Time // when the last needed character is ready
+}
+
+// CoverageAssignment is a character counted toward a target, flying PlanName.
+type CoverageAssignment struct {
+	CharacterName string
+	PlanName      string
+	ReadyAt       time.Time
+}
+
+// CharacterSchedule is the ordered training a character is assigned. Start
+// is when the new training begins, after the character's current queue.
+type CharacterSchedule struct {
+	CharacterName string
+	AccountName   string
+	Start         time.Time
+	Finish        time.Time
+	Steps         []ScheduledStep
+}
+
+// ScheduledStep is one skill level in a character's schedule.
+type ScheduledStep struct {
+	PlanName  string
+	SkillName string
+	Level     int32
+	Start     time.Time
+	Finish    time.Time
+}
+
+// InjectorPrices are the user's ISK prices for large and small skill
+// injectors. A zero price is not set.
+type InjectorPrices struct {
+	Large float64 `json:"large"`
+	Small float64 `json:"small"`
+}
+
+// InjectorEstimate is the skill injectors that close a character's SP gap for
+// a plan. Injectors are only counted while they don't overshoot the gap, so
+// RemainingSP, less than one small injector's worth, is left to train. To
+// inject it as well takes ClosingSmallInjectors instead, one more than
+// SmallInjectors whenever RemainingSP is left, for ClosingCost.
+type InjectorEstimate struct {
+	CharacterName            string
+	PlanName                 string
+	TotalSP                  int64 // SP before injecting, unallocated SP included
+	SkillPointsNeeded        int64 // SP the plan needs beyond the queue and unallocated SP
+	LargeInjectors           int
+	SmallInjectors           int
+	InjectedSP               int64
+	RemainingSP              int64
+	RemainingTrainingSeconds int64
+	TotalCost                *float64 // nil unless the prices of the injectors used are set
+	ClosingSmallInjectors    int
+	ClosingCost              *float64 // nil unless the prices of the injectors used are set
+	NeedsOmega               bool     // the plan needs skills above the Alpha caps
+}
@@ -0,0 +1,55 @@
+package eve
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/guarzo/canifly/internal/model"
+)
+
+// LoadAlphaSkillCaps loads the highest level an Alpha clone can use of each
+// skill from the downloaded SDE clone grades. Alpha grades differ only by race
+// starter skills, so a skill's cap is the highest any grade allows.
+func (s *SkillStore) LoadAlphaSkillCaps() error {
+	s.logger.Infof("load alpha skill caps")
+
+	path := filepath.Join(s.basePath, "config", "fuzzworks", "cloneGrades.json")
+	data, err := s.fs.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("clone grades not found - ensure EVE data is downloaded: %w", err)
+	}
+
+	var grades map[string]model.CloneGrade
+	if err := json.Unmarshal(data, &grades); err != nil {
+		return fmt.Errorf("failed to parse clone grades: %w", err)
+	}
+
+	caps := make(map[int32]int32)
+	for _, grade := range grades {
+		for _, skill := range grade.Skills {
+			caps[skill.TypeID] = max(caps[skill.TypeID], skill.Level)
+		}
+	}
+	if len(caps) == 0 {
+		return fmt.Errorf("clone grades contain no skill caps")
+	}
+
+	s.mut.Lock()
+	s.alphaSkillCaps = caps
+	s.mut.Unlock()
+
+	s.logger.Debugf("Loaded Alpha caps for %d skills from %d clone grades", len(caps), len(grades))
+	return nil
+}
+
+// GetAlphaSkillCap returns the highest level of a skill an Alpha clone can use,
+// 0 for Omega-only skills. ok is false when the clone grades are not loaded.
+func (s *SkillStore) GetAlphaSkillCap(typeID int32) (level int32, ok bool) {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+	if s.alphaSkillCaps == nil {
+		return 0, false
+	}
+	return s.alphaSkillCaps[typeID], true
+}
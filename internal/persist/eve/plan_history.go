@@ -0,0 +1,127 @@
+package eve
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/guarzo/canifly/internal/model"
+)
+
+const planHistoryDir = "plan_history"
+
+// recordPlanVersion keeps a copy of content in the plan's history. previous is
+// the file content being replaced, if any; it is kept first when the plan has
+// no history yet, so the first overwrite doesn't lose it. Content identical to
+// the latest version is not recorded again. History is best effort: failures
+// are logged and never fail the write itself.
+func (s *SkillStore) recordPlanVersion(planName string, previous, content []byte, source model.PlanSource) {
+	s.historyMut.Lock()
+	defer s.historyMut.Unlock()
+
+	dir := filepath.Join(s.basePath, planHistoryDir, planName)
+	versions, err := s.readPlanHistory(dir)
+	if err != nil {
+		s.logger.Warnf("Failed to read history of skill plan %s: %v", planName, err)
+		return
+	}
+
+	var latest []byte
+	if len(versions) > 0 {
+		if latest, err = s.fs.ReadFile(filepath.Join(dir, versionFileName(versions[0]))); err != nil {
+			s.logger.Warnf("Failed to read latest version of skill plan %s: %v", planName, err)
+		}
+	} else if previous != nil && !bytes.Equal(previous, content) {
+		if err := s.writePlanVersion(dir, previous, model.PlanSourceLocal); err != nil {
+			s.logger.Warnf("Failed to keep previous version of skill plan %s: %v", planName, err)
+		}
+	}
+
+	if latest != nil && bytes.Equal(latest, content) {
+		return
+	}
+	if err := s.writePlanVersion(dir, content, source); err != nil {
+		s.logger.Warnf("Failed to record version of skill plan %s: %v", planName, err)
+	}
+}
+
+// writePlanVersion writes content as a new version. Version IDs are
+// nanosecond timestamps, bumped when the clock hasn't moved since the last one.
+func (s *SkillStore) writePlanVersion(dir string, content []byte, source model.PlanSource) error {
+	id := time.Now().UnixNano()
+	if id <= s.lastVersionID {
+		id = s.lastVersionID + 1
+	}
+	s.lastVersionID = id
+
+	if err := s.fs.MkdirAll(dir, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+	version := model.PlanVersion{ID: strconv.FormatInt(id, 10), Source: source}
+	return s.fs.WriteFile(filepath.Join(dir, versionFileName(version)), content, 0644)
+}
+
+// readPlanHistory lists the versions in a plan's history directory, newest first.
+func (s *SkillStore) readPlanHistory(dir string) ([]model.PlanVersion, error) {
+	entries, err := s.fs.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []model.PlanVersion{}, nil
+		}
+		return nil, err
+	}
+
+	versions := make([]model.PlanVersion, 0, len(entries))
+	for _, entry := range entries {
+		id, source, ok := strings.Cut(strings.TrimSuffix(entry.Name(), ".txt"), "-")
+		nanos, err := strconv.ParseInt(id, 10, 64)
+		if entry.IsDir() || !ok || err != nil {
+			continue
+		}
+		versions = append(versions, model.PlanVersion{
+			ID:        id,
+			Timestamp: time.Unix(0, nanos).UTC(),
+			Source:    model.PlanSource(source),
+		})
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].Timestamp.After(versions[j].Timestamp)
+	})
+	return versions, nil
+}
+
+func versionFileName(version model.PlanVersion) string {
+	return fmt.Sprintf("%s-%s.txt", version.ID, version.Source)
+}
+
+// GetSkillPlanHistory lists the saved versions of a plan, newest first.
+func (s *SkillStore) GetSkillPlanHistory(planName string) ([]model.PlanVersion, error) {
+	s.historyMut.Lock()
+	defer s.historyMut.Unlock()
+	return s.readPlanHistory(filepath.Join(s.basePath, planHistoryDir, planName))
+}
+
+// GetSkillPlanVersion returns the content of one saved version of a plan. It
+// returns an os.ErrNotExist error when the version is unknown.
+func (s *SkillStore) GetSkillPlanVersion(planName, versionID string) ([]byte, error) {
+	s.historyMut.Lock()
+	defer s.historyMut.Unlock()
+
+	dir := filepath.Join(s.basePath, planHistoryDir, planName)
+	versions, err := s.readPlanHistory(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, version := range versions {
+		if version.ID == versionID {
+			return s.fs.ReadFile(filepath.Join(dir, versionFileName(version)))
+		}
+	}
+	return nil, fmt.Errorf("version %s of skill plan %s: %w", versionID, planName, os.ErrNotExist)
+}
@@ -1,11 +1,9 @@
 package eve
 
 import (
-	"bufio"
 	"fmt"
 	"os"
 	"path/filepath"
-	"strconv"
 	"strings"
 	"sync"
 
@@ -27,22 +25,34 @@ type SkillStore struct {
 	fs               persist.FileSystem
 	basePath         string
 	skillPlans       map[string]model.SkillPlan
+	planSources      map[string]skillplans.ParsedPlan
 	skillTypes       map[string]model.SkillType
 	skillIdToType    map[string]model.SkillType
+	prerequisites    map[int32][]model.SkillPrerequisite
+	skillAttributes  map[int32]model.SkillAttributes
+	attributeBonuses map[int32]model.CharacterAttributes
+	alphaSkillCaps   map[int32]int32 // nil until clone grades are loaded
+	shipTypes        map[int32]bool  // nil until type attributes are loaded
 	githubDownloader *skillplans.GitHubDownloader
 	mut              sync.RWMutex
+	historyMut       sync.Mutex // guards plan history files and lastVersionID
+	lastVersionID    int64
 }
 
 // NewSkillStore now accepts a FileSystem and a basePath for writable directories.
 // The githubDownloader parameter is optional - pass it to enable downloading skill plans from GitHub.
 func NewSkillStore(logger interfaces.Logger, fs persist.FileSystem, basePath string, opts ...func(*SkillStore)) *SkillStore {
 	store := &SkillStore{
-		logger:        logger,
-		fs:            fs,
-		basePath:      basePath,
-		skillPlans:    make(map[string]model.SkillPlan),
-		skillTypes:    make(map[string]model.SkillType),
-		skillIdToType: make(map[string]model.SkillType),
+		logger:           logger,
+		fs:               fs,
+		basePath:         basePath,
+		skillPlans:       make(map[string]model.SkillPlan),
+		planSources:      make(map[string]skillplans.ParsedPlan),
+		skillTypes:       make(map[string]model.SkillType),
+		skillIdToType:    make(map[string]model.SkillType),
+		prerequisites:    make(map[int32][]model.SkillPrerequisite),
+		skillAttributes:  make(map[int32]model.SkillAttributes),
+		attributeBonuses: make(map[int32]model.CharacterAttributes),
 	}
 
 	// Apply options
@@ -53,10 +63,14 @@ func NewSkillStore(logger interfaces.Logger, fs persist.FileSystem, basePath str
 	return store
 }
 
-// WithGitHubDownloader is an option to set the GitHub downloader
+// WithGitHubDownloader is an option to set the GitHub downloader. Downloaded
+// plans are recorded in the plan history.
 func WithGitHubDownloader(downloader *skillplans.GitHubDownloader) func(*SkillStore) {
 	return func(s *SkillStore) {
 		s.githubDownloader = downloader
+		downloader.OnDownload(func(planName string, previous, content []byte) {
+			s.recordPlanVersion(planName, previous, content, model.PlanSourceGitHub)
+		})
 	}
 }
 
@@ -76,12 +90,14 @@ func (s *SkillStore) LoadSkillPlans() error {
 		}
 	}
 
-	plans, err := s.loadSkillPlans(writableDir)
+	sources, err := s.loadSkillPlans(writableDir)
 	if err != nil {
 		return fmt.Errorf("failed to load eve plans: %w", err)
 	}
+	plans := s.resolvePlans(sources)
 
 	s.mut.Lock()
+	s.planSources = sources
 	s.skillPlans = plans
 	s.mut.Unlock()
 
@@ -89,37 +105,64 @@ func (s *SkillStore) LoadSkillPlans() error {
 	return nil
 }
 
-func (s *SkillStore) SaveSkillPlan(planName string, skills map[string]model.Skill) error {
-	if len(skills) == 0 {
-		return fmt.Errorf("cannot save an empty eve plan for planName: %s", planName)
-	}
+// SaveSkillPlan writes a plan file exactly as given, so plans round-trip
+// byte-for-byte, and indexes its parsed steps in memory. The plan's includes
+// must resolve; plans that include this one pick up the new content. The
+// content is also recorded in the plan's history under source.
+func (s *SkillStore) SaveSkillPlan(planName string, content []byte, source model.PlanSource) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
 
-	planFilePath := filepath.Join(s.basePath, plansDir, planName+".txt")
+	sources := make(map[string]skillplans.ParsedPlan, len(s.planSources)+1)
+	for name, source := range s.planSources {
+		sources[name] = source
+	}
+	sources[planName] = s.parsePlanSource(planName, content)
 
-	var sb strings.Builder
-	for skillName, skill := range skills {
-		sb.WriteString(fmt.Sprintf("%s %d\n", skillName, skill.Level))
+	steps, err := skillplans.ResolveSteps(planName, sources)
+	if err != nil {
+		return fmt.Errorf("cannot save eve plan %s: %w", planName, err)
+	}
+	if len(steps) == 0 {
+		return fmt.Errorf("cannot save an empty eve plan for planName: %s", planName)
 	}
 
-	if err := persist.AtomicWriteFile(s.fs, planFilePath, []byte(sb.String()), 0644); err != nil {
+	planFilePath := filepath.Join(s.basePath, plansDir, planName+".txt")
+	previous, _ := s.fs.ReadFile(planFilePath)
+	if err := persist.AtomicWriteFile(s.fs, planFilePath, content, 0644); err != nil {
 		return fmt.Errorf("failed to write plan file: %w", err)
 	}
+	s.recordPlanVersion(planName, previous, content, source)
 
-	planKey := planName
-	s.mut.Lock()
-	s.skillPlans[planKey] = model.SkillPlan{Name: planKey, Skills: skills}
-	s.mut.Unlock()
-	s.logger.Infof("Saved eve plan %s with %d skills", planKey, len(skills))
-
-	// Debug: log current number of plans in memory
-	s.mut.RLock()
-	planCount := len(s.skillPlans)
-	s.mut.RUnlock()
-	s.logger.Infof("Total skill plans in memory after save: %d", planCount)
+	s.planSources = sources
+	s.skillPlans = s.resolvePlans(sources)
+	s.logger.Infof("Saved eve plan %s with %d steps", planName, len(steps))
+	s.logger.Infof("Total skill plans in memory after save: %d", len(s.skillPlans))
 
 	return nil
 }
 
+// resolvePlans builds every plan from its parsed source, splicing in included
+// plans. A plan whose includes cannot be resolved keeps only its own steps.
+func (s *SkillStore) resolvePlans(sources map[string]skillplans.ParsedPlan) map[string]model.SkillPlan {
+	plans := make(map[string]model.SkillPlan, len(sources))
+	for name, source := range sources {
+		steps, err := skillplans.ResolveSteps(name, sources)
+		if err != nil {
+			s.logger.Warnf("Skill plan %s: %v; using its own skills only", name, err)
+			steps = source.Steps
+		}
+		plans[name] = model.SkillPlan{
+			Name:     name,
+			Steps:    steps,
+			Skills:   skillplans.IndexSteps(steps),
+			Icon:     source.Icon,
+			Metadata: source.Metadata,
+		}
+	}
+	return plans
+}
+
 func (s *SkillStore) GetSkillPlans() map[string]model.SkillPlan {
 	s.mut.RLock()
 	defer s.mut.RUnlock()
@@ -149,8 +192,8 @@ func (s *SkillStore) GetSkillPlanFile(planName string) ([]byte, error) {
 	return os.ReadFile(filePath)
 }
 
-func (s *SkillStore) loadSkillPlans(dir string) (map[string]model.SkillPlan, error) {
-	plans := make(map[string]model.SkillPlan)
+func (s *SkillStore) loadSkillPlans(dir string) (map[string]skillplans.ParsedPlan, error) {
+	plans := make(map[string]skillplans.ParsedPlan)
 
 	// We need to list files in dir. Since we're using fs abstraction for reading,
 	// we might still rely on os.ReadDir if fs does not provide a listing method.
@@ -168,76 +211,27 @@ func (s *SkillStore) loadSkillPlans(dir string) (map[string]model.SkillPlan, err
 		planName := strings.TrimSuffix(entry.Name(), ".txt")
 		path := filepath.Join(dir, entry.Name())
 
-		parsed, err := s.readSkillsFromFile(path)
+		data, err := s.fs.ReadFile(path)
 		if err != nil {
-			return nil, fmt.Errorf("failed to read skills from %s: %w", path, err)
-		}
-		plans[planName] = model.SkillPlan{
-			Name:   planName,
-			Skills: parsed.Skills,
-			Icon:   parsed.Icon,
+			return nil, fmt.Errorf("failed to read eve plan file %s: %w", path, err)
 		}
+		plans[planName] = s.parsePlanSource(planName, data)
 	}
 
 	return plans, nil
 }
 
-// ParsedSkillPlan holds the result of parsing a skill plan file
-type ParsedSkillPlan struct {
-	Skills map[string]model.Skill
-	Icon   string // Optional icon identifier
-}
-
-func (s *SkillStore) readSkillsFromFile(filePath string) (*ParsedSkillPlan, error) {
-	data, err := s.fs.ReadFile(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read eve plan file %s: %w", filePath, err)
-	}
-
-	result := &ParsedSkillPlan{
-		Skills: make(map[string]model.Skill),
-	}
-
-	scanner := bufio.NewScanner(strings.NewReader(string(data)))
-	lineNumber := 0
-	for scanner.Scan() {
-		lineNumber++
-		line := scanner.Text()
-		trimmed := strings.TrimSpace(line)
-
-		// Check for icon directive
-		if strings.HasPrefix(trimmed, "# icon:") {
-			result.Icon = strings.TrimSpace(strings.TrimPrefix(trimmed, "# icon:"))
-			continue
-		}
-
-		// Skip empty lines and other comments
-		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
-			continue
-		}
-
-		parts := strings.Fields(line)
-		if len(parts) < 2 {
-			return nil, fmt.Errorf("invalid format in file %s at line %d: %s", filePath, lineNumber, line)
-		}
-
-		skillLevelStr := parts[len(parts)-1]
-		skillName := strings.Join(parts[:len(parts)-1], " ")
-		skillLevel, err := strconv.Atoi(skillLevelStr)
-		if err != nil {
-			return nil, fmt.Errorf("invalid eve level in %s at line %d: %s", filePath, lineNumber, skillLevelStr)
-		}
-
-		if currentSkill, exists := result.Skills[skillName]; !exists || skillLevel > currentSkill.Level {
-			result.Skills[skillName] = model.Skill{Name: skillName, Level: skillLevel}
-		}
-	}
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("error scanning file %s: %w", filePath, err)
+// parsePlanSource parses plan file content. Lines that cannot be parsed are
+// logged and skipped rather than failing the whole plan.
+func (s *SkillStore) parsePlanSource(planName string, content []byte) skillplans.ParsedPlan {
+	parsed := skillplans.ParsePlan(string(content))
+	for _, lineErr := range parsed.Errors {
+		s.logger.Warnf("Skipping invalid line in skill plan %s: %v", planName, lineErr)
 	}
 
-	s.logger.Debugf("Read %d skills from %s (icon: %s)", len(result.Skills), filePath, result.Icon)
-	return result, nil
+	s.logger.Debugf("Read %d steps and %d includes for plan %s (icon: %s)",
+		len(parsed.Steps), len(parsed.Includes), planName, parsed.Icon)
+	return parsed
 }
 
 func (s *SkillStore) LoadSkillTypes() error {
@@ -362,7 +356,8 @@ func (s *SkillStore) DeleteSkillPlan(planName string) error {
 	}
 
 	s.mut.Lock()
-	delete(s.skillPlans, planName)
+	delete(s.planSources, planName)
+	s.skillPlans = s.resolvePlans(s.planSources)
 	s.mut.Unlock()
 
 	s.logger.Infof("Deleted eve plan %s", planName)
@@ -23,12 +23,7 @@ func TestSkillStore_SaveAndDeleteSkillPlan(t *testing.T) {
 	// Ensure the plans directory exists
 	require.NoError(t, store.LoadSkillPlans())
 
-	skills := map[string]model.Skill{
-		"Gunnery":  {Name: "Gunnery", Level: 5},
-		"Missiles": {Name: "Missiles", Level: 3},
-	}
-
-	err := store.SaveSkillPlan("myplan", skills)
+	err := store.SaveSkillPlan("myplan", []byte("Gunnery 5\nMissiles 3\n"), model.PlanSourceUser)
 	assert.NoError(t, err, "Saving skill plan should succeed")
 
 	planFile := filepath.Join(basePath, "plans", "myplan.txt")
@@ -50,10 +45,7 @@ func TestSkillStore_GetSkillPlanFile(t *testing.T) {
 	// Ensure the plans directory is created
 	require.NoError(t, store.LoadSkillPlans())
 
-	skills := map[string]model.Skill{
-		"Engineering": {Name: "Engineering", Level: 4},
-	}
-	err := store.SaveSkillPlan("engineering_plan", skills)
+	err := store.SaveSkillPlan("engineering_plan", []byte("Engineering 4\n"), model.PlanSourceUser)
 	require.NoError(t, err)
 
 	data, err := store.GetSkillPlanFile("engineering_plan")
@@ -78,8 +70,7 @@ func TestSkillStore_GetSkillPlans(t *testing.T) {
 	require.NoError(t, os.MkdirAll(plansDir, 0755), "Failed to create plans directory")
 
 	// Save a plan
-	skills := map[string]model.Skill{"Drones": {Name: "Drones", Level: 2}}
-	err := store.SaveSkillPlan("drones_plan", skills)
+	err := store.SaveSkillPlan("drones_plan", []byte("Drones 2\n"), model.PlanSourceUser)
 	require.NoError(t, err)
 
 	// Now GetSkillPlans should return exactly one
@@ -118,6 +109,67 @@ Medium Hybrid Turret 3`
 	assert.Equal(t, 5, plans["test_plan"].Skills["Gunnery"].Level)
 }
 
+func TestSkillStore_SaveSkillPlanPreservesOrder(t *testing.T) {
+	logger := &testutil.MockLogger{}
+	fs := persist.OSFileSystem{}
+	basePath := t.TempDir()
+
+	store := eve.NewSkillStore(logger, fs, basePath)
+	require.NoError(t, store.LoadSkillPlans())
+
+	content := "icon: https://images.evetech.net/alliances/1/logo\n" +
+		"CPU Management 1\nCPU Management 2\n# capacitor next\nCapacitor Management II\nCPU Management 3\n"
+	require.NoError(t, store.SaveSkillPlan("magic", []byte(content), model.PlanSourceUser))
+
+	data, err := store.GetSkillPlanFile("magic")
+	require.NoError(t, err)
+	assert.Equal(t, content, string(data), "plan file should round-trip byte-for-byte")
+
+	// Reload from disk to make sure the order survives a restart.
+	require.NoError(t, store.LoadSkillPlans())
+	plan := store.GetSkillPlans()["magic"]
+	assert.Equal(t, "https://images.evetech.net/alliances/1/logo", plan.Icon)
+	assert.Equal(t, []model.Skill{
+		{Name: "CPU Management", Level: 1},
+		{Name: "CPU Management", Level: 2},
+		{Name: "Capacitor Management", Level: 2},
+		{Name: "CPU Management", Level: 3},
+	}, plan.Steps)
+	assert.Equal(t, map[string]model.Skill{
+		"CPU Management":       {Name: "CPU Management", Level: 3},
+		"Capacitor Management": {Name: "Capacitor Management", Level: 2},
+	}, plan.Skills)
+}
+
+func TestSkillStore_SaveSkillPlanResolvesIncludes(t *testing.T) {
+	logger := &testutil.MockLogger{}
+	fs := persist.OSFileSystem{}
+	basePath := t.TempDir()
+
+	store := eve.NewSkillStore(logger, fs, basePath)
+	require.NoError(t, store.LoadSkillPlans())
+
+	require.NoError(t, store.SaveSkillPlan("base", []byte("CPU Management 4\n"), model.PlanSourceUser))
+	require.NoError(t, store.SaveSkillPlan("doctrine", []byte("# include: base\nLogistics Cruisers 4\n"), model.PlanSourceUser))
+	assert.Equal(t, 4, store.GetSkillPlans()["doctrine"].Skills["CPU Management"].Level)
+
+	// Updating the base plan flows into every plan built on it.
+	require.NoError(t, store.SaveSkillPlan("base", []byte("CPU Management 5\n"), model.PlanSourceUser))
+	assert.Equal(t, 5, store.GetSkillPlans()["doctrine"].Skills["CPU Management"].Level)
+
+	err := store.SaveSkillPlan("base", []byte("# include: doctrine\n"), model.PlanSourceUser)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "base -> doctrine -> base")
+	assert.Equal(t, 5, store.GetSkillPlans()["base"].Skills["CPU Management"].Level, "rejected plan is not applied")
+
+	// Includes survive a reload from disk.
+	require.NoError(t, store.LoadSkillPlans())
+	assert.Equal(t, []model.Skill{
+		{Name: "CPU Management", Level: 5},
+		{Name: "Logistics Cruisers", Level: 4},
+	}, store.GetSkillPlans()["doctrine"].Steps)
+}
+
 func TestSkillStore_LoadSkillTypes(t *testing.T) {
 	logger := &testutil.MockLogger{}
 	fs := persist.OSFileSystem{}
@@ -175,3 +227,133 @@ func TestSkillStore_GetSkillTypeByID(t *testing.T) {
 	_, found = store.GetSkillTypeByID("999999")
 	assert.False(t, found, "ID 999999 should not be found in skill types")
 }
+
+func TestSkillStore_LoadSkillAttributes(t *testing.T) {
+	logger := &testutil.MockLogger{}
+	fs := persist.OSFileSystem{}
+	basePath := t.TempDir()
+
+	fuzzworksDir := filepath.Join(basePath, "config", "fuzzworks")
+	require.NoError(t, os.MkdirAll(fuzzworksDir, 0755))
+
+	// Heavy Assault Cruisers (16591) requires Spaceship Command 5 and Caldari Cruiser 5;
+	// the attribute value is stored in either column depending on the row.
+	csvContent := `typeID,attributeID,valueInt,valueFloat
+16591,182,None,3327.0
+16591,277,5,None
+16591,183,3334,None
+16591,278,None,5.0
+16591,275,None,6.0
+16591,180,165,None
+16591,181,None,166.0
+3334,182,3327,None
+3334,277,4,None
+9943,176,None,3.0
+9943,175,None,0.0
+11993,11,None,1250.0
+11993,48,420,None
+2048,50,None,1.0`
+	require.NoError(t, os.WriteFile(filepath.Join(fuzzworksDir, "dgmTypeAttributes.csv"), []byte(csvContent), 0644))
+
+	store := eve.NewSkillStore(logger, fs, basePath)
+	_, loaded := store.IsShipType(11993)
+	assert.False(t, loaded)
+	require.NoError(t, store.LoadSkillAttributes())
+
+	assert.Equal(t, []model.SkillPrerequisite{
+		{SkillID: 3327, Level: 5},
+		{SkillID: 3334, Level: 5},
+	}, store.GetSkillPrerequisites(16591))
+	assert.Equal(t, []model.SkillPrerequisite{{SkillID: 3327, Level: 4}}, store.GetSkillPrerequisites(3334))
+	assert.Empty(t, store.GetSkillPrerequisites(3327))
+
+	attrs, found := store.GetSkillAttributes(16591)
+	assert.True(t, found)
+	assert.Equal(t, model.SkillAttributes{Rank: 6, PrimaryAttribute: 165, SecondaryAttribute: 166}, attrs)
+	_, found = store.GetSkillAttributes(3334)
+	assert.False(t, found, "types without a rank are not skills")
+
+	// Only the Cerberus provides powergrid and CPU; the Damage Control uses CPU.
+	ship, loaded := store.IsShipType(11993)
+	assert.True(t, loaded)
+	assert.True(t, ship)
+	ship, _ = store.IsShipType(2048)
+	assert.False(t, ship)
+
+	// Cybernetic Subprocessor - Basic grants +3 intelligence.
+	bonus, found := store.GetAttributeBonus(9943)
+	assert.True(t, found)
+	assert.Equal(t, model.CharacterAttributes{Intelligence: 3}, bonus)
+	_, found = store.GetAttributeBonus(16591)
+	assert.False(t, found)
+}
+
+func TestSkillStore_LoadAlphaSkillCaps(t *testing.T) {
+	logger := &testutil.MockLogger{}
+	fs := persist.OSFileSystem{}
+	basePath := t.TempDir()
+
+	store := eve.NewSkillStore(logger, fs, basePath)
+	assert.Error(t, store.LoadAlphaSkillCaps(), "clone grades are not downloaded yet")
+	_, known := store.GetAlphaSkillCap(3327)
+	assert.False(t, known)
+
+	fuzzworksDir := filepath.Join(basePath, "config", "fuzzworks")
+	require.NoError(t, os.MkdirAll(fuzzworksDir, 0755))
+	grades := `{
+  "1": {"name": "Alpha Caldari", "skills": [{"typeID": 3327, "level": 4}, {"typeID": 3334, "level": 4}]},
+  "2": {"name": "Alpha Gallente", "skills": [{"typeID": 3327, "level": 4}, {"typeID": 3332, "level": 4}, {"typeID": 3334, "level": 2}]}
+}`
+	require.NoError(t, os.WriteFile(filepath.Join(fuzzworksDir, "cloneGrades.json"), []byte(grades), 0644))
+
+	require.NoError(t, store.LoadAlphaSkillCaps())
+	level, known := store.GetAlphaSkillCap(3334)
+	assert.True(t, known)
+	assert.Equal(t, int32(4), level, "the highest cap of any grade applies")
+	level, known = store.GetAlphaSkillCap(16591)
+	assert.True(t, known)
+	assert.Equal(t, int32(0), level, "skills missing from every grade are Omega-only")
+}
+
+func TestSkillStore_PlanHistory(t *testing.T) {
+	logger := &testutil.MockLogger{}
+	fs := persist.OSFileSystem{}
+	basePath := t.TempDir()
+
+	// A plan already on disk before history was kept.
+	plansPath := filepath.Join(basePath, "plans")
+	require.NoError(t, os.MkdirAll(plansPath, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(plansPath, "doctrine.txt"), []byte("Gunnery 3\n"), 0644))
+
+	store := eve.NewSkillStore(logger, fs, basePath)
+	require.NoError(t, store.LoadSkillPlans())
+
+	require.NoError(t, store.SaveSkillPlan("doctrine", []byte("Gunnery 4\n"), model.PlanSourceUser))
+	require.NoError(t, store.SaveSkillPlan("doctrine", []byte("Gunnery 4\n"), model.PlanSourceUser))
+	require.NoError(t, store.SaveSkillPlan("doctrine", []byte("Gunnery 5\n"), model.PlanSourceCopy))
+
+	versions, err := store.GetSkillPlanHistory("doctrine")
+	require.NoError(t, err)
+	require.Len(t, versions, 3, "unchanged content is not recorded twice")
+	assert.Equal(t, model.PlanSourceCopy, versions[0].Source)
+	assert.Equal(t, model.PlanSourceUser, versions[1].Source)
+	assert.Equal(t, model.PlanSourceLocal, versions[2].Source)
+	assert.True(t, versions[0].Timestamp.After(versions[1].Timestamp))
+
+	content, err := store.GetSkillPlanVersion("doctrine", versions[2].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Gunnery 3\n", string(content))
+
+	_, err = store.GetSkillPlanVersion("doctrine", "1")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	// History outlives the plan.
+	require.NoError(t, store.DeleteSkillPlan("doctrine"))
+	versions, err = store.GetSkillPlanHistory("doctrine")
+	require.NoError(t, err)
+	assert.Len(t, versions, 3)
+
+	versions, err = store.GetSkillPlanHistory("unknown")
+	require.NoError(t, err)
+	assert.Empty(t, versions)
+}
@@ -0,0 +1,301 @@
+package eve
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/guarzo/canifly/internal/model"
+)
+
+// requiredSkillSlots pairs the dogma attribute holding each required skill type
+// ID with the attribute holding its level (requiredSkill1..6).
+var requiredSkillSlots = []struct {
+	skill int32
+	level int32
+}{
+	{182, 277},
+	{183, 278},
+	{184, 279},
+	{1285, 1286},
+	{1289, 1287},
+	{1290, 1288},
+}
+
+// Dogma attribute IDs describing how a skill trains.
+const (
+	attrPrimaryAttribute   int32 = 180
+	attrSecondaryAttribute int32 = 181
+	attrSkillTimeConstant  int32 = 275 // skill rank
+)
+
+// Dogma attribute IDs of the fitting resources a ship provides; modules use
+// others for what they need.
+const (
+	attrPowerOutput int32 = 11
+	attrCPUOutput   int32 = 48
+)
+
+// Dogma attribute IDs of the attribute bonuses granted by implants and
+// cerebral accelerators.
+const (
+	attrCharismaBonus     int32 = 175
+	attrIntelligenceBonus int32 = 176
+	attrMemoryBonus       int32 = 177
+	attrPerceptionBonus   int32 = 178
+	attrWillpowerBonus    int32 = 179
+)
+
+// wantedTypeAttributes is the set of dogma attributes kept in memory; the full
+// dgmTypeAttributes dump is far too large to hold on to.
+var wantedTypeAttributes = func() map[int32]bool {
+	wanted := map[int32]bool{
+		attrPrimaryAttribute:   true,
+		attrSecondaryAttribute: true,
+		attrSkillTimeConstant:  true,
+		attrCharismaBonus:      true,
+		attrIntelligenceBonus:  true,
+		attrMemoryBonus:        true,
+		attrPerceptionBonus:    true,
+		attrWillpowerBonus:     true,
+		attrPowerOutput:        true,
+		attrCPUOutput:          true,
+	}
+	for _, slot := range requiredSkillSlots {
+		wanted[slot.skill] = true
+		wanted[slot.level] = true
+	}
+	return wanted
+}()
+
+// LoadSkillAttributes loads the required skill graph, skill training
+// attributes, implant attribute bonuses and which types are ships from the
+// Fuzzworks dgmTypeAttributes dump.
+func (s *SkillStore) LoadSkillAttributes() error {
+	s.logger.Infof("load skill attributes")
+
+	fuzzworksPath := filepath.Join(s.basePath, "config", "fuzzworks", "dgmTypeAttributes.csv")
+	file, err := s.fs.Open(fuzzworksPath)
+	if err != nil {
+		return fmt.Errorf("type attributes not found - ensure Fuzzworks data is downloaded: %w", err)
+	}
+	defer file.Close()
+
+	s.logger.Infof("Loading type attributes from Fuzzworks data: %s", fuzzworksPath)
+
+	attributes, err := s.parseTypeAttributes(file)
+	if err != nil {
+		return fmt.Errorf("failed to parse Fuzzworks type attributes: %w", err)
+	}
+
+	prerequisites := buildPrerequisites(attributes)
+	skillAttributes := buildSkillAttributes(attributes)
+	attributeBonuses := buildAttributeBonuses(attributes)
+	shipTypes := buildShipTypes(attributes)
+
+	s.mut.Lock()
+	s.prerequisites = prerequisites
+	s.skillAttributes = skillAttributes
+	s.attributeBonuses = attributeBonuses
+	s.shipTypes = shipTypes
+	s.mut.Unlock()
+
+	s.logger.Debugf("Loaded prerequisites for %d types, training attributes for %d skills and bonuses for %d implants from Fuzzworks data",
+		len(prerequisites), len(skillAttributes), len(attributeBonuses))
+	return nil
+}
+
+// parseTypeAttributes streams the dgmTypeAttributes CSV and returns the wanted
+// attribute values keyed by type ID, then attribute ID.
+func (s *SkillStore) parseTypeAttributes(r io.Reader) (map[int32]map[int32]float64, error) {
+	reader := csv.NewReader(r)
+	reader.ReuseRecord = true
+
+	headers, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	colIndices := map[string]int{"typeID": -1, "attributeID": -1, "valueInt": -1, "valueFloat": -1}
+	for i, header := range headers {
+		if _, ok := colIndices[strings.TrimSpace(header)]; ok {
+			colIndices[strings.TrimSpace(header)] = i
+		}
+	}
+	if colIndices["typeID"] == -1 || colIndices["attributeID"] == -1 {
+		return nil, fmt.Errorf("required columns (typeID, attributeID) are missing")
+	}
+
+	attributes := make(map[int32]map[int32]float64)
+	lineNumber := 1
+	for {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		lineNumber++
+		if err != nil {
+			s.logger.Warnf("Skipping malformed row %d in type attributes: %v", lineNumber, err)
+			continue
+		}
+
+		attributeID, err := parseCsvInt32(row, colIndices["attributeID"])
+		if err != nil || !wantedTypeAttributes[attributeID] {
+			continue
+		}
+		typeID, err := parseCsvInt32(row, colIndices["typeID"])
+		if err != nil {
+			continue
+		}
+		value, ok := attributeValue(row, colIndices["valueInt"], colIndices["valueFloat"])
+		if !ok {
+			continue
+		}
+
+		if attributes[typeID] == nil {
+			attributes[typeID] = make(map[int32]float64)
+		}
+		attributes[typeID][attributeID] = value
+	}
+
+	return attributes, nil
+}
+
+// buildPrerequisites turns raw requiredSkillN attributes into the direct
+// prerequisite list for every type that has one.
+func buildPrerequisites(attributes map[int32]map[int32]float64) map[int32][]model.SkillPrerequisite {
+	prerequisites := make(map[int32][]model.SkillPrerequisite)
+	for typeID, values := range attributes {
+		for _, slot := range requiredSkillSlots {
+			skillID, ok := values[slot.skill]
+			if !ok || skillID <= 0 {
+				continue
+			}
+			level := int32(values[slot.level])
+			if level < 1 {
+				level = 1
+			}
+			prerequisites[typeID] = append(prerequisites[typeID], model.SkillPrerequisite{
+				SkillID: int32(skillID),
+				Level:   level,
+			})
+		}
+	}
+	return prerequisites
+}
+
+// buildSkillAttributes extracts rank and primary/secondary attributes for every
+// type that has a skill rank, i.e. every skill.
+func buildSkillAttributes(attributes map[int32]map[int32]float64) map[int32]model.SkillAttributes {
+	skillAttributes := make(map[int32]model.SkillAttributes)
+	for typeID, values := range attributes {
+		rank, ok := values[attrSkillTimeConstant]
+		if !ok || rank <= 0 {
+			continue
+		}
+		skillAttributes[typeID] = model.SkillAttributes{
+			Rank:               int32(rank),
+			PrimaryAttribute:   int32(values[attrPrimaryAttribute]),
+			SecondaryAttribute: int32(values[attrSecondaryAttribute]),
+		}
+	}
+	return skillAttributes
+}
+
+// buildAttributeBonuses extracts the character attribute bonuses of every type
+// that grants one, i.e. attribute implants and cerebral accelerators.
+func buildAttributeBonuses(attributes map[int32]map[int32]float64) map[int32]model.CharacterAttributes {
+	bonuses := make(map[int32]model.CharacterAttributes)
+	for typeID, values := range attributes {
+		bonus := model.CharacterAttributes{
+			Charisma:     int32(values[attrCharismaBonus]),
+			Intelligence: int32(values[attrIntelligenceBonus]),
+			Memory:       int32(values[attrMemoryBonus]),
+			Perception:   int32(values[attrPerceptionBonus]),
+			Willpower:    int32(values[attrWillpowerBonus]),
+		}
+		if bonus.Charisma > 0 || bonus.Intelligence > 0 || bonus.Memory > 0 || bonus.Perception > 0 || bonus.Willpower > 0 {
+			bonuses[typeID] = bonus
+		}
+	}
+	return bonuses
+}
+
+// buildShipTypes lists the types that provide both powergrid and CPU, i.e.
+// ships.
+func buildShipTypes(attributes map[int32]map[int32]float64) map[int32]bool {
+	ships := make(map[int32]bool)
+	for typeID, values := range attributes {
+		if values[attrPowerOutput] > 0 && values[attrCPUOutput] > 0 {
+			ships[typeID] = true
+		}
+	}
+	return ships
+}
+
+// IsShipType reports whether a type is a ship. ok is false when the type
+// attributes are not loaded.
+func (s *SkillStore) IsShipType(typeID int32) (ship bool, ok bool) {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+	if s.shipTypes == nil {
+		return false, false
+	}
+	return s.shipTypes[typeID], true
+}
+
+// GetAttributeBonus returns the attribute bonuses granted by an implant or
+// booster type.
+func (s *SkillStore) GetAttributeBonus(typeID int32) (model.CharacterAttributes, bool) {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+	bonus, ok := s.attributeBonuses[typeID]
+	return bonus, ok
+}
+
+// GetSkillAttributes returns the rank and training attributes of a skill.
+func (s *SkillStore) GetSkillAttributes(typeID int32) (model.SkillAttributes, bool) {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+	attrs, ok := s.skillAttributes[typeID]
+	return attrs, ok
+}
+
+// GetSkillPrerequisites returns the direct skill requirements of a type.
+func (s *SkillStore) GetSkillPrerequisites(typeID int32) []model.SkillPrerequisite {
+	s.mut.RLock()
+	defer s.mut.RUnlock()
+	prereqs := s.prerequisites[typeID]
+	cpy := make([]model.SkillPrerequisite, len(prereqs))
+	copy(cpy, prereqs)
+	return cpy
+}
+
+func parseCsvInt32(row []string, idx int) (int32, error) {
+	if idx < 0 || idx >= len(row) {
+		return 0, fmt.Errorf("column %d out of range", idx)
+	}
+	v, err := strconv.ParseInt(strings.TrimSpace(row[idx]), 10, 32)
+	return int32(v), err
+}
+
+// attributeValue reads a dogma value, which Fuzzworks stores in either the
+// valueInt or valueFloat column with the other set to "None" or empty.
+func attributeValue(row []string, intIdx, floatIdx int) (float64, bool) {
+	for _, idx := range []int{floatIdx, intIdx} {
+		if idx < 0 || idx >= len(row) {
+			continue
+		}
+		raw := strings.TrimSpace(row[idx])
+		if raw == "" || raw == "None" {
+			continue
+		}
+		if v, err := strconv.ParseFloat(raw, 64); err == nil {
+			return v, true
+		}
+	}
+	return 0, false
+}
@@ -32,8 +32,13 @@ func SetupHandlers(secret string, logger interfaces.Logger, appServices *AppServ
 
 	authHandler := flyHandlers.NewAuthHandler(sessionStore, appServices.ESIAPIService, logger, appServices.AccountManagementService, appServices.ConfigurationService, appServices.LoginService, appServices.AuthClient, appServices.HTTPCacheService, appServices.WebSocketHub, appServices.CharacterService, persistentSessionStore)
 	accountHandler := flyHandlers.NewAccountHandler(sessionStore, logger, appServices.AccountManagementService, appServices.HTTPCacheService, appServices.WebSocketHub)
-	characterHandler := flyHandlers.NewCharacterHandler(logger, appServices.CharacterService, appServices.ESIAPIService, appServices.HTTPCacheService)
+	characterHandler := flyHandlers.NewCharacterHandler(logger, appServices.CharacterService, appServices.ESIAPIService, appServices.HTTPCacheService, appServices.WebSocketHub)
 	skillPlanHandler := flyHandlers.NewSkillPlanHandler(logger, appServices.SkillPlanService, appServices.AccountManagementService, appServices.HTTPCacheService, appServices.WebSocketHub)
+	planningHandler := flyHandlers.NewPlanningHandler(logger, appServices.PlanningService, appServices.AccountManagementService)
+	injectorHandler := flyHandlers.NewInjectorHandler(logger, appServices.InjectorService, appServices.AccountManagementService)
+	farmHandler := flyHandlers.NewFarmHandler(logger, appServices.FarmService)
+	cloneHandler := flyHandlers.NewCloneHandler(logger, appServices.CloneService)
+	diagnosticsHandler := flyHandlers.NewDiagnosticsHandler(logger, appServices.ESIGovernor)
 	configHandler := flyHandlers.NewConfigHandler(logger, appServices.ConfigurationService, appServices.HTTPCacheService)
 	eveDataHandler := flyHandlers.NewEveDataHandler(logger, appServices.SyncService, appServices.ConfigurationService, appServices.SkillPlanService, appServices.ProfileService, appServices.AccountManagementService, appServices.HTTPCacheService)
 	assocHandler := flyHandlers.NewAssociationHandler(logger, appServices.AccountManagementService)
@@ -62,10 +67,21 @@ func SetupHandlers(secret string, logger interfaces.Logger, appServices *AppServ
 	r.HandleFunc("/api/skill-plans", skillPlanHandler.ListSkillPlans()).Methods("GET")
 	r.HandleFunc("/api/skill-plans", skillPlanHandler.CreateSkillPlan()).Methods("POST")
 	r.HandleFunc("/api/skill-plans/refresh", skillPlanHandler.RefreshSkillPlans()).Methods("POST")
+	r.HandleFunc("/api/skill-plans/import", skillPlanHandler.ImportSkillPlan()).Methods("POST")
+	r.HandleFunc("/api/skill-plans/validate", skillPlanHandler.ValidateSkillPlans()).Methods("GET")
+	r.HandleFunc("/api/fittings/plan", skillPlanHandler.CreateFittingPlan()).Methods("POST")
+	r.HandleFunc("/api/fittings/evaluate", skillPlanHandler.EvaluateFitting()).Methods("POST")
+	r.HandleFunc("/api/ships/{typeName}/status", skillPlanHandler.ShipStatus()).Methods("GET")
 	r.HandleFunc("/api/skill-plans/{name}", skillPlanHandler.GetSkillPlan()).Methods("GET")
 	r.HandleFunc("/api/skill-plans/{name}", skillPlanHandler.UpdateSkillPlan()).Methods("PUT")
 	r.HandleFunc("/api/skill-plans/{name}", skillPlanHandler.DeleteSkillPlanRESTful()).Methods("DELETE")
 	r.HandleFunc("/api/skill-plans/{name}/copy", skillPlanHandler.CopySkillPlan()).Methods("POST")
+	r.HandleFunc("/api/skill-plans/{name}/expanded", skillPlanHandler.GetExpandedSkillPlan()).Methods("GET")
+	r.HandleFunc("/api/skill-plans/{name}/export", skillPlanHandler.ExportSkillPlan()).Methods("GET")
+	r.HandleFunc("/api/skill-plans/{name}/history", skillPlanHandler.SkillPlanHistory()).Methods("GET")
+	r.HandleFunc("/api/skill-plans/{name}/diff", skillPlanHandler.DiffSkillPlan()).Methods("GET")
+	r.HandleFunc("/api/skill-plans/{name}/restore", skillPlanHandler.RestoreSkillPlan()).Methods("POST")
+	r.HandleFunc("/api/skill-plans/{name}/injection-candidates", injectorHandler.RankInjectionCandidates()).Methods("GET")
 
 	// RESTful account endpoints
 	r.HandleFunc("/api/accounts", accountHandler.ListAccounts()).Methods("GET")
@@ -74,10 +90,25 @@ func SetupHandlers(secret string, logger interfaces.Logger, appServices *AppServ
 	r.HandleFunc("/api/accounts/{id}", accountHandler.DeleteAccount()).Methods("DELETE")
 
 	// RESTful character endpoints
+	r.HandleFunc("/api/characters/refresh-all", characterHandler.RefreshAllCharacters()).Methods("POST")
 	r.HandleFunc("/api/characters/{id}", characterHandler.GetCharacter()).Methods("GET")
 	r.HandleFunc("/api/characters/{id}", characterHandler.UpdateCharacterRESTful()).Methods("PATCH")
 	r.HandleFunc("/api/characters/{id}", characterHandler.DeleteCharacter()).Methods("DELETE")
 	r.HandleFunc("/api/characters/{id}/refresh", characterHandler.RefreshCharacter()).Methods("POST")
+	r.HandleFunc("/api/characters/{id}/remap-advice", skillPlanHandler.RemapAdvice()).Methods("POST")
+	r.HandleFunc("/api/characters/{id}/training-scenario", skillPlanHandler.TrainingScenario()).Methods("POST")
+	r.HandleFunc("/api/characters/{id}/next-skills", skillPlanHandler.NextSkills()).Methods("POST")
+	r.HandleFunc("/api/characters/{id}/plans/{name}/queue-export", skillPlanHandler.ExportSkillQueue()).Methods("GET")
+	r.HandleFunc("/api/characters/{id}/plans/{name}/injectors", injectorHandler.EstimateInjectors()).Methods("GET")
+
+	// Training planning endpoints
+	r.HandleFunc("/api/planning/assign", planningHandler.AssignTraining()).Methods("POST")
+
+	// Skill farm endpoints
+	r.HandleFunc("/api/farms", farmHandler.GetFarmSummary()).Methods("GET")
+
+	// Jump clone endpoints
+	r.HandleFunc("/api/clones", cloneHandler.GetCloneInventory()).Methods("GET")
 
 	// RESTful config endpoints
 	r.HandleFunc("/api/config", configHandler.GetConfig()).Methods("GET")
@@ -102,6 +133,9 @@ func SetupHandlers(secret string, logger interfaces.Logger, appServices *AppServ
 	// WebSocket endpoint
 	r.HandleFunc("/api/ws", appServices.WebSocketHub.HandleWebSocket)
 
+	// Diagnostics endpoints
+	r.HandleFunc("/api/diagnostics/esi", diagnosticsHandler.GetESIStatus()).Methods("GET")
+
 	// Fuzzworks endpoints
 	r.HandleFunc("/api/fuzzworks/update", fuzzworksHandler.UpdateData()).Methods("POST")
 	r.HandleFunc("/api/fuzzworks/status", fuzzworksHandler.GetStatus()).Methods("GET")
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"path/filepath"
 	"time"
 
 	"github.com/guarzo/canifly/internal/handlers"
@@ -16,11 +15,16 @@ import (
 	accountSvc "github.com/guarzo/canifly/internal/services/account"
 	cacheSvc "github.com/guarzo/canifly/internal/services/cache"
 	characterSvc "github.com/guarzo/canifly/internal/services/character"
+	cloneSvc "github.com/guarzo/canifly/internal/services/clone"
 	configSvc "github.com/guarzo/canifly/internal/services/config"
 	eveSvc "github.com/guarzo/canifly/internal/services/eve"
+	farmSvc "github.com/guarzo/canifly/internal/services/farm"
 	"github.com/guarzo/canifly/internal/services/fuzzworks"
+	injectorSvc "github.com/guarzo/canifly/internal/services/injector"
 	"github.com/guarzo/canifly/internal/services/interfaces"
+	planningSvc "github.com/guarzo/canifly/internal/services/planning"
 	profileSvc "github.com/guarzo/canifly/internal/services/profile"
+	schedulerSvc "github.com/guarzo/canifly/internal/services/scheduler"
 	skillplanSvc "github.com/guarzo/canifly/internal/services/skillplan"
 	"github.com/guarzo/canifly/internal/services/skillplans"
 	"github.com/guarzo/canifly/internal/services/storage"
@@ -35,8 +39,14 @@ type AppServices struct {
 
 	// Split EVE Services
 	ESIAPIService    interfaces.ESIAPIService
+	ESIGovernor      interfaces.ESIGovernor
 	CharacterService interfaces.CharacterService
 	SkillPlanService interfaces.SkillPlanService
+	PlanningService  interfaces.PlanningService
+	InjectorService  interfaces.InjectorService
+	FarmService      interfaces.FarmService
+	CloneService     interfaces.CloneService
+	Scheduler        interfaces.SchedulerService
 	ProfileService   interfaces.ProfileService
 	CacheableService interfaces.CacheableService
 
@@ -62,6 +72,7 @@ type AppServices struct {
 //	OPTIONAL — failure is logged and startup continues:
 //	  * EVE credentials (user can set via UI)
 //	  * Fuzzworks refresh (cached data is used until ready; first-run download is required)
+//	  * Skill attributes (plans are evaluated without prerequisites)
 //	  * Persistent cache load (rebuilt on demand)
 //	  * Settings directory creation (best-effort; recreated on demand)
 func GetServices(logger interfaces.Logger, cfg Config) (*AppServices, error) {
@@ -107,15 +118,27 @@ func GetServices(logger interfaces.Logger, cfg Config) (*AppServices, error) {
 
 	// Fuzzworks initial download.
 	//
-	// First-run policy: if the canonical data file (invTypes.csv) is missing,
-	// block synchronously so the REQUIRED skill repo load below can succeed.
+	// First-run policy: if the canonical data file (invTypes.csv) is missing, block
+	// synchronously so the REQUIRED skill repo load below can succeed.
 	// Subsequent runs: run async — the existing cached data is used immediately
 	// and a refresh broadcasts progress as fuzzworks:status events
 	// {state: updating|ready|error, error?: string}.
+	var skillStoreOpts []func(*eve.SkillStore)
+	if cfg.SkillPlansRepoURL != "" {
+		githubDownloader := skillplans.NewGitHubDownloader(cfg.SkillPlansRepoURL, logger)
+		skillStoreOpts = append(skillStoreOpts, eve.WithGitHubDownloader(githubDownloader))
+	}
+	skillRepo := eve.NewSkillStore(logger, persist.OSFileSystem{}, cfg.BasePath, skillStoreOpts...)
+
 	if autoUpdate {
 		fuzzworksService := fuzzworks.New(logger, cfg.BasePath, false)
-		invTypesPath := filepath.Join(cfg.BasePath, "config", "fuzzworks", "invTypes.csv")
-		_, statErr := os.Stat(invTypesPath)
+		requiredPath := fuzzworksService.GetInvTypesPath()
+		_, statErr := os.Stat(requiredPath)
+		// Installs from before prerequisite support have invTypes but no
+		// attributes yet; those are OPTIONAL, so they're fetched by the
+		// background refresh, which reloads them once it completes.
+		_, attrErr := os.Stat(fuzzworksService.GetTypeAttributesPath())
+		attributesMissing := os.IsNotExist(attrErr)
 		switch {
 		case os.IsNotExist(statErr):
 			// First run — no cached data; block until download completes.
@@ -130,19 +153,31 @@ func GetServices(logger interfaces.Logger, cfg Config) (*AppServices, error) {
 			// Stat failed for a reason other than "not found" (e.g. permission denied).
 			// Don't silently fall through to the async refresh path — the file may be
 			// unreadable and the skill repo load below would fail without a useful message.
-			return nil, fmt.Errorf("failed to stat fuzzworks data %s: %w", invTypesPath, statErr)
+			return nil, fmt.Errorf("failed to stat fuzzworks data %s: %w", requiredPath, statErr)
 		default:
 			// File exists — refresh in background.
 			logger.Infof("Fuzzworks auto-update enabled — running refresh in background")
+			if attributesMissing {
+				logger.Warnf("Fuzzworks skill attributes missing — downloading in background; plans are evaluated without prerequisites until then")
+			}
 			go func() {
 				ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
 				defer cancel()
 				webSocketHub.BroadcastUpdate("fuzzworks:status", map[string]string{"state": "updating"})
 				if err := fuzzworksService.Initialize(ctx); err != nil {
 					logger.Errorf("Fuzzworks update failed: %v", err)
+					if attributesMissing {
+						logger.Warnf("skill attributes still unavailable: %v", err)
+					}
 					webSocketHub.BroadcastUpdate("fuzzworks:status", map[string]string{"state": "error", "error": err.Error()})
 					return
 				}
+				if err := skillRepo.LoadSkillAttributes(); err != nil {
+					logger.Warnf("failed to load downloaded skill attributes: %v", err)
+				}
+				if err := skillRepo.LoadAlphaSkillCaps(); err != nil {
+					logger.Warnf("failed to load downloaded alpha skill caps: %v", err)
+				}
 				webSocketHub.BroadcastUpdate("fuzzworks:status", map[string]string{"state": "ready"})
 			}()
 		}
@@ -158,18 +193,20 @@ func GetServices(logger interfaces.Logger, cfg Config) (*AppServices, error) {
 	authClient := initAuthClient(logger, cfg, configurationService)
 
 	// REQUIRED: skill repo — skill plans + types must load for the app to function.
-	var skillStoreOpts []func(*eve.SkillStore)
-	if cfg.SkillPlansRepoURL != "" {
-		githubDownloader := skillplans.NewGitHubDownloader(cfg.SkillPlansRepoURL, logger)
-		skillStoreOpts = append(skillStoreOpts, eve.WithGitHubDownloader(githubDownloader))
-	}
-	skillRepo := eve.NewSkillStore(logger, persist.OSFileSystem{}, cfg.BasePath, skillStoreOpts...)
 	if err := skillRepo.LoadSkillPlans(); err != nil {
 		return nil, fmt.Errorf("failed to load skill plans: %v", err)
 	}
 	if err := skillRepo.LoadSkillTypes(); err != nil {
 		return nil, fmt.Errorf("failed to load skill types: %v", err)
 	}
+	// OPTIONAL: skill attributes — without them plans are evaluated without prerequisites.
+	if err := skillRepo.LoadSkillAttributes(); err != nil {
+		logger.Warnf("failed to load skill attributes: %v", err)
+	}
+	// OPTIONAL: Alpha clone skill caps — without them Alpha accounts are evaluated uncapped.
+	if err := skillRepo.LoadAlphaSkillCaps(); err != nil {
+		logger.Warnf("failed to load alpha skill caps: %v", err)
+	}
 	// REQUIRED: system repo
 	systemRepo := eve.NewSystemStore(logger, cfg.BasePath)
 	if err := systemRepo.LoadSystems(); err != nil {
@@ -219,6 +256,23 @@ func GetServices(logger interfaces.Logger, cfg Config) (*AppServices, error) {
 
 	// Create skill plan service (narrow deps: just skillRepo + logger)
 	skillPlanService := skillplanSvc.NewService(logger, skillRepo)
+	skillPlanService.LogPlanDiagnostics()
+
+	// Planning builds on skill plan training queues.
+	planningService := planningSvc.NewService(logger, skillPlanService)
+	injectorService := injectorSvc.NewService(logger, skillPlanService, configurationService)
+
+	// Farm monitoring alerts over the WebSocket hub from stored character data;
+	// started by cmd.Start once the server is up.
+	farmService := farmSvc.NewService(logger, accountManagementService, webSocketHub)
+
+	// Clone inventory from stored character data, with implant names from invTypes.
+	cloneService := cloneSvc.NewService(logger, accountManagementService, skillRepo)
+
+	// Background ESI refreshes; started by cmd.Start once the server is up.
+	scheduler := schedulerSvc.NewService(logger,
+		schedulerSvc.NewCharacterRefreshTasks(logger, accountManagementService, characterService, httpCacheService, webSocketHub)...,
+	)
 
 	// Profile service consumes the ESI client directly.
 	profileService := profileSvc.NewService(
@@ -246,8 +300,14 @@ func GetServices(logger interfaces.Logger, cfg Config) (*AppServices, error) {
 
 		// Split EVE Services
 		ESIAPIService:    esiClient,
+		ESIGovernor:      httpClient.Governor,
 		CharacterService: characterService,
 		SkillPlanService: skillPlanService,
+		PlanningService:  planningService,
+		InjectorService:  injectorService,
+		FarmService:      farmService,
+		CloneService:     cloneService,
+		Scheduler:        scheduler,
 		ProfileService:   profileService,
 		CacheableService: persistentCache,
 
@@ -1,6 +1,7 @@
 package account
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"sync"
@@ -283,7 +284,7 @@ func (s *AccountManagementService) RefreshAccountData() (*model.AccountData, err
 			}
 
 			// Update character data
-			updatedChar, err := s.userInfoFetcher.GetUserInfo(&char.Token)
+			updatedChar, err := s.userInfoFetcher.GetUserInfo(context.Background(), &char.Token)
 			if err != nil {
 				s.logger.Errorf("Failed to get user info for character %d: %v", char.Character.CharacterID, err)
 				continue
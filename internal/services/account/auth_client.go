@@ -48,6 +48,7 @@ func NewAuthClient(logger interfaces.Logger, clientID, clientSecret, callbackURL
 				"esi-skills.read_skills.v1",
 				"esi-clones.read_clones.v1",
 				"esi-clones.read_implants.v1",
+				"esi-universe.read_structures.v1",
 				"esi-skills.read_skillqueue.v1",
 				"esi-characters.read_corporation_roles.v1",
 			},
@@ -0,0 +1,281 @@
+package character
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"golang.org/x/oauth2"
+
+	flyErrors "github.com/guarzo/canifly/internal/errors"
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/services/interfaces"
+)
+
+const (
+	// DefaultRefreshConcurrency is how many characters RefreshAllCharacters
+	// refreshes at once when no concurrency is given.
+	DefaultRefreshConcurrency = 4
+	// MaxRefreshConcurrency caps the worker pool so a bulk refresh can't
+	// exhaust ESI's error budget on its own.
+	MaxRefreshConcurrency = 16
+)
+
+// refreshedCharacter is what a refresh changed about a character: its token,
+// if it was renewed, and the ESI data of the endpoints it fetched.
+type refreshedCharacter struct {
+	identity     model.CharacterIdentity
+	fetched      endpointSet
+	tokenRenewed bool
+	dataFetched  bool
+}
+
+// RefreshAllCharacters refreshes every character with a pool of concurrency
+// workers, 0 meaning DefaultRefreshConcurrency. Corporation and alliance
+// lookups are shared between characters, and the accounts and ESI cache are
+// saved once at the end. progress, if set, is called as each character is
+// queued, starts and finishes; it may be called from several goroutines.
+// Refreshed characters are merged into the accounts as they are at the end,
+// so changes made while the refresh runs are kept.
+func (s *Service) RefreshAllCharacters(ctx context.Context, concurrency int, progress func(model.CharacterRefreshProgress)) (*model.BulkRefreshResult, error) {
+	if concurrency == 0 {
+		concurrency = DefaultRefreshConcurrency
+	}
+	if concurrency < 1 || concurrency > MaxRefreshConcurrency {
+		return nil, flyErrors.NewCustomError(http.StatusBadRequest, "concurrency must be between 1 and 16")
+	}
+	if !s.refreshMu.TryLock() {
+		return nil, flyErrors.NewCustomError(http.StatusConflict, "a refresh of all characters is already running")
+	}
+	defer s.refreshMu.Unlock()
+
+	if progress == nil {
+		progress = func(model.CharacterRefreshProgress) {}
+	}
+
+	accounts, err := s.accountMgmt.FetchAccounts()
+	if err != nil {
+		return nil, err
+	}
+
+	var jobs []model.CharacterIdentity
+	for _, account := range accounts {
+		for _, charIdentity := range account.Characters {
+			jobs = append(jobs, charIdentity)
+			progress(refreshProgress(&charIdentity, model.RefreshQueued, nil))
+		}
+	}
+	s.logger.Infof("Refreshing %d characters with %d workers", len(jobs), concurrency)
+
+	result := &model.BulkRefreshResult{Total: len(jobs), Failures: []model.CharacterRefreshProgress{}}
+	affiliations := newAffiliationCache()
+	queue := make(chan model.CharacterIdentity)
+	refreshed := make([]refreshedCharacter, 0, len(jobs))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for charIdentity := range queue {
+				progress(refreshProgress(&charIdentity, model.RefreshRunning, nil))
+
+				character, err := s.refreshIdentity(ctx, charIdentity, affiliations, nil)
+
+				status := model.RefreshDone
+				if err != nil {
+					s.logger.Errorf("Failed to refresh character %s: %v", charIdentity.Character.CharacterName, err)
+					status = model.RefreshFailed
+				}
+				update := refreshProgress(&charIdentity, status, err)
+
+				mu.Lock()
+				if character.dataFetched || character.tokenRenewed {
+					refreshed = append(refreshed, character)
+				}
+				if err != nil {
+					result.Failed++
+					result.Failures = append(result.Failures, update)
+				} else {
+					result.Refreshed++
+				}
+				mu.Unlock()
+
+				progress(update)
+			}
+		}()
+	}
+	for _, job := range jobs {
+		queue <- job
+	}
+	close(queue)
+	wg.Wait()
+
+	if err := s.cache.SaveCache(); err != nil {
+		s.logger.WithError(err).Infof("failed to save esi cache after refreshing all characters")
+	}
+	if err := s.saveRefreshed(refreshed); err != nil {
+		return nil, flyErrors.NewCustomError(http.StatusInternalServerError, "failed to save accounts: "+err.Error())
+	}
+
+	s.logger.Infof("Refreshed %d of %d characters, %d failed", result.Refreshed, result.Total, result.Failed)
+	return result, nil
+}
+
+// refreshIdentity refreshes the given endpoints of a copy of charIdentity. A
+// renewed token is kept in the result even if fetching the character's data
+// then fails; once ctx is done, none of the data is.
+func (s *Service) refreshIdentity(ctx context.Context, charIdentity model.CharacterIdentity, affiliations *affiliationCache, endpoints endpointSet) (refreshedCharacter, error) {
+	refreshed := refreshedCharacter{identity: charIdentity}
+	if err := ctx.Err(); err != nil {
+		return refreshed, err
+	}
+	if err := s.ensureFreshToken(&refreshed.identity); err != nil {
+		return refreshed, err
+	}
+	refreshed.tokenRenewed = refreshed.identity.Token.AccessToken != charIdentity.Token.AccessToken
+
+	fetched, err := s.processIdentity(ctx, &refreshed.identity, affiliations, endpoints)
+	if err != nil {
+		return refreshed, fmt.Errorf("failed to update character: %w", err)
+	}
+	refreshed.fetched = fetched
+	refreshed.dataFetched = len(fetched) > 0
+	return refreshed, nil
+}
+
+// saveRefreshed merges refreshed characters into the accounts as they are
+// now, not as they were when the refresh began, so a role or farm update, a
+// new login or a removal made meanwhile is kept. Only what a refresh replaces
+// is copied over, so a refresh of some endpoints doesn't undo a concurrent
+// refresh of others, and an endpoint that failed to fetch keeps its data.
+func (s *Service) saveRefreshed(refreshed []refreshedCharacter) error {
+	byID := make(map[int64]refreshedCharacter, len(refreshed))
+	for _, r := range refreshed {
+		byID[r.identity.Character.CharacterID] = r
+	}
+
+	s.accountsMu.Lock()
+	defer s.accountsMu.Unlock()
+
+	accounts, err := s.accountMgmt.FetchAccounts()
+	if err != nil {
+		return err
+	}
+	for i := range accounts {
+		for j := range accounts[i].Characters {
+			current := &accounts[i].Characters[j]
+			r, ok := byID[current.Character.CharacterID]
+			if !ok {
+				continue
+			}
+			if r.tokenRenewed || r.dataFetched {
+				current.Token = r.identity.Token
+			}
+			if r.dataFetched {
+				mergeEndpoints(current, &r.identity, r.fetched)
+			}
+		}
+	}
+	return s.accountMgmt.SaveAccounts(accounts)
+}
+
+// mergeEndpoints copies the data of the given endpoints from src to dst.
+func mergeEndpoints(dst, src *model.CharacterIdentity, endpoints endpointSet) {
+	if endpoints[model.EndpointAffiliation] {
+		dst.Character.UserInfoResponse = src.Character.UserInfoResponse
+		dst.CorporationName = src.CorporationName
+		dst.AllianceName = src.AllianceName
+	}
+	if endpoints[model.EndpointSkills] {
+		dst.Character.CharacterSkillsResponse = src.Character.CharacterSkillsResponse
+	}
+	if endpoints[model.EndpointSkillQueue] {
+		dst.Character.SkillQueue = src.Character.SkillQueue
+		dst.MCT = src.MCT
+		dst.Training = src.Training
+	}
+	if endpoints[model.EndpointLocation] {
+		dst.Character.Location = src.Character.Location
+		dst.Character.LocationName = src.Character.LocationName
+	}
+	if endpoints[model.EndpointAttributes] {
+		dst.Character.Attributes = src.Character.Attributes
+	}
+	if endpoints[model.EndpointImplants] {
+		dst.Character.Implants = src.Character.Implants
+	}
+	if endpoints[model.EndpointClones] {
+		dst.Character.Clones = src.Character.Clones
+	}
+}
+
+func refreshProgress(charIdentity *model.CharacterIdentity, status string, err error) model.CharacterRefreshProgress {
+	update := model.CharacterRefreshProgress{
+		CharacterID:   charIdentity.Character.CharacterID,
+		CharacterName: charIdentity.Character.CharacterName,
+		Status:        status,
+	}
+	if err != nil {
+		update.Error = err.Error()
+	}
+	return update
+}
+
+// affiliationCache shares corporation and alliance lookups between the
+// characters of a bulk refresh, so each is fetched from ESI once however many
+// characters belong to it. A nil cache fetches every time.
+type affiliationCache struct {
+	mu           sync.Mutex
+	corporations map[int64]*lookup[model.Corporation]
+	alliances    map[int64]*lookup[model.Alliance]
+}
+
+// lookup is a single ESI fetch that callers wait on together.
+type lookup[T any] struct {
+	once  sync.Once
+	value *T
+	err   error
+}
+
+func newAffiliationCache() *affiliationCache {
+	return &affiliationCache{
+		corporations: make(map[int64]*lookup[model.Corporation]),
+		alliances:    make(map[int64]*lookup[model.Alliance]),
+	}
+}
+
+func (c *affiliationCache) corporation(ctx context.Context, esi interfaces.ESIAPIService, id int64, token *oauth2.Token) (*model.Corporation, error) {
+	if c == nil {
+		return esi.GetCorporation(ctx, id, token)
+	}
+	c.mu.Lock()
+	l := sharedLookup(c.corporations, id)
+	c.mu.Unlock()
+
+	l.once.Do(func() { l.value, l.err = esi.GetCorporation(ctx, id, token) })
+	return l.value, l.err
+}
+
+func (c *affiliationCache) alliance(ctx context.Context, esi interfaces.ESIAPIService, id int64, token *oauth2.Token) (*model.Alliance, error) {
+	if c == nil {
+		return esi.GetAlliance(ctx, id, token)
+	}
+	c.mu.Lock()
+	l := sharedLookup(c.alliances, id)
+	c.mu.Unlock()
+
+	l.once.Do(func() { l.value, l.err = esi.GetAlliance(ctx, id, token) })
+	return l.value, l.err
+}
+
+func sharedLookup[T any](lookups map[int64]*lookup[T], id int64) *lookup[T] {
+	l, ok := lookups[id]
+	if !ok {
+		l = &lookup[T]{}
+		lookups[id] = l
+	}
+	return l
+}
@@ -0,0 +1,260 @@
+package character
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+
+	flyErrors "github.com/guarzo/canifly/internal/errors"
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/testutil"
+)
+
+func refreshIdentity(id int64, name string) model.CharacterIdentity {
+	var c model.Character
+	c.CharacterID = id
+	c.CharacterName = name
+	return model.CharacterIdentity{
+		Character: c,
+		Token:     oauth2.Token{AccessToken: name, Expiry: time.Now().Add(time.Hour)},
+	}
+}
+
+func tokenFor(name string) interface{} {
+	return mock.MatchedBy(func(token *oauth2.Token) bool { return token.AccessToken == name })
+}
+
+func TestRefreshAllCharacters(t *testing.T) {
+	// Charlie's token has expired; it is renewed, but fetching its data fails.
+	charlie := refreshIdentity(3, "Charlie")
+	charlie.Token.Expiry = time.Now().Add(-time.Minute)
+	charlie.Token.RefreshToken = "refresh"
+	renewed := &oauth2.Token{AccessToken: "Charlie2", RefreshToken: "refresh2", Expiry: time.Now().Add(time.Hour)}
+
+	accounts := []model.Account{
+		{Name: "Main", Characters: []model.CharacterIdentity{refreshIdentity(1, "Alpha"), refreshIdentity(2, "Bravo")}},
+		{Name: "Alt", Characters: []model.CharacterIdentity{charlie}},
+	}
+	// While the refresh runs, Bravo is made a scout and Delta logs in.
+	scout := refreshIdentity(2, "Bravo")
+	scout.Role = "Scout"
+	current := []model.Account{
+		{Name: "Main", Characters: []model.CharacterIdentity{refreshIdentity(1, "Alpha"), scout}},
+		{Name: "Alt", Characters: []model.CharacterIdentity{charlie, refreshIdentity(4, "Delta")}},
+	}
+
+	accountMgmt := &testutil.MockAccountManagementService{}
+	accountMgmt.On("FetchAccounts").Return(accounts, nil).Once()
+	accountMgmt.On("FetchAccounts").Return(current, nil).Once()
+	authClient := &testutil.MockAuthClient{}
+	authClient.On("RefreshToken", "refresh").Return(renewed, nil).Once()
+	var saved []model.Account
+	accountMgmt.On("SaveAccounts", mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(0).([]model.Account)
+	}).Return(nil).Once()
+
+	esi := &testutil.MockESIService{}
+	for _, c := range []model.UserInfoResponse{{CharacterID: 1, CharacterName: "Alpha"}, {CharacterID: 2, CharacterName: "Bravo"}} {
+		esi.On("GetUserInfo", tokenFor(c.CharacterName)).Return(&c, nil).Once()
+	}
+	esi.On("GetUserInfo", tokenFor("Charlie2")).Return(nil, errors.New("invalid token")).Once()
+	esi.On("GetCharacter", mock.Anything).Return(&model.CharacterResponse{CorporationID: 100}, nil)
+	esi.On("GetCharacterSkills", mock.Anything, mock.Anything).Return(&model.CharacterSkillsResponse{TotalSP: 5_000_000}, nil)
+	esi.On("GetCharacterSkillQueue", mock.Anything, mock.Anything).Return(&[]model.SkillQueue{}, nil)
+	esi.On("GetCharacterLocation", mock.Anything, mock.Anything).Return(int64(30000142), nil)
+	esi.On("GetCharacterAttributes", mock.Anything, mock.Anything).Return(&model.CharacterAttributes{}, nil)
+	esi.On("GetCharacterImplants", mock.Anything, mock.Anything).Return([]int32{}, nil)
+	esi.On("GetCharacterClones", mock.Anything, mock.Anything).Return(&model.CloneLocation{}, nil)
+	// Alpha and Bravo share a corporation and alliance, fetched once between them.
+	esi.On("GetCorporation", int64(100), mock.Anything).Return(&model.Corporation{Name: "Corp", AllianceID: 200}, nil).Once()
+	esi.On("GetAlliance", int64(200), mock.Anything).Return(&model.Alliance{Name: "Alliance"}, nil).Once()
+
+	systemRepo := &testutil.MockSystemRepository{}
+	systemRepo.On("GetSystemName", int64(30000142)).Return("Jita")
+	cache := &testutil.MockCacheService{}
+	cache.On("SaveCache").Return(nil).Once()
+
+	svc := NewService(&testutil.MockLogger{}, nil, authClient, accountMgmt, nil, nil, nil, systemRepo, cache, esi)
+
+	var mu sync.Mutex
+	statuses := make(map[string][]string)
+	result, err := svc.RefreshAllCharacters(context.Background(), 2, func(update model.CharacterRefreshProgress) {
+		mu.Lock()
+		defer mu.Unlock()
+		statuses[update.CharacterName] = append(statuses[update.CharacterName], update.Status)
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, result.Total)
+	assert.Equal(t, 2, result.Refreshed)
+	assert.Equal(t, 1, result.Failed)
+	require.Len(t, result.Failures, 1)
+	assert.Equal(t, "Charlie", result.Failures[0].CharacterName)
+	assert.Contains(t, result.Failures[0].Error, "invalid token")
+
+	assert.Equal(t, []string{model.RefreshQueued, model.RefreshRunning, model.RefreshDone}, statuses["Alpha"])
+	assert.Equal(t, []string{model.RefreshQueued, model.RefreshRunning, model.RefreshDone}, statuses["Bravo"])
+	assert.Equal(t, []string{model.RefreshQueued, model.RefreshRunning, model.RefreshFailed}, statuses["Charlie"])
+
+	require.Len(t, saved, 2)
+	bravo := saved[0].Characters[1]
+	assert.Equal(t, "Corp", bravo.CorporationName)
+	assert.Equal(t, "Alliance", bravo.AllianceName)
+	assert.Equal(t, "Jita", bravo.Character.LocationName)
+	assert.Equal(t, int64(5_000_000), bravo.Character.TotalSP)
+	assert.Equal(t, "Scout", bravo.Role, "changes made during the refresh are kept")
+	// A failed character keeps its data but not its old token.
+	require.Len(t, saved[1].Characters, 2)
+	assert.Equal(t, charlie.Character, saved[1].Characters[0].Character)
+	assert.Equal(t, *renewed, saved[1].Characters[0].Token)
+	assert.Equal(t, refreshIdentity(4, "Delta").Character, saved[1].Characters[1].Character)
+
+	accountMgmt.AssertExpectations(t)
+	authClient.AssertExpectations(t)
+	esi.AssertExpectations(t)
+	cache.AssertExpectations(t)
+}
+
+func TestRefreshAllCharacters_Concurrency(t *testing.T) {
+	svc := NewService(&testutil.MockLogger{}, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	for _, concurrency := range []int{-1, MaxRefreshConcurrency + 1} {
+		_, err := svc.RefreshAllCharacters(context.Background(), concurrency, nil)
+		var customErr *flyErrors.CustomError
+		require.ErrorAs(t, err, &customErr)
+		assert.Equal(t, http.StatusBadRequest, customErr.StatusCode)
+	}
+
+	svc.refreshMu.Lock()
+	defer svc.refreshMu.Unlock()
+	_, err := svc.RefreshAllCharacters(context.Background(), 0, nil)
+	var customErr *flyErrors.CustomError
+	require.ErrorAs(t, err, &customErr)
+	assert.Equal(t, http.StatusConflict, customErr.StatusCode)
+}
+
+func TestRefreshCharacterEndpoints(t *testing.T) {
+	alpha := refreshIdentity(1, "Alpha")
+	alpha.Character.TotalSP = 1_000_000
+	alpha.Character.Location = 30002187
+	// Meanwhile a skills refresh has saved Alpha's new SP.
+	current := alpha
+	current.Character.TotalSP = 1_250_000
+
+	accountMgmt := &testutil.MockAccountManagementService{}
+	accountMgmt.On("FetchAccounts").Return([]model.Account{{Characters: []model.CharacterIdentity{alpha}}}, nil).Once()
+	accountMgmt.On("FetchAccounts").Return([]model.Account{{Characters: []model.CharacterIdentity{current}}}, nil).Once()
+	var saved []model.Account
+	accountMgmt.On("SaveAccounts", mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(0).([]model.Account)
+	}).Return(nil).Once()
+
+	// Only the queue and location are fetched.
+	esi := &testutil.MockESIService{}
+	esi.On("GetCharacterSkillQueue", int64(1), mock.Anything).Return(&[]model.SkillQueue{{SkillID: 3327}}, nil).Once()
+	esi.On("GetCharacterLocation", int64(1), mock.Anything).Return(int64(30000142), nil).Once()
+
+	systemRepo := &testutil.MockSystemRepository{}
+	systemRepo.On("GetSystemName", int64(30000142)).Return("Jita")
+	cache := &testutil.MockCacheService{}
+	cache.On("SaveCache").Return(nil).Once()
+
+	svc := NewService(&testutil.MockLogger{}, nil, nil, accountMgmt, nil, nil, nil, systemRepo, cache, esi)
+
+	updated, err := svc.RefreshCharacterEndpoints(context.Background(), 1, model.EndpointSkillQueue, model.EndpointLocation)
+	require.NoError(t, err)
+	assert.True(t, updated)
+
+	require.Len(t, saved, 1)
+	character := saved[0].Characters[0].Character
+	assert.Equal(t, "Jita", character.LocationName)
+	assert.Equal(t, []model.SkillQueue{{SkillID: 3327}}, character.SkillQueue)
+	assert.Equal(t, int64(1_250_000), character.TotalSP, "endpoints not refreshed are left as they are")
+
+	accountMgmt.AssertExpectations(t)
+	esi.AssertExpectations(t)
+}
+
+func TestRefreshCharacterData_KeepsEndpointsThatFail(t *testing.T) {
+	alpha := refreshIdentity(1, "Alpha")
+	alpha.Character.TotalSP = 1_000_000
+	alpha.Character.Skills = []model.SkillResponse{{SkillID: 3327, TrainedSkillLevel: 4}}
+	alpha.Character.SkillQueue = []model.SkillQueue{{SkillID: 3327}}
+	previousClones := &model.CharacterClones{JumpClones: []model.JumpClone{{JumpCloneID: 7}}}
+	alpha.Character.Clones = previousClones
+
+	accountMgmt := &testutil.MockAccountManagementService{}
+	accountMgmt.On("FetchAccounts").Return([]model.Account{{Characters: []model.CharacterIdentity{alpha}}}, nil).Twice()
+	var saved []model.Account
+	accountMgmt.On("SaveAccounts", mock.Anything).Run(func(args mock.Arguments) {
+		saved = args.Get(0).([]model.Account)
+	}).Return(nil).Once()
+
+	esi := &testutil.MockESIService{}
+	esi.On("GetUserInfo", mock.Anything).Return(&model.UserInfoResponse{CharacterID: 1, CharacterName: "Alpha"}, nil)
+	esi.On("GetCharacter", mock.Anything).Return(&model.CharacterResponse{CorporationID: 100}, nil)
+	esi.On("GetCorporation", int64(100), mock.Anything).Return(&model.Corporation{Name: "Corp"}, nil)
+	esi.On("GetCharacterSkills", mock.Anything, mock.Anything).Return(nil, errors.New("esi down"))
+	esi.On("GetCharacterSkillQueue", mock.Anything, mock.Anything).Return(nil, errors.New("esi down"))
+	esi.On("GetCharacterLocation", mock.Anything, mock.Anything).Return(int64(30000142), nil)
+	esi.On("GetCharacterAttributes", mock.Anything, mock.Anything).Return(&model.CharacterAttributes{}, nil)
+	esi.On("GetCharacterImplants", mock.Anything, mock.Anything).Return([]int32{}, nil)
+	esi.On("GetCharacterClones", mock.Anything, mock.Anything).Return(nil, errors.New("esi down"))
+
+	systemRepo := &testutil.MockSystemRepository{}
+	systemRepo.On("GetSystemName", int64(30000142)).Return("Jita")
+	cache := &testutil.MockCacheService{}
+	cache.On("SaveCache").Return(nil).Once()
+
+	svc := NewService(&testutil.MockLogger{}, nil, nil, accountMgmt, nil, nil, nil, systemRepo, cache, esi)
+
+	updated, err := svc.RefreshCharacterData(context.Background(), 1)
+	require.NoError(t, err)
+	assert.True(t, updated)
+
+	require.Len(t, saved, 1)
+	stored := saved[0].Characters[0]
+	assert.Equal(t, int64(1_000_000), stored.Character.TotalSP, "skills that failed to fetch are kept")
+	assert.Equal(t, alpha.Character.Skills, stored.Character.Skills)
+	assert.Equal(t, alpha.Character.SkillQueue, stored.Character.SkillQueue)
+	assert.Same(t, previousClones, stored.Character.Clones)
+	assert.Equal(t, "Jita", stored.Character.LocationName)
+	assert.Equal(t, "Corp", stored.CorporationName)
+}
+
+func TestRefreshCharacterData_CancelledSavesNothing(t *testing.T) {
+	alpha := refreshIdentity(1, "Alpha")
+	alpha.Character.TotalSP = 1_000_000
+
+	accountMgmt := &testutil.MockAccountManagementService{}
+	accountMgmt.On("FetchAccounts").Return([]model.Account{{Characters: []model.CharacterIdentity{alpha}}}, nil).Once()
+
+	// Shutdown cancels the refresh while it fetches skills.
+	ctx, cancel := context.WithCancel(context.Background())
+	esi := &testutil.MockESIService{}
+	esi.On("GetUserInfo", mock.Anything).Return(&model.UserInfoResponse{CharacterID: 1, CharacterName: "Alpha"}, nil)
+	esi.On("GetCharacter", mock.Anything).Return(&model.CharacterResponse{CorporationID: 100}, nil)
+	esi.On("GetCorporation", int64(100), mock.Anything).Return(&model.Corporation{Name: "Corp"}, nil)
+	esi.On("GetCharacterSkills", mock.Anything, mock.Anything).Run(func(mock.Arguments) { cancel() }).
+		Return(nil, context.Canceled)
+	esi.On("GetCharacterSkillQueue", mock.Anything, mock.Anything).Return(nil, context.Canceled)
+	esi.On("GetCharacterLocation", mock.Anything, mock.Anything).Return(int64(0), context.Canceled)
+	esi.On("GetCharacterAttributes", mock.Anything, mock.Anything).Return((*model.CharacterAttributes)(nil), context.Canceled)
+	esi.On("GetCharacterImplants", mock.Anything, mock.Anything).Return([]int32(nil), context.Canceled)
+	esi.On("GetCharacterClones", mock.Anything, mock.Anything).Return(nil, context.Canceled)
+
+	svc := NewService(&testutil.MockLogger{}, nil, nil, accountMgmt, nil, nil, nil, nil, nil, esi)
+
+	updated, err := svc.RefreshCharacterData(ctx, 1)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.False(t, updated)
+	accountMgmt.AssertNotCalled(t, "SaveAccounts", mock.Anything)
+}
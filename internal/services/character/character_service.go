@@ -1,8 +1,10 @@
 package character
 
 import (
+	"context"
 	"fmt"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/guarzo/canifly/internal/model"
@@ -26,6 +28,9 @@ type Service struct {
 	systemRepo  interfaces.SystemRepository
 	cache       interfaces.CacheableService
 	esi         interfaces.ESIAPIService
+
+	accountsMu sync.Mutex // Serializes read-modify-save of the accounts
+	refreshMu  sync.Mutex // Held while RefreshAllCharacters runs
 }
 
 // NewService constructs a CharacterService. All dependencies are passed at
@@ -58,84 +63,135 @@ func NewService(
 }
 
 // ProcessIdentity refreshes a character identity with the latest ESI data.
-func (s *Service) ProcessIdentity(charIdentity *model.CharacterIdentity) (*model.CharacterIdentity, error) {
+func (s *Service) ProcessIdentity(ctx context.Context, charIdentity *model.CharacterIdentity) (*model.CharacterIdentity, error) {
+	if _, err := s.processIdentity(ctx, charIdentity, nil, nil); err != nil {
+		return nil, err
+	}
+
+	if err := s.cache.SaveCache(); err != nil {
+		s.logger.WithError(err).Infof("failed to save esi cache after processing identity")
+	}
+
+	return charIdentity, nil
+}
+
+// endpointSet is the character endpoints a refresh fetches; nil means all.
+type endpointSet map[model.CharacterEndpoint]bool
+
+func newEndpointSet(endpoints []model.CharacterEndpoint) endpointSet {
+	if len(endpoints) == 0 {
+		return nil
+	}
+	set := make(endpointSet, len(endpoints))
+	for _, endpoint := range endpoints {
+		set[endpoint] = true
+	}
+	return set
+}
+
+func (e endpointSet) has(endpoint model.CharacterEndpoint) bool {
+	return e == nil || e[endpoint]
+}
+
+// processIdentity is ProcessIdentity without saving the ESI cache, fetching
+// only the given endpoints. It returns the endpoints it fetched: one that
+// fails is logged and left as it was, and is not among them. If ctx is done
+// meanwhile, fetches will have failed for that alone, so ctx's error is
+// returned instead and nothing counts as fetched. When
+// affiliations is set, corporation and alliance lookups are shared through it.
+func (s *Service) processIdentity(ctx context.Context, charIdentity *model.CharacterIdentity, affiliations *affiliationCache, endpoints endpointSet) (endpointSet, error) {
 	s.logger.Infof("ProcessIdentity started for character %s (ID: %d)",
 		charIdentity.Character.CharacterName, charIdentity.Character.CharacterID)
 	s.logger.Infof("Token expiry: %v", charIdentity.Token.Expiry)
 
-	user, err := s.esi.GetUserInfo(&charIdentity.Token)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get user info: %v", err)
-	}
-	s.logger.Debugf("Fetched user info for character %s (ID: %d)", user.CharacterName, user.CharacterID)
+	characterID := charIdentity.Character.CharacterID
+	fetched := make(endpointSet)
 
-	characterResponse, err := s.esi.GetCharacter(strconv.FormatInt(charIdentity.Character.CharacterID, 10))
-	if err != nil {
-		s.logger.Warnf("Failed to get character %s: %v", charIdentity.Character.CharacterName, err)
+	if endpoints.has(model.EndpointAffiliation) {
+		user, err := s.esi.GetUserInfo(ctx, &charIdentity.Token)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get user info: %w", err)
+		}
+		s.logger.Debugf("Fetched user info for character %s (ID: %d)", user.CharacterName, user.CharacterID)
+		charIdentity.Character.UserInfoResponse = *user
+		if corporationName, allianceName, err := s.fetchAffiliation(ctx, charIdentity, affiliations); err != nil {
+			s.logger.Warnf("Failed to get affiliation for character %d: %v", characterID, err)
+		} else {
+			charIdentity.CorporationName, charIdentity.AllianceName = corporationName, allianceName
+			fetched[model.EndpointAffiliation] = true
+		}
 	}
 
-	skills, err := s.esi.GetCharacterSkills(charIdentity.Character.CharacterID, &charIdentity.Token)
-	if err != nil {
-		s.logger.Errorf("Failed to get skills for character %d: %v", charIdentity.Character.CharacterID, err)
-		skills = &model.CharacterSkillsResponse{Skills: []model.SkillResponse{}}
-	} else {
-		s.logger.Infof("Successfully fetched %d skills for character %d, total SP: %d",
-			len(skills.Skills), charIdentity.Character.CharacterID, skills.TotalSP)
+	if endpoints.has(model.EndpointSkills) {
+		if skills, err := s.esi.GetCharacterSkills(ctx, characterID, &charIdentity.Token); err != nil {
+			s.logger.Errorf("Failed to get skills for character %d: %v", characterID, err)
+		} else {
+			s.logger.Infof("Successfully fetched %d skills for character %d, total SP: %d",
+				len(skills.Skills), characterID, skills.TotalSP)
+			charIdentity.Character.CharacterSkillsResponse = *skills
+			fetched[model.EndpointSkills] = true
+		}
 	}
 
-	skillQueue, err := s.esi.GetCharacterSkillQueue(charIdentity.Character.CharacterID, &charIdentity.Token)
-	if err != nil {
-		s.logger.Warnf("Failed to get eve queue for character %d: %v", charIdentity.Character.CharacterID, err)
-		skillQueue = &[]model.SkillQueue{}
+	if endpoints.has(model.EndpointSkillQueue) {
+		if skillQueue, err := s.esi.GetCharacterSkillQueue(ctx, characterID, &charIdentity.Token); err != nil {
+			s.logger.Warnf("Failed to get eve queue for character %d: %v", characterID, err)
+		} else {
+			s.logger.Debugf("Fetched %d eve queue entries for character %d", len(*skillQueue), characterID)
+			charIdentity.Character.SkillQueue = *skillQueue
+			charIdentity.MCT = s.isCharacterTraining(*skillQueue)
+			if charIdentity.MCT {
+				if skillType, found := s.skillRepo.GetSkillTypeByID(strconv.Itoa(int(charIdentity.Character.SkillQueue[0].SkillID))); found {
+					charIdentity.Training = skillType.TypeName
+				} else {
+					charIdentity.Training = ""
+				}
+			}
+			fetched[model.EndpointSkillQueue] = true
+		}
 	}
-	s.logger.Debugf("Fetched %d eve queue entries for character %d", len(*skillQueue), charIdentity.Character.CharacterID)
 
-	characterLocation, err := s.esi.GetCharacterLocation(charIdentity.Character.CharacterID, &charIdentity.Token)
-	if err != nil {
-		s.logger.Errorf("Failed to get location for character %d: %v", charIdentity.Character.CharacterID, err)
-		characterLocation = 0
-	} else {
-		s.logger.Infof("Successfully fetched location for character %d: %d", charIdentity.Character.CharacterID, characterLocation)
+	if endpoints.has(model.EndpointLocation) {
+		if characterLocation, err := s.esi.GetCharacterLocation(ctx, characterID, &charIdentity.Token); err != nil {
+			s.logger.Errorf("Failed to get location for character %d: %v", characterID, err)
+		} else {
+			s.logger.Infof("Successfully fetched location for character %d: %d", characterID, characterLocation)
+			charIdentity.Character.Location = characterLocation
+			charIdentity.Character.LocationName = s.systemRepo.GetSystemName(characterLocation)
+			fetched[model.EndpointLocation] = true
+		}
 	}
 
-	corporationName := ""
-	allianceName := ""
-	if characterResponse != nil {
-		characterCorporation, err := s.esi.GetCorporation(int64(characterResponse.CorporationID), &charIdentity.Token)
-		if err != nil {
-			s.logger.Warnf("Failed to get corporation for corporation %d: %v", characterResponse.CorporationID, err)
+	if endpoints.has(model.EndpointAttributes) {
+		if attributes, err := s.esi.GetCharacterAttributes(ctx, characterID, &charIdentity.Token); err != nil {
+			s.logger.Warnf("Failed to get attributes for character %d: %v", characterID, err)
 		} else {
-			corporationName = characterCorporation.Name
-		}
-		if characterCorporation != nil && characterCorporation.AllianceID != 0 {
-			characterAlliance, err := s.esi.GetAlliance(int64(characterCorporation.AllianceID), &charIdentity.Token)
-			if err != nil {
-				s.logger.Warnf("Failed to get alliance for character %s: %v", characterCorporation.AllianceID, err)
-			} else {
-				allianceName = characterAlliance.Name
-			}
+			charIdentity.Character.Attributes = attributes
+			fetched[model.EndpointAttributes] = true
 		}
 	}
 
-	s.logger.Debugf("Character %d is located at %d", charIdentity.Character.CharacterID, characterLocation)
+	if endpoints.has(model.EndpointImplants) {
+		if implants, err := s.esi.GetCharacterImplants(ctx, characterID, &charIdentity.Token); err != nil {
+			s.logger.Warnf("Failed to get implants for character %d: %v", characterID, err)
+		} else {
+			charIdentity.Character.Implants = implants
+			fetched[model.EndpointImplants] = true
+		}
+	}
 
-	// Update charIdentity with fetched data
-	s.logger.Debugf("updating %s", user.CharacterName)
-	charIdentity.Character.UserInfoResponse = *user
-	charIdentity.Character.CharacterSkillsResponse = *skills
-	charIdentity.Character.SkillQueue = *skillQueue
-	charIdentity.Character.Location = characterLocation
-	charIdentity.Character.LocationName = s.systemRepo.GetSystemName(charIdentity.Character.Location)
-	charIdentity.MCT = s.isCharacterTraining(*skillQueue)
-	if charIdentity.MCT {
-		if skillType, found := s.skillRepo.GetSkillTypeByID(strconv.Itoa(int(charIdentity.Character.SkillQueue[0].SkillID))); found {
-			charIdentity.Training = skillType.TypeName
+	if endpoints.has(model.EndpointClones) {
+		if clones, err := s.fetchClones(ctx, charIdentity); err != nil {
+			s.logger.Warnf("Failed to get clones for character %d: %v", characterID, err)
 		} else {
-			charIdentity.Training = ""
+			charIdentity.Character.Clones = clones
+			fetched[model.EndpointClones] = true
 		}
 	}
-	charIdentity.CorporationName = corporationName
-	charIdentity.AllianceName = allianceName
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
 	// Initialize maps if nil
 	if charIdentity.Character.QualifiedPlans == nil {
@@ -151,11 +207,29 @@ func (s *Service) ProcessIdentity(charIdentity *model.CharacterIdentity) (*model
 		charIdentity.Character.MissingSkills = make(map[string]map[string]int32)
 	}
 
-	if err := s.cache.SaveCache(); err != nil {
-		s.logger.WithError(err).Infof("failed to save esi cache after processing identity")
+	return fetched, nil
+}
+
+// fetchAffiliation returns the names of the character's corporation and
+// alliance, the latter empty if it has none.
+func (s *Service) fetchAffiliation(ctx context.Context, charIdentity *model.CharacterIdentity, affiliations *affiliationCache) (corporationName, allianceName string, err error) {
+	characterResponse, err := s.esi.GetCharacter(ctx, strconv.FormatInt(charIdentity.Character.CharacterID, 10))
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get character: %w", err)
 	}
 
-	return charIdentity, nil
+	characterCorporation, err := affiliations.corporation(ctx, s.esi, int64(characterResponse.CorporationID), &charIdentity.Token)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get corporation %d: %w", characterResponse.CorporationID, err)
+	}
+	if characterCorporation.AllianceID != 0 {
+		characterAlliance, err := affiliations.alliance(ctx, s.esi, int64(characterCorporation.AllianceID), &charIdentity.Token)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to get alliance %d: %w", characterCorporation.AllianceID, err)
+		}
+		allianceName = characterAlliance.Name
+	}
+	return characterCorporation.Name, allianceName, nil
 }
 
 func (s *Service) isCharacterTraining(queue []model.SkillQueue) bool {
@@ -186,6 +260,9 @@ func (s *Service) DoesCharacterExist(characterID int64) (bool, *model.CharacterI
 
 // UpdateCharacter applies a partial update to the character with the given ID.
 func (s *Service) UpdateCharacter(characterID int64, update model.CharacterUpdate) error {
+	s.accountsMu.Lock()
+	defer s.accountsMu.Unlock()
+
 	accounts, err := s.accountMgmt.FetchAccounts()
 	if err != nil {
 		return fmt.Errorf("failed to fetch accounts: %w", err)
@@ -217,6 +294,9 @@ func (s *Service) UpdateCharacter(characterID int64, update model.CharacterUpdat
 	if update.MCT != nil {
 		charIdentity.MCT = *update.MCT
 	}
+	if update.Farm != nil {
+		charIdentity.Farm = *update.Farm
+	}
 
 	if err := s.accountMgmt.SaveAccounts(accounts); err != nil {
 		return fmt.Errorf("failed to save accounts: %w", err)
@@ -226,6 +306,9 @@ func (s *Service) UpdateCharacter(characterID int64, update model.CharacterUpdat
 }
 
 func (s *Service) RemoveCharacter(characterID int64) error {
+	s.accountsMu.Lock()
+	defer s.accountsMu.Unlock()
+
 	accounts, err := s.accountMgmt.FetchAccounts()
 	if err != nil {
 		return err
@@ -247,9 +330,20 @@ func (s *Service) RemoveCharacter(characterID int64) error {
 	return fmt.Errorf("character not found")
 }
 
-func (s *Service) RefreshCharacterData(characterID int64) (bool, error) {
+func (s *Service) RefreshCharacterData(ctx context.Context, characterID int64) (bool, error) {
 	s.logger.Infof("RefreshCharacterData called for character ID: %d", characterID)
+	return s.refreshCharacter(ctx, characterID, nil)
+}
+
+// RefreshCharacterEndpoints refreshes only the given endpoints of a
+// character's ESI data, leaving the rest as it is. With no endpoints it
+// refreshes everything, like RefreshCharacterData.
+func (s *Service) RefreshCharacterEndpoints(ctx context.Context, characterID int64, endpoints ...model.CharacterEndpoint) (bool, error) {
+	s.logger.Infof("Refreshing %v for character ID: %d", endpoints, characterID)
+	return s.refreshCharacter(ctx, characterID, newEndpointSet(endpoints))
+}
 
+func (s *Service) refreshCharacter(ctx context.Context, characterID int64, endpoints endpointSet) (bool, error) {
 	accounts, err := s.accountMgmt.FetchAccounts()
 	if err != nil {
 		return false, fmt.Errorf("failed to fetch accounts: %w", err)
@@ -258,48 +352,30 @@ func (s *Service) RefreshCharacterData(characterID int64) (bool, error) {
 	for i := range accounts {
 		for j := range accounts[i].Characters {
 			if accounts[i].Characters[j].Character.CharacterID == characterID {
-				charIdentity := &accounts[i].Characters[j]
+				charIdentity := accounts[i].Characters[j]
 				s.logger.Infof("Found character: %s (ID: %d)", charIdentity.Character.CharacterName, characterID)
 
-				if time.Now().After(charIdentity.Token.Expiry) {
-					s.logger.Infof("Token expired for character %s, refreshing token", charIdentity.Character.CharacterName)
-
-					if charIdentity.Token.RefreshToken == "" {
-						s.logger.Errorf("No refresh token available for character %s", charIdentity.Character.CharacterName)
-						return false, fmt.Errorf("no refresh token available")
-					}
-
-					newToken, err := s.authClient.RefreshToken(charIdentity.Token.RefreshToken)
-					if err != nil {
-						s.logger.Errorf("Failed to refresh token for character %s: %v", charIdentity.Character.CharacterName, err)
-						return false, fmt.Errorf("failed to refresh token: %w", err)
+				refreshed, refreshErr := s.refreshIdentity(ctx, charIdentity, nil, endpoints)
+				if refreshed.dataFetched {
+					s.logger.Infof("ProcessIdentity completed, skills: %d, total SP: %d",
+						len(refreshed.identity.Character.CharacterSkillsResponse.Skills),
+						refreshed.identity.Character.CharacterSkillsResponse.TotalSP)
+					if err := s.cache.SaveCache(); err != nil {
+						s.logger.WithError(err).Infof("failed to save esi cache after processing identity")
 					}
-
-					charIdentity.Token = *newToken
-					s.logger.Infof("Token refreshed successfully, new expiry: %v", newToken.Expiry)
-					accounts[i].Characters[j].Token = *newToken
-				} else {
-					s.logger.Infof("Token valid until %v, proceeding with refresh", charIdentity.Token.Expiry)
 				}
-
-				updatedChar, err := s.ProcessIdentity(charIdentity)
-				if err != nil {
-					s.logger.Errorf("ProcessIdentity failed: %v", err)
-					return false, fmt.Errorf("failed to update character: %w", err)
+				if refreshed.dataFetched || refreshed.tokenRenewed {
+					if err := s.saveRefreshed([]refreshedCharacter{refreshed}); err != nil {
+						return false, fmt.Errorf("failed to save accounts: %w", err)
+					}
 				}
-
-				s.logger.Infof("ProcessIdentity completed, skills: %d, total SP: %d",
-					len(updatedChar.Character.CharacterSkillsResponse.Skills),
-					updatedChar.Character.CharacterSkillsResponse.TotalSP)
-
-				accounts[i].Characters[j] = *updatedChar
-
-				if err := s.accountMgmt.SaveAccounts(accounts); err != nil {
-					return false, fmt.Errorf("failed to save accounts: %w", err)
+				if refreshErr != nil {
+					s.logger.Errorf("Refresh failed for character %d: %v", characterID, refreshErr)
+					return false, refreshErr
 				}
 
 				s.logger.Infof("Character data saved successfully")
-				return true, nil
+				return refreshed.dataFetched, nil
 			}
 		}
 	}
@@ -307,3 +383,27 @@ func (s *Service) RefreshCharacterData(characterID int64) (bool, error) {
 	s.logger.Warnf("Character ID %d not found in any account", characterID)
 	return false, fmt.Errorf("character not found")
 }
+
+// ensureFreshToken refreshes the character's access token if it has expired.
+func (s *Service) ensureFreshToken(charIdentity *model.CharacterIdentity) error {
+	if !time.Now().After(charIdentity.Token.Expiry) {
+		s.logger.Infof("Token valid until %v, proceeding with refresh", charIdentity.Token.Expiry)
+		return nil
+	}
+	s.logger.Infof("Token expired for character %s, refreshing token", charIdentity.Character.CharacterName)
+
+	if charIdentity.Token.RefreshToken == "" {
+		s.logger.Errorf("No refresh token available for character %s", charIdentity.Character.CharacterName)
+		return fmt.Errorf("no refresh token available")
+	}
+
+	newToken, err := s.authClient.RefreshToken(charIdentity.Token.RefreshToken)
+	if err != nil {
+		s.logger.Errorf("Failed to refresh token for character %s: %v", charIdentity.Character.CharacterName, err)
+		return fmt.Errorf("failed to refresh token: %w", err)
+	}
+
+	charIdentity.Token = *newToken
+	s.logger.Infof("Token refreshed successfully, new expiry: %v", newToken.Expiry)
+	return nil
+}
@@ -0,0 +1,76 @@
+package character
+
+import (
+	"context"
+
+	"golang.org/x/oauth2"
+
+	"github.com/guarzo/canifly/internal/model"
+)
+
+// fetchClones fetches a character's clones and resolves where each one is.
+func (s *Service) fetchClones(ctx context.Context, charIdentity *model.CharacterIdentity) (*model.CharacterClones, error) {
+	resp, err := s.esi.GetCharacterClones(ctx, charIdentity.Character.CharacterID, &charIdentity.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	// Jump clones tend to share a few staging stations, so look each up once.
+	places := make(map[int64]model.ClonePlace)
+	place := func(locationID int64, locationType string) model.ClonePlace {
+		if p, ok := places[locationID]; ok {
+			return p
+		}
+		p := s.resolveClonePlace(ctx, locationID, locationType, &charIdentity.Token)
+		places[locationID] = p
+		return p
+	}
+
+	clones := &model.CharacterClones{
+		JumpClones:            make([]model.JumpClone, 0, len(resp.JumpClones)),
+		LastCloneJumpDate:     resp.LastCloneJumpDate,
+		LastStationChangeDate: resp.LastStationChangeDate,
+	}
+	if resp.HomeLocation.LocationID != 0 {
+		clones.HomeLocation = place(resp.HomeLocation.LocationID, resp.HomeLocation.LocationType)
+	}
+	for _, jc := range resp.JumpClones {
+		clones.JumpClones = append(clones.JumpClones, model.JumpClone{
+			JumpCloneID: jc.JumpCloneID,
+			Name:        jc.Name,
+			Location:    place(jc.LocationID, jc.LocationType),
+			Implants:    jc.Implants,
+		})
+	}
+	return clones, nil
+}
+
+// resolveClonePlace looks up the name and solar system of a station or
+// structure. Structures the character can't dock at are left unnamed.
+func (s *Service) resolveClonePlace(ctx context.Context, locationID int64, locationType string, token *oauth2.Token) model.ClonePlace {
+	place := model.ClonePlace{LocationID: locationID, LocationType: locationType}
+
+	switch locationType {
+	case "station":
+		station, err := s.esi.GetStation(ctx, locationID)
+		if err != nil {
+			s.logger.Warnf("Failed to get station %d: %v", locationID, err)
+			return place
+		}
+		place.Name = station.Name
+		place.SystemID = station.SystemID
+	case "structure":
+		structure, err := s.esi.GetStructure(ctx, locationID, token)
+		if err != nil {
+			s.logger.Warnf("Failed to get structure %d: %v", locationID, err)
+			return place
+		}
+		place.Name = structure.Name
+		place.SystemID = structure.SystemID
+	}
+
+	if place.SystemID != 0 {
+		place.SystemName = s.systemRepo.GetSystemName(place.SystemID)
+	}
+	return place
+}
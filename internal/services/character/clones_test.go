@@ -0,0 +1,73 @@
+package character
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/testutil"
+)
+
+func TestFetchClones(t *testing.T) {
+	jumped := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	resp := &model.CloneLocation{
+		JumpClones: []model.JumpCloneResponse{
+			{JumpCloneID: 1, Name: "Snakes", LocationID: 60003760, LocationType: "station", Implants: []int32{22107, 22108}},
+			{JumpCloneID: 2, LocationID: 1035466617946, LocationType: "structure", Implants: []int32{10216}},
+			{JumpCloneID: 3, LocationID: 60003760, LocationType: "station"},
+		},
+		LastCloneJumpDate: &jumped,
+	}
+	resp.HomeLocation.LocationID = 60003760
+	resp.HomeLocation.LocationType = "station"
+
+	esi := &testutil.MockESIService{}
+	esi.On("GetCharacterClones", int64(1), mock.Anything).Return(resp, nil)
+	// The station is looked up once for the home and both clones in it.
+	esi.On("GetStation", int64(60003760)).Return(&model.Station{ID: 60003760, SystemID: 30000142, Name: "Jita IV - Moon 4 - Caldari Navy Assembly Plant"}, nil).Once()
+	esi.On("GetStructure", int64(1035466617946), mock.Anything).Return(nil, errors.New("forbidden")).Once()
+	systemRepo := &testutil.MockSystemRepository{}
+	systemRepo.On("GetSystemName", int64(30000142)).Return("Jita")
+
+	svc := NewService(&testutil.MockLogger{}, nil, nil, nil, nil, nil, nil, systemRepo, nil, esi)
+	identity := refreshIdentity(1, "Alpha")
+
+	clones, err := svc.fetchClones(context.Background(), &identity)
+	require.NoError(t, err)
+	require.NotNil(t, clones)
+
+	jita := model.ClonePlace{
+		LocationID:   60003760,
+		LocationType: "station",
+		Name:         "Jita IV - Moon 4 - Caldari Navy Assembly Plant",
+		SystemID:     30000142,
+		SystemName:   "Jita",
+	}
+	assert.Equal(t, jita, clones.HomeLocation)
+	assert.Equal(t, &jumped, clones.LastCloneJumpDate)
+	require.Len(t, clones.JumpClones, 3)
+	assert.Equal(t, model.JumpClone{JumpCloneID: 1, Name: "Snakes", Location: jita, Implants: []int32{22107, 22108}}, clones.JumpClones[0])
+	// An inaccessible structure is kept, unnamed.
+	assert.Equal(t, model.ClonePlace{LocationID: 1035466617946, LocationType: "structure"}, clones.JumpClones[1].Location)
+	assert.Equal(t, jita, clones.JumpClones[2].Location)
+
+	esi.AssertExpectations(t)
+}
+
+func TestFetchClones_Error(t *testing.T) {
+	esi := &testutil.MockESIService{}
+	esi.On("GetCharacterClones", int64(1), mock.Anything).Return(nil, errors.New("esi down"))
+
+	svc := NewService(&testutil.MockLogger{}, nil, nil, nil, nil, nil, nil, nil, nil, esi)
+	identity := refreshIdentity(1, "Alpha")
+
+	clones, err := svc.fetchClones(context.Background(), &identity)
+	assert.Error(t, err)
+	assert.Nil(t, clones)
+}
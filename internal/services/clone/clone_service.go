@@ -0,0 +1,122 @@
+package clone
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/services/interfaces"
+)
+
+// Compile-time interface check.
+var _ interfaces.CloneService = (*Service)(nil)
+
+// A clone jump is allowed once every cloneJumpCooldown, less an hour per
+// level of Infomorph Synchronizing.
+const (
+	cloneJumpCooldown        = 24 * time.Hour
+	infomorphSynchronizingID = 33399
+)
+
+// Service implements interfaces.CloneService.
+type Service struct {
+	logger    interfaces.Logger
+	accounts  interfaces.AccountManagementService
+	skillRepo interfaces.SkillRepository
+	now       func() time.Time
+}
+
+// NewService constructs a CloneService.
+func NewService(logger interfaces.Logger, accounts interfaces.AccountManagementService, skillRepo interfaces.SkillRepository) *Service {
+	return &Service{
+		logger:    logger,
+		accounts:  accounts,
+		skillRepo: skillRepo,
+		now:       time.Now,
+	}
+}
+
+// GetCloneInventory lists every character's jump clones, as of its last
+// refresh, with implant names from invTypes and when it can next jump.
+// Characters whose clones haven't been fetched yet are left out.
+func (s *Service) GetCloneInventory() (*model.CloneInventory, error) {
+	accounts, err := s.accounts.FetchAccounts()
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	inventory := &model.CloneInventory{Characters: []model.CharacterCloneInventory{}}
+	for _, account := range accounts {
+		for _, identity := range account.Characters {
+			if identity.Character.Clones == nil {
+				continue
+			}
+			character := s.characterInventory(account.Name, identity.Character, now)
+			inventory.Characters = append(inventory.Characters, character)
+			inventory.JumpClones += len(character.JumpClones)
+		}
+	}
+
+	sort.SliceStable(inventory.Characters, func(i, j int) bool {
+		return inventory.Characters[i].CharacterName < inventory.Characters[j].CharacterName
+	})
+	return inventory, nil
+}
+
+func (s *Service) characterInventory(accountName string, character model.Character, now time.Time) model.CharacterCloneInventory {
+	clones := character.Clones
+	inventory := model.CharacterCloneInventory{
+		CharacterID:    character.CharacterID,
+		CharacterName:  character.CharacterName,
+		AccountName:    accountName,
+		HomeLocation:   clones.HomeLocation,
+		ActiveImplants: s.implants(character.Implants),
+		JumpClones:     make([]model.CloneInventoryClone, 0, len(clones.JumpClones)),
+		CanJump:        true,
+	}
+	for _, jc := range clones.JumpClones {
+		inventory.JumpClones = append(inventory.JumpClones, model.CloneInventoryClone{
+			JumpCloneID: jc.JumpCloneID,
+			Name:        jc.Name,
+			Location:    jc.Location,
+			Implants:    s.implants(jc.Implants),
+		})
+	}
+
+	if clones.LastCloneJumpDate != nil {
+		next := clones.LastCloneJumpDate.Add(jumpCooldown(character))
+		if next.After(now) {
+			inventory.NextJumpAt = &next
+			inventory.CanJump = false
+		}
+	}
+	return inventory
+}
+
+// implants names implant type IDs from invTypes. Unknown types keep an empty
+// name.
+func (s *Service) implants(typeIDs []int32) []model.CloneImplant {
+	implants := make([]model.CloneImplant, 0, len(typeIDs))
+	for _, typeID := range typeIDs {
+		implant := model.CloneImplant{TypeID: typeID}
+		if implantType, ok := s.skillRepo.GetSkillTypeByID(strconv.Itoa(int(typeID))); ok {
+			implant.Name = implantType.TypeName
+		} else {
+			s.logger.Debugf("No invTypes entry for implant %d", typeID)
+		}
+		implants = append(implants, implant)
+	}
+	return implants
+}
+
+// jumpCooldown is how long the character must wait between clone jumps.
+func jumpCooldown(character model.Character) time.Duration {
+	for _, skill := range character.Skills {
+		if skill.SkillID == infomorphSynchronizingID {
+			return cloneJumpCooldown - time.Duration(skill.ActiveSkillLevel)*time.Hour
+		}
+	}
+	return cloneJumpCooldown
+}
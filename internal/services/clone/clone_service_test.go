@@ -0,0 +1,77 @@
+package clone
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/testutil"
+)
+
+var cloneNow = time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+
+func cloneIdentity(id int64, name string, clones *model.CharacterClones, implants ...int32) model.CharacterIdentity {
+	var c model.Character
+	c.CharacterID = id
+	c.CharacterName = name
+	c.Clones = clones
+	c.Implants = implants
+	return model.CharacterIdentity{Character: c}
+}
+
+func TestGetCloneInventory(t *testing.T) {
+	staging := model.ClonePlace{LocationID: 1035466617946, LocationType: "structure", Name: "Staging Keepstar", SystemID: 30004759, SystemName: "1DQ1-A"}
+	jumped := cloneNow.Add(-20 * time.Hour)
+
+	synced := cloneIdentity(1, "Bravo", &model.CharacterClones{
+		JumpClones:        []model.JumpClone{{JumpCloneID: 10, Name: "+5", Location: staging, Implants: []int32{10216, 99999}}},
+		LastCloneJumpDate: &jumped,
+	}, 22107)
+	// Infomorph Synchronizing V brings the cooldown down to 19 hours.
+	synced.Character.Skills = []model.SkillResponse{{SkillID: infomorphSynchronizingID, ActiveSkillLevel: 5, TrainedSkillLevel: 5}}
+
+	waiting := cloneIdentity(2, "Alpha", &model.CharacterClones{
+		JumpClones:        []model.JumpClone{{JumpCloneID: 20, Location: staging}, {JumpCloneID: 21, Location: staging}},
+		LastCloneJumpDate: &jumped,
+	})
+
+	accounts := &testutil.MockAccountManagementService{}
+	accounts.On("FetchAccounts").Return([]model.Account{
+		{Name: "Main", Characters: []model.CharacterIdentity{synced, waiting}},
+		{Name: "New", Characters: []model.CharacterIdentity{cloneIdentity(3, "Unfetched", nil)}},
+	}, nil)
+
+	skillRepo := &testutil.MockSkillRepository{}
+	skillRepo.On("GetSkillTypeByID", "22107").Return(model.SkillType{TypeID: "22107", TypeName: "High-grade Snake Alpha"}, true)
+	skillRepo.On("GetSkillTypeByID", "10216").Return(model.SkillType{TypeID: "10216", TypeName: "Limited Ocular Filter"}, true)
+	skillRepo.On("GetSkillTypeByID", mock.Anything).Return(model.SkillType{}, false)
+
+	svc := NewService(&testutil.MockLogger{}, accounts, skillRepo)
+	svc.now = func() time.Time { return cloneNow }
+
+	inventory, err := svc.GetCloneInventory()
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, inventory.JumpClones)
+	require.Len(t, inventory.Characters, 2, "characters without clone data are left out")
+
+	alpha := inventory.Characters[0]
+	assert.Equal(t, "Alpha", alpha.CharacterName)
+	assert.Equal(t, "Main", alpha.AccountName)
+	assert.False(t, alpha.CanJump)
+	require.NotNil(t, alpha.NextJumpAt)
+	assert.Equal(t, jumped.Add(24*time.Hour), *alpha.NextJumpAt)
+	assert.Empty(t, alpha.ActiveImplants)
+
+	bravo := inventory.Characters[1]
+	assert.True(t, bravo.CanJump)
+	assert.Nil(t, bravo.NextJumpAt)
+	assert.Equal(t, []model.CloneImplant{{TypeID: 22107, Name: "High-grade Snake Alpha"}}, bravo.ActiveImplants)
+	require.Len(t, bravo.JumpClones, 1)
+	assert.Equal(t, staging, bravo.JumpClones[0].Location)
+	assert.Equal(t, []model.CloneImplant{{TypeID: 10216, Name: "Limited Ocular Filter"}, {TypeID: 99999}}, bravo.JumpClones[0].Implants)
+}
@@ -255,6 +255,17 @@ func (s *ConfigurationService) SaveRoles(roles []string) error {
 	return s.storage.SaveConfigData(configData)
 }
 
+// SaveInjectorPrices stores the ISK prices used to cost skill injector estimates.
+func (s *ConfigurationService) SaveInjectorPrices(prices model.InjectorPrices) error {
+	configData, err := s.storage.LoadConfigData()
+	if err != nil {
+		return err
+	}
+
+	configData.InjectorPrices = &prices
+	return s.storage.SaveConfigData(configData)
+}
+
 // EVE Credentials methods
 
 func (s *ConfigurationService) NeedsEVEConfiguration() (bool, error) {
@@ -1,6 +1,7 @@
 package eve
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -46,77 +47,127 @@ func NewESIClient(
 }
 
 // GetUserInfo fetches the EVE user info for the given access token.
-func (s *ESIClient) GetUserInfo(token *oauth2.Token) (*model.UserInfoResponse, error) {
+func (s *ESIClient) GetUserInfo(ctx context.Context, token *oauth2.Token) (*model.UserInfoResponse, error) {
 	if token == nil || token.AccessToken == "" {
 		return nil, fmt.Errorf("no access token provided")
 	}
 
 	var user model.UserInfoResponse
-	if err := s.httpClient.GetJSONFromURL("https://login.eveonline.com/oauth/verify", token, false, &user); err != nil {
+	if err := s.httpClient.GetJSONFromURL(ctx, "https://login.eveonline.com/oauth/verify", token, false, &user); err != nil {
 		return nil, fmt.Errorf("failed to decode user info: %w", err)
 	}
 
 	return &user, nil
 }
 
-func (s *ESIClient) GetCharacter(id string) (*model.CharacterResponse, error) {
+func (s *ESIClient) GetCharacter(ctx context.Context, id string) (*model.CharacterResponse, error) {
 	endpoint := fmt.Sprintf("/latest/characters/%s/", id)
 	resp := &model.CharacterResponse{}
-	if err := s.httpClient.GetJSON(endpoint, nil, true, resp); err != nil {
+	if err := s.httpClient.GetJSON(ctx, endpoint, nil, true, resp); err != nil {
 		return nil, err
 	}
 	return resp, nil
 }
 
-func (s *ESIClient) GetCharacterSkills(characterID int64, token *oauth2.Token) (*model.CharacterSkillsResponse, error) {
+func (s *ESIClient) GetCharacterSkills(ctx context.Context, characterID int64, token *oauth2.Token) (*model.CharacterSkillsResponse, error) {
 	s.logger.Debugf("fetching skills for %d", characterID)
 	endpoint := fmt.Sprintf("/latest/characters/%d/skills/", characterID)
 	resp := &model.CharacterSkillsResponse{}
-	if err := s.httpClient.GetJSON(endpoint, token, false, resp); err != nil {
+	if err := s.httpClient.GetJSON(ctx, endpoint, token, true, resp); err != nil {
 		return nil, err
 	}
 	return resp, nil
 }
 
-func (s *ESIClient) GetCharacterSkillQueue(characterID int64, token *oauth2.Token) (*[]model.SkillQueue, error) {
+func (s *ESIClient) GetCharacterSkillQueue(ctx context.Context, characterID int64, token *oauth2.Token) (*[]model.SkillQueue, error) {
 	s.logger.Debugf("fetching skill queue for %d", characterID)
 	endpoint := fmt.Sprintf("/latest/characters/%d/skillqueue/", characterID)
 	var resp []model.SkillQueue
-	if err := s.httpClient.GetJSON(endpoint, token, false, &resp); err != nil {
+	if err := s.httpClient.GetJSON(ctx, endpoint, token, true, &resp); err != nil {
 		return nil, err
 	}
 	return &resp, nil
 }
 
-func (s *ESIClient) GetCharacterLocation(characterID int64, token *oauth2.Token) (int64, error) {
+func (s *ESIClient) GetCharacterLocation(ctx context.Context, characterID int64, token *oauth2.Token) (int64, error) {
 	s.logger.Debugf("fetching location for %d", characterID)
 	endpoint := fmt.Sprintf("/latest/characters/%d/location/", characterID)
 	resp := &model.CharacterLocation{}
-	if err := s.httpClient.GetJSON(endpoint, token, false, resp); err != nil {
+	if err := s.httpClient.GetJSON(ctx, endpoint, token, true, resp); err != nil {
 		return 0, err
 	}
 	return resp.SolarSystemID, nil
 }
 
-func (s *ESIClient) GetCorporation(id int64, token *oauth2.Token) (*model.Corporation, error) {
+func (s *ESIClient) GetCharacterAttributes(ctx context.Context, characterID int64, token *oauth2.Token) (*model.CharacterAttributes, error) {
+	s.logger.Debugf("fetching attributes for %d", characterID)
+	endpoint := fmt.Sprintf("/latest/characters/%d/attributes/", characterID)
+	resp := &model.CharacterAttributes{}
+	if err := s.httpClient.GetJSON(ctx, endpoint, token, true, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *ESIClient) GetCharacterImplants(ctx context.Context, characterID int64, token *oauth2.Token) ([]int32, error) {
+	s.logger.Debugf("fetching implants for %d", characterID)
+	endpoint := fmt.Sprintf("/latest/characters/%d/implants/", characterID)
+	var resp []int32
+	if err := s.httpClient.GetJSON(ctx, endpoint, token, true, &resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *ESIClient) GetCharacterClones(ctx context.Context, characterID int64, token *oauth2.Token) (*model.CloneLocation, error) {
+	s.logger.Debugf("fetching clones for %d", characterID)
+	endpoint := fmt.Sprintf("/latest/characters/%d/clones/", characterID)
+	resp := &model.CloneLocation{}
+	if err := s.httpClient.GetJSON(ctx, endpoint, token, true, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *ESIClient) GetStation(ctx context.Context, id int64) (*model.Station, error) {
+	endpoint := fmt.Sprintf("/latest/universe/stations/%d/", id)
+	resp := &model.Station{}
+	if err := s.httpClient.GetJSON(ctx, endpoint, nil, true, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// GetStructure fetches a player structure, which ESI only returns to
+// characters with docking access to it.
+func (s *ESIClient) GetStructure(ctx context.Context, id int64, token *oauth2.Token) (*model.Structure, error) {
+	endpoint := fmt.Sprintf("/latest/universe/structures/%d/", id)
+	resp := &model.Structure{}
+	if err := s.httpClient.GetJSON(ctx, endpoint, token, true, resp); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (s *ESIClient) GetCorporation(ctx context.Context, id int64, token *oauth2.Token) (*model.Corporation, error) {
 	endpoint := fmt.Sprintf("/latest/corporations/%d/", id)
 	resp := &model.Corporation{}
-	if err := s.httpClient.GetJSON(endpoint, token, true, resp); err != nil {
+	if err := s.httpClient.GetJSON(ctx, endpoint, token, true, resp); err != nil {
 		return nil, err
 	}
 	return resp, nil
 }
 
-func (s *ESIClient) GetAlliance(id int64, token *oauth2.Token) (*model.Alliance, error) {
+func (s *ESIClient) GetAlliance(ctx context.Context, id int64, token *oauth2.Token) (*model.Alliance, error) {
 	endpoint := fmt.Sprintf("/latest/alliances/%d/", id)
 	resp := &model.Alliance{}
-	if err := s.httpClient.GetJSON(endpoint, token, true, resp); err != nil {
+	if err := s.httpClient.GetJSON(ctx, endpoint, token, true, resp); err != nil {
 		return nil, err
 	}
 	return resp, nil
 }
 
-func (s *ESIClient) ResolveCharacterNames(charIds []string) (map[string]string, error) {
+func (s *ESIClient) ResolveCharacterNames(ctx context.Context, charIds []string) (map[string]string, error) {
 	charIdToName := make(map[string]string)
 	deletedChars, err := s.storage.LoadDeletedCharacters()
 	if err != nil {
@@ -129,7 +180,7 @@ func (s *ESIClient) ResolveCharacterNames(charIds []string) (map[string]string,
 			continue
 		}
 
-		character, err := s.GetCharacter(id)
+		character, err := s.GetCharacter(ctx, id)
 		if err != nil {
 			s.logger.Warnf("failed to retrieve name for %s", id)
 			var customErr *flyErrors.CustomError
@@ -0,0 +1,258 @@
+package farm
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/services/interfaces"
+)
+
+// Compile-time interface check.
+var _ interfaces.FarmService = (*Service)(nil)
+
+// A skill extractor removes extractorSP, and can't take a character below
+// extractionFloorSP.
+const (
+	extractionFloorSP = 5_000_000
+	extractorSP       = 500_000
+)
+
+// monitorInterval is how often Start checks the farms.
+const monitorInterval = 5 * time.Minute
+
+// WebSocket update types for farm alerts. Both carry the model.FarmCharacter.
+const (
+	alertQueueEmpty      = "farm:queue-empty"
+	alertExtractionReady = "farm:extraction-ready"
+)
+
+// farmState is what the last check saw of a farm character, so alerts are
+// only raised when something changes.
+type farmState struct {
+	queueEmpty     bool
+	extractions    int
+	nextExtraction *time.Time
+}
+
+// Service implements interfaces.FarmService.
+type Service struct {
+	logger      interfaces.Logger
+	accounts    interfaces.AccountManagementService
+	broadcaster interfaces.Broadcaster
+	now         func() time.Time
+
+	mu       sync.Mutex
+	states   map[int64]farmState
+	done     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewService constructs a FarmService.
+func NewService(logger interfaces.Logger, accounts interfaces.AccountManagementService, broadcaster interfaces.Broadcaster) *Service {
+	return &Service{
+		logger:      logger,
+		accounts:    accounts,
+		broadcaster: broadcaster,
+		now:         time.Now,
+		states:      make(map[int64]farmState),
+		done:        make(chan struct{}),
+	}
+}
+
+// Start checks the farms every few minutes, raising alerts, until Shutdown.
+func (s *Service) Start() {
+	s.wg.Add(1)
+	go s.monitor(monitorInterval)
+}
+
+func (s *Service) monitor(interval time.Duration) {
+	defer s.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			s.logger.Info("Farm monitor shutting down")
+			return
+		case <-ticker.C:
+			if _, err := s.GetFarmSummary(); err != nil {
+				s.logger.Warnf("Farm check failed: %v", err)
+			}
+		}
+	}
+}
+
+// Shutdown stops the farm monitor and waits for a check in progress to
+// finish, giving up with ctx's error once ctx is done.
+func (s *Service) Shutdown(ctx context.Context) error {
+	s.stopOnce.Do(func() { close(s.done) })
+
+	stopped := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// GetFarmSummary reports every farm character's extraction state, totalled
+// across accounts. An alert is broadcast when a farm's queue has emptied or
+// it can use more extractors than at the previous check.
+func (s *Service) GetFarmSummary() (*model.FarmSummary, error) {
+	accounts, err := s.accounts.FetchAccounts()
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	summary := &model.FarmSummary{Characters: []model.FarmCharacter{}}
+	for _, account := range accounts {
+		for _, identity := range account.Characters {
+			if !identity.Farm {
+				continue
+			}
+			farmCharacter := trackCharacter(account.Name, identity.Character, now)
+			summary.Characters = append(summary.Characters, farmCharacter)
+			summary.TotalSP += farmCharacter.TotalSP
+			summary.ExtractableSP += farmCharacter.ExtractableSP
+			summary.Extractions += farmCharacter.Extractions
+			if farmCharacter.QueueEmpty {
+				summary.EmptyQueues++
+			}
+		}
+	}
+
+	s.raiseAlerts(summary.Characters, now)
+	return summary, nil
+}
+
+// raiseAlerts compares the farm characters with the previous check. A queue
+// found empty on the first check is alerted too, as it needs attention. An
+// extraction is alerted once the time projected for it has passed, whether or
+// not the character has been refreshed since.
+func (s *Service) raiseAlerts(characters []model.FarmCharacter, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	states := make(map[int64]farmState, len(characters))
+	for _, character := range characters {
+		previous, seen := s.states[character.CharacterID]
+		if character.QueueEmpty && (!seen || !previous.queueEmpty) {
+			s.broadcaster.BroadcastUpdate(alertQueueEmpty, character)
+		}
+		state := farmState{
+			queueEmpty:     character.QueueEmpty,
+			extractions:    character.Extractions,
+			nextExtraction: character.NextExtractionAt,
+		}
+		extractionDue := previous.nextExtraction != nil && !now.Before(*previous.nextExtraction)
+		if seen && (character.Extractions > previous.extractions || extractionDue) {
+			s.broadcaster.BroadcastUpdate(alertExtractionReady, character)
+			// Don't alert the same extraction again if rounding leaves the
+			// count behind the projection.
+			state.extractions = max(state.extractions, previous.extractions+1)
+		}
+		states[character.CharacterID] = state
+	}
+	s.states = states
+}
+
+// trackCharacter works out a farm character's extractions from its SP now:
+// its total SP at the last refresh plus what its skill queue has trained
+// since. The next extraction is projected along the rest of the queue.
+func trackCharacter(accountName string, character model.Character, now time.Time) model.FarmCharacter {
+	currentSP := currentSkillPoints(character, now)
+	farmCharacter := model.FarmCharacter{
+		CharacterID:   character.CharacterID,
+		CharacterName: character.CharacterName,
+		AccountName:   accountName,
+		TotalSP:       currentSP,
+		QueueEmpty:    true,
+	}
+	if currentSP > extractionFloorSP {
+		farmCharacter.Extractions = int((currentSP - extractionFloorSP) / extractorSP)
+	}
+	farmCharacter.ExtractableSP = int64(farmCharacter.Extractions) * extractorSP
+
+	queue := make([]model.SkillQueue, len(character.SkillQueue))
+	copy(queue, character.SkillQueue)
+	sort.SliceStable(queue, func(i, j int) bool { return queue[i].QueuePosition < queue[j].QueuePosition })
+
+	needed := float64(extractionFloorSP + int64(farmCharacter.Extractions+1)*extractorSP - currentSP)
+	var trained float64
+	for _, entry := range queue {
+		if entry.StartDate == nil || entry.FinishDate == nil || !entry.FinishDate.After(now) {
+			continue
+		}
+		farmCharacter.QueueEmpty = false
+		if farmCharacter.QueueFinishDate == nil || entry.FinishDate.After(*farmCharacter.QueueFinishDate) {
+			finish := *entry.FinishDate
+			farmCharacter.QueueFinishDate = &finish
+		}
+
+		rate := spPerSecond(entry)
+		start := *entry.StartDate
+		if !start.After(now) {
+			start = now
+			farmCharacter.SPPerHour = rate * 3600
+		}
+		if farmCharacter.NextExtractionAt != nil || rate <= 0 {
+			continue
+		}
+		gained := rate * entry.FinishDate.Sub(start).Seconds()
+		if trained+gained >= needed {
+			at := start.Add(time.Duration((needed - trained) / rate * float64(time.Second)))
+			farmCharacter.NextExtractionAt = &at
+		}
+		trained += gained
+	}
+	return farmCharacter
+}
+
+// currentSkillPoints is the character's total SP at now. For each skill in
+// the queue, the SP it has reached by now (its training start SP plus its
+// rate times the time since it started) replaces the SP recorded for it at
+// the last refresh, when that is higher. Skills missing from the skills list
+// are left out, as there is nothing to compare them with.
+func currentSkillPoints(character model.Character, now time.Time) int64 {
+	reached := make(map[int32]int64)
+	for _, entry := range character.SkillQueue {
+		if entry.StartDate == nil || entry.FinishDate == nil || entry.StartDate.After(now) {
+			continue
+		}
+		end := now
+		if entry.FinishDate.Before(end) {
+			end = *entry.FinishDate
+		}
+		sp := int64(max(entry.TrainingStartSP, entry.LevelStartSP)) + int64(spPerSecond(entry)*end.Sub(*entry.StartDate).Seconds())
+		reached[entry.SkillID] = max(reached[entry.SkillID], min(sp, int64(entry.LevelEndSP)))
+	}
+
+	total := character.TotalSP
+	for _, skill := range character.Skills {
+		if sp, ok := reached[skill.SkillID]; ok {
+			total += max(sp-skill.SkillpointsInSkill, 0)
+		}
+	}
+	return total
+}
+
+// spPerSecond is the training rate of a skill queue entry.
+func spPerSecond(entry model.SkillQueue) float64 {
+	seconds := entry.FinishDate.Sub(*entry.StartDate).Seconds()
+	sp := entry.LevelEndSP - max(entry.TrainingStartSP, entry.LevelStartSP)
+	if seconds <= 0 || sp <= 0 {
+		return 0
+	}
+	return float64(sp) / seconds
+}
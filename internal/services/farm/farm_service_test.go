@@ -0,0 +1,154 @@
+package farm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/testutil"
+)
+
+var farmNow = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func at(hours int) *time.Time {
+	t := farmNow.Add(time.Duration(hours) * time.Hour)
+	return &t
+}
+
+func farmIdentity(id int64, name string, totalSP int64, queue ...model.SkillQueue) model.CharacterIdentity {
+	var c model.Character
+	c.CharacterID = id
+	c.CharacterName = name
+	c.TotalSP = totalSP
+	c.SkillQueue = queue
+	return model.CharacterIdentity{Character: c, Farm: true}
+}
+
+func TestTrackCharacter(t *testing.T) {
+	// Both entries train at 1 SP per second; the first started an hour ago,
+	// when the character was last refreshed.
+	identity := farmIdentity(1, "Farm", 6_200_000,
+		model.SkillQueue{SkillID: 2, QueuePosition: 1, StartDate: at(9), FinishDate: at(109), LevelStartSP: 0, LevelEndSP: 360_000},
+		model.SkillQueue{SkillID: 1, QueuePosition: 0, StartDate: at(-1), FinishDate: at(9), TrainingStartSP: 4_000, LevelEndSP: 40_000},
+	)
+	identity.Character.Skills = []model.SkillResponse{{SkillID: 1, SkillpointsInSkill: 4_000}, {SkillID: 2}}
+
+	farmCharacter := trackCharacter("Farms", identity.Character, farmNow)
+
+	assert.Equal(t, int64(6_203_600), farmCharacter.TotalSP, "the hour trained since the refresh counts")
+	assert.Equal(t, 2, farmCharacter.Extractions)
+	assert.Equal(t, int64(1_000_000), farmCharacter.ExtractableSP)
+	assert.Equal(t, 3600.0, farmCharacter.SPPerHour)
+	assert.False(t, farmCharacter.QueueEmpty)
+	assert.Equal(t, at(109), farmCharacter.QueueFinishDate)
+	// 296,400 SP to 6.5M: 32,400 from the rest of the first entry, 264,000 from the second.
+	require.NotNil(t, farmCharacter.NextExtractionAt)
+	assert.Equal(t, at(9).Add(264_000*time.Second), *farmCharacter.NextExtractionAt)
+}
+
+func TestTrackCharacter_BelowFloorWithShortQueue(t *testing.T) {
+	identity := farmIdentity(1, "Farm", 4_900_000,
+		model.SkillQueue{StartDate: at(-1), FinishDate: at(1), LevelEndSP: 7_200},
+	)
+
+	farmCharacter := trackCharacter("Farms", identity.Character, farmNow)
+
+	assert.Zero(t, farmCharacter.Extractions)
+	assert.Zero(t, farmCharacter.ExtractableSP)
+	assert.Nil(t, farmCharacter.NextExtractionAt, "the queue runs out before 5.5M")
+}
+
+func TestGetFarmSummary_Alerts(t *testing.T) {
+	training := model.SkillQueue{StartDate: at(-1), FinishDate: at(100), LevelEndSP: 360_000}
+	mainCharacter := farmIdentity(3, "Main", 90_000_000)
+	mainCharacter.Farm = false
+	first := []model.Account{
+		{Name: "Farms", Characters: []model.CharacterIdentity{
+			farmIdentity(1, "Idle", 7_000_000),
+			farmIdentity(2, "Busy", 5_400_000, training),
+		}},
+		{Name: "Main", Characters: []model.CharacterIdentity{mainCharacter}},
+	}
+	second := []model.Account{
+		{Name: "Farms", Characters: []model.CharacterIdentity{
+			farmIdentity(1, "Idle", 7_000_000),
+			farmIdentity(2, "Busy", 5_500_000, training),
+		}},
+	}
+
+	accounts := &testutil.MockAccountManagementService{}
+	accounts.On("FetchAccounts").Return(first, nil).Once()
+	accounts.On("FetchAccounts").Return(second, nil).Once()
+	broadcaster := &testutil.MockBroadcaster{}
+	broadcaster.On("BroadcastUpdate", alertQueueEmpty, mock.MatchedBy(func(c model.FarmCharacter) bool {
+		return c.CharacterName == "Idle"
+	})).Once()
+	broadcaster.On("BroadcastUpdate", alertExtractionReady, mock.MatchedBy(func(c model.FarmCharacter) bool {
+		return c.CharacterName == "Busy"
+	})).Once()
+	s := NewService(&testutil.MockLogger{}, accounts, broadcaster)
+	s.now = func() time.Time { return farmNow }
+
+	summary, err := s.GetFarmSummary()
+	require.NoError(t, err)
+	require.Len(t, summary.Characters, 2, "only farm characters are tracked")
+	assert.Equal(t, int64(12_400_000), summary.TotalSP)
+	assert.Equal(t, int64(2_000_000), summary.ExtractableSP)
+	assert.Equal(t, 4, summary.Extractions)
+	assert.Equal(t, 1, summary.EmptyQueues)
+
+	// Idle's queue was already empty; Busy has reached its first extraction.
+	summary, err = s.GetFarmSummary()
+	require.NoError(t, err)
+	assert.Equal(t, 5, summary.Extractions)
+
+	broadcaster.AssertExpectations(t)
+}
+
+func TestGetFarmSummary_AlertsWithoutRefresh(t *testing.T) {
+	// Refreshed at farmNow, 1,000 SP short of an extraction at 1 SP per second.
+	identity := farmIdentity(1, "Farm", 5_499_000,
+		model.SkillQueue{SkillID: 1, StartDate: at(0), FinishDate: at(100), LevelEndSP: 360_000},
+	)
+	identity.Character.Skills = []model.SkillResponse{{SkillID: 1}}
+	accounts := &testutil.MockAccountManagementService{}
+	accounts.On("FetchAccounts").Return([]model.Account{{Name: "Farms", Characters: []model.CharacterIdentity{identity}}}, nil)
+	broadcaster := &testutil.MockBroadcaster{}
+	broadcaster.On("BroadcastUpdate", alertExtractionReady, mock.Anything).Once()
+	s := NewService(&testutil.MockLogger{}, accounts, broadcaster)
+
+	now := farmNow
+	s.now = func() time.Time { return now }
+	summary, err := s.GetFarmSummary()
+	require.NoError(t, err)
+	require.NotNil(t, summary.Characters[0].NextExtractionAt)
+	assert.Equal(t, farmNow.Add(1_000*time.Second), *summary.Characters[0].NextExtractionAt)
+	broadcaster.AssertNotCalled(t, "BroadcastUpdate", alertExtractionReady, mock.Anything)
+
+	// Later checks see the same stored data; by the third the SP is there.
+	now = farmNow.Add(10 * time.Minute)
+	_, err = s.GetFarmSummary()
+	require.NoError(t, err)
+	broadcaster.AssertNotCalled(t, "BroadcastUpdate", alertExtractionReady, mock.Anything)
+	now = farmNow.Add(20 * time.Minute)
+	summary, err = s.GetFarmSummary()
+	require.NoError(t, err)
+	assert.Equal(t, 1, summary.Extractions)
+
+	broadcaster.AssertExpectations(t)
+}
+
+func TestShutdown_StopsMonitor(t *testing.T) {
+	s := NewService(&testutil.MockLogger{}, &testutil.MockAccountManagementService{}, &testutil.MockBroadcaster{})
+	s.Start()
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, s.Shutdown(ctx))
+	require.NoError(t, s.Shutdown(ctx), "a second Shutdown is harmless")
+}
@@ -17,12 +17,15 @@ import (
 	"sync"
 	"time"
 
+	"github.com/guarzo/canifly/internal/model"
 	"github.com/guarzo/canifly/internal/services/interfaces"
 )
 
 const (
 	FuzzworkInvTypesURL     = "https://www.fuzzwork.co.uk/dump/latest/invTypes.csv.bz2"
 	FuzzworkSolarSystemsURL = "https://www.fuzzwork.co.uk/dump/latest/mapSolarSystems.csv.bz2"
+	FuzzworkTypeAttrsURL    = "https://www.fuzzwork.co.uk/dump/latest/dgmTypeAttributes.csv.bz2"
+	CloneGradesURL          = "https://sde.hoboleaks.space/tq/clonegrades.json"
 	MaxRetries              = 3
 	RequestTimeout          = 60 * time.Second
 	MetadataFile            = "fuzzworks_metadata.json"
@@ -31,8 +34,10 @@ const (
 type DataType string
 
 const (
-	InvTypes     DataType = "invTypes"
-	SolarSystems DataType = "solarSystems"
+	InvTypes       DataType = "invTypes"
+	SolarSystems   DataType = "solarSystems"
+	TypeAttributes DataType = "typeAttributes"
+	CloneGrades    DataType = "cloneGrades"
 )
 
 type FileMetadata struct {
@@ -44,8 +49,10 @@ type FileMetadata struct {
 }
 
 type Metadata struct {
-	InvTypes     *FileMetadata `json:"inv_types"`
-	SolarSystems *FileMetadata `json:"solar_systems"`
+	InvTypes       *FileMetadata `json:"inv_types"`
+	SolarSystems   *FileMetadata `json:"solar_systems"`
+	TypeAttributes *FileMetadata `json:"type_attributes"`
+	CloneGrades    *FileMetadata `json:"clone_grades,omitempty"`
 }
 
 type Service struct {
@@ -140,6 +147,27 @@ func (s *Service) UpdateData(ctx context.Context) error {
 		}
 	}()
 
+	// Download dogma type attributes (required skills, skill ranks)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := s.updateFile(ctx, TypeAttributes, FuzzworkTypeAttrsURL, "dgmTypeAttributes.csv"); err != nil {
+			errMux.Lock()
+			errors = append(errors, fmt.Errorf("typeAttributes update failed: %w", err))
+			errMux.Unlock()
+		}
+	}()
+
+	// Download Alpha clone skill caps. They are not part of the Fuzzworks dump
+	// and are optional: without them Alpha accounts are evaluated uncapped.
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := s.updateFile(ctx, CloneGrades, CloneGradesURL, "cloneGrades.json"); err != nil {
+			s.logger.Warnf("cloneGrades update failed: %v", err)
+		}
+	}()
+
 	wg.Wait()
 
 	if len(errors) > 0 {
@@ -260,9 +288,12 @@ func (s *Service) download(ctx context.Context, url string, existingETag string)
 		return nil, nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
 	}
 
-	// Read and decompress
-	bzReader := bzip2.NewReader(resp.Body)
-	data, err := io.ReadAll(bzReader)
+	// Read and decompress; only the Fuzzworks dumps are bzip2 compressed
+	var body io.Reader = resp.Body
+	if strings.HasSuffix(url, ".bz2") {
+		body = bzip2.NewReader(resp.Body)
+	}
+	data, err := io.ReadAll(body)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to decompress: %w", err)
 	}
@@ -283,6 +314,10 @@ func (s *Service) download(ctx context.Context, url string, existingETag string)
 }
 
 func (s *Service) validateData(dataType DataType, data []byte) error {
+	if dataType == CloneGrades {
+		return s.validateCloneGrades(data)
+	}
+
 	reader := csv.NewReader(strings.NewReader(string(data)))
 
 	// Read header
@@ -297,6 +332,8 @@ func (s *Service) validateData(dataType DataType, data []byte) error {
 		return s.validateInvTypes(reader, header)
 	case SolarSystems:
 		return s.validateSolarSystems(reader, header)
+	case TypeAttributes:
+		return s.validateTypeAttributes(reader, header)
 	default:
 		return fmt.Errorf("unknown data type: %s", dataType)
 	}
@@ -383,6 +420,60 @@ func (s *Service) validateSolarSystems(reader *csv.Reader, header []string) erro
 	return nil
 }
 
+func (s *Service) validateTypeAttributes(reader *csv.Reader, header []string) error {
+	// Check required columns
+	requiredCols := []string{"typeID", "attributeID", "valueInt", "valueFloat"}
+	if !hasRequiredColumns(header, requiredCols) {
+		return fmt.Errorf("missing required columns in typeAttributes")
+	}
+
+	// Count rows and check for a known skill requirement
+	rowCount := 0
+	foundRequiredSkill := false
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			continue
+		}
+
+		rowCount++
+
+		// requiredSkill1 (attribute 182) is set on practically every ship and module
+		if len(record) > 1 && record[1] == "182" {
+			foundRequiredSkill = true
+		}
+	}
+
+	if rowCount < 100000 {
+		return fmt.Errorf("insufficient data: only %d type attributes found", rowCount)
+	}
+
+	if !foundRequiredSkill {
+		return fmt.Errorf("validation failed: required skill attributes not found")
+	}
+
+	return nil
+}
+
+func (s *Service) validateCloneGrades(data []byte) error {
+	var grades map[string]model.CloneGrade
+	if err := json.Unmarshal(data, &grades); err != nil {
+		return fmt.Errorf("failed to parse clone grades: %w", err)
+	}
+
+	// Every Alpha clone grade can train well over a hundred skills
+	for _, grade := range grades {
+		if len(grade.Skills) >= 100 {
+			return nil
+		}
+	}
+	return fmt.Errorf("validation failed: no clone grade with skill caps found")
+}
+
 func (s *Service) needsUpdate(dataType DataType, url string) bool {
 	s.metadataMux.RLock()
 	defer s.metadataMux.RUnlock()
@@ -393,6 +484,10 @@ func (s *Service) needsUpdate(dataType DataType, url string) bool {
 		metadata = s.metadata.InvTypes
 	case SolarSystems:
 		metadata = s.metadata.SolarSystems
+	case TypeAttributes:
+		metadata = s.metadata.TypeAttributes
+	case CloneGrades:
+		metadata = s.metadata.CloneGrades
 	}
 
 	if metadata == nil {
@@ -416,6 +511,10 @@ func (s *Service) getFilename(dataType DataType) string {
 		return "invTypes.csv"
 	case SolarSystems:
 		return "mapSolarSystems.csv"
+	case TypeAttributes:
+		return "dgmTypeAttributes.csv"
+	case CloneGrades:
+		return "cloneGrades.json"
 	default:
 		return ""
 	}
@@ -434,6 +533,14 @@ func (s *Service) getExistingETag(url string) string {
 		if s.metadata.SolarSystems != nil {
 			return s.metadata.SolarSystems.ETag
 		}
+	case FuzzworkTypeAttrsURL:
+		if s.metadata.TypeAttributes != nil {
+			return s.metadata.TypeAttributes.ETag
+		}
+	case CloneGradesURL:
+		if s.metadata.CloneGrades != nil {
+			return s.metadata.CloneGrades.ETag
+		}
 	}
 	return ""
 }
@@ -447,6 +554,10 @@ func (s *Service) updateMetadata(dataType DataType, metadata *FileMetadata) {
 		s.metadata.InvTypes = metadata
 	case SolarSystems:
 		s.metadata.SolarSystems = metadata
+	case TypeAttributes:
+		s.metadata.TypeAttributes = metadata
+	case CloneGrades:
+		s.metadata.CloneGrades = metadata
 	}
 }
 
@@ -506,11 +617,13 @@ func (s *Service) createBackup(filePath string) error {
 func (s *Service) hasLocalFiles() bool {
 	invTypesPath := filepath.Join(s.dataPath, "invTypes.csv")
 	solarSystemsPath := filepath.Join(s.dataPath, "mapSolarSystems.csv")
+	typeAttributesPath := filepath.Join(s.dataPath, "dgmTypeAttributes.csv")
 
 	_, err1 := os.Stat(invTypesPath)
 	_, err2 := os.Stat(solarSystemsPath)
+	_, err3 := os.Stat(typeAttributesPath)
 
-	return err1 == nil && err2 == nil
+	return err1 == nil && err2 == nil && err3 == nil
 }
 
 func (s *Service) GetInvTypesPath() string {
@@ -521,6 +634,14 @@ func (s *Service) GetSolarSystemsPath() string {
 	return filepath.Join(s.dataPath, "mapSolarSystems.csv")
 }
 
+func (s *Service) GetTypeAttributesPath() string {
+	return filepath.Join(s.dataPath, "dgmTypeAttributes.csv")
+}
+
+func (s *Service) GetCloneGradesPath() string {
+	return filepath.Join(s.dataPath, "cloneGrades.json")
+}
+
 // ParseSolarSystemsCSV parses the downloaded solar systems CSV and returns ID->Name mapping
 func (s *Service) ParseSolarSystemsCSV() (map[int64]string, map[string]int64, error) {
 	filePath := s.GetSolarSystemsPath()
@@ -608,6 +729,17 @@ func (s *Service) validateLocalFiles() error {
 		return fmt.Errorf("mapSolarSystems.csv validation failed: %w", err)
 	}
 
+	// Validate dgmTypeAttributes.csv
+	typeAttributesPath := filepath.Join(s.dataPath, "dgmTypeAttributes.csv")
+	typeAttributesData, err := os.ReadFile(typeAttributesPath)
+	if err != nil {
+		return fmt.Errorf("failed to read dgmTypeAttributes.csv: %w", err)
+	}
+
+	if err := s.validateData(TypeAttributes, typeAttributesData); err != nil {
+		return fmt.Errorf("dgmTypeAttributes.csv validation failed: %w", err)
+	}
+
 	return nil
 }
 
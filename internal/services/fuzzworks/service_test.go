@@ -2,8 +2,10 @@ package fuzzworks
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/guarzo/canifly/internal/testutil"
@@ -96,6 +98,28 @@ func TestService_validateInvTypes(t *testing.T) {
 	}
 }
 
+func TestService_validateCloneGrades(t *testing.T) {
+	logger := &testutil.MockLogger{}
+	service := New(logger, "", false)
+
+	skills := make([]string, 0, 120)
+	for i := 0; i < 120; i++ {
+		skills = append(skills, fmt.Sprintf(`{"typeID": %d, "level": 4}`, 3300+i))
+	}
+	validData := []byte(`{"1": {"name": "Alpha Caldari", "skills": [` + strings.Join(skills, ",") + `]}}`)
+	if err := service.validateData(CloneGrades, validData); err != nil {
+		t.Errorf("Expected valid clone grades, got %v", err)
+	}
+
+	if err := service.validateData(CloneGrades, []byte(`{"1": {"name": "Alpha Caldari", "skills": []}}`)); err == nil {
+		t.Error("Expected error for clone grades without skills")
+	}
+
+	if err := service.validateData(CloneGrades, []byte(`typeID,level`)); err == nil {
+		t.Error("Expected error for non-JSON clone grades")
+	}
+}
+
 func TestService_needsUpdate(t *testing.T) {
 	logger := &testutil.MockLogger{}
 	service := New(logger, "", false)
@@ -0,0 +1,209 @@
+package injector
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	flyErrors "github.com/guarzo/canifly/internal/errors"
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/services/interfaces"
+)
+
+// Compile-time interface check.
+var _ interfaces.InjectorService = (*Service)(nil)
+
+// A small skill injector grants a fifth of a large one.
+const smallInjectorDivisor = 5
+
+// injectorTiers are the SP a large skill injector grants, which drops as the
+// character's total SP grows.
+var injectorTiers = []struct {
+	belowSP int64
+	grants  int64
+}{
+	{5_000_000, 500_000},
+	{50_000_000, 400_000},
+	{80_000_000, 300_000},
+}
+
+// largeInjectorSP is the SP a large injector grants at totalSP.
+func largeInjectorSP(totalSP int64) int64 {
+	for _, tier := range injectorTiers {
+		if totalSP < tier.belowSP {
+			return tier.grants
+		}
+	}
+	return 150_000
+}
+
+// Service implements interfaces.InjectorService.
+type Service struct {
+	logger     interfaces.Logger
+	skillPlans interfaces.SkillPlanService
+	config     interfaces.ConfigurationService
+}
+
+// NewService constructs an InjectorService.
+func NewService(logger interfaces.Logger, skillPlans interfaces.SkillPlanService, config interfaces.ConfigurationService) *Service {
+	return &Service{logger: logger, skillPlans: skillPlans, config: config}
+}
+
+// EstimateInjectors works out the injectors a character needs for a plan.
+func (s *Service) EstimateInjectors(accounts []model.Account, characterID int64, planName string) (*model.InjectorEstimate, error) {
+	for _, account := range accounts {
+		for _, identity := range account.Characters {
+			if identity.Character.CharacterID != characterID {
+				continue
+			}
+			return s.estimate(identity.Character, account.Status, planName, s.injectorPrices())
+		}
+	}
+	return nil, flyErrors.NewCustomError(http.StatusNotFound, fmt.Sprintf("character %d not found", characterID))
+}
+
+// RankInjectionCandidates estimates the injectors every character needs for a
+// plan, cheapest first. With both injector prices set, characters are ranked
+// by cost; otherwise by injectors used, a small injector counting as a fifth
+// of a large one. Characters that need Omega for the plan come last.
+func (s *Service) RankInjectionCandidates(accounts []model.Account, planName string) ([]model.InjectorEstimate, error) {
+	if _, exists := s.skillPlans.GetSkillPlans()[planName]; !exists {
+		return nil, flyErrors.NewCustomError(http.StatusNotFound, fmt.Sprintf("skill plan %s not found", planName))
+	}
+	prices := s.injectorPrices()
+
+	estimates := []model.InjectorEstimate{}
+	for _, account := range accounts {
+		for _, identity := range account.Characters {
+			estimate, err := s.estimate(identity.Character, account.Status, planName, prices)
+			if err != nil {
+				return nil, err
+			}
+			estimates = append(estimates, *estimate)
+		}
+	}
+
+	priced := prices.Large > 0 && prices.Small > 0
+	sort.SliceStable(estimates, func(i, j int) bool {
+		a, b := estimates[i], estimates[j]
+		if a.NeedsOmega != b.NeedsOmega {
+			return !a.NeedsOmega
+		}
+		if priced && *a.TotalCost != *b.TotalCost {
+			return *a.TotalCost < *b.TotalCost
+		}
+		if units, other := injectorUnits(a), injectorUnits(b); units != other {
+			return units < other
+		}
+		if a.RemainingTrainingSeconds != b.RemainingTrainingSeconds {
+			return a.RemainingTrainingSeconds < b.RemainingTrainingSeconds
+		}
+		return a.CharacterName < b.CharacterName
+	})
+	return estimates, nil
+}
+
+// estimate fills a character's plan gap with large injectors, then small
+// ones, as long as each fits in what is left, and works out the extra small
+// injector that would close the gap entirely. The plan's SP gap is the SP of
+// the levels still to train: queued levels train anyway, unallocated SP is
+// spent first and levels above the Alpha caps can't be trained at all.
+func (s *Service) estimate(character model.Character, status model.AccountStatus, planName string, prices model.InjectorPrices) (*model.InjectorEstimate, error) {
+	steps, err := s.skillPlans.PlanTrainingQueue(character, status, planName)
+	if err != nil {
+		return nil, err
+	}
+
+	estimate := &model.InjectorEstimate{
+		CharacterName: character.CharacterName,
+		PlanName:      planName,
+		// Injector tiers count unallocated SP toward the total.
+		TotalSP: character.TotalSP + int64(character.UnallocatedSP),
+	}
+	var training []model.TrainingStep
+	for _, step := range steps {
+		switch {
+		case step.OmegaOnly:
+			estimate.NeedsOmega = true
+		case !step.Queued:
+			estimate.SkillPointsNeeded += step.SkillPoints
+			training = append(training, step)
+		}
+	}
+	estimate.SkillPointsNeeded = max(estimate.SkillPointsNeeded-int64(character.UnallocatedSP), 0)
+
+	totalSP, remaining := estimate.TotalSP, estimate.SkillPointsNeeded
+	for grants := largeInjectorSP(totalSP); grants <= remaining; grants = largeInjectorSP(totalSP) {
+		estimate.LargeInjectors++
+		totalSP += grants
+		remaining -= grants
+	}
+	for grants := largeInjectorSP(totalSP) / smallInjectorDivisor; grants <= remaining; grants = largeInjectorSP(totalSP) / smallInjectorDivisor {
+		estimate.SmallInjectors++
+		totalSP += grants
+		remaining -= grants
+	}
+	estimate.InjectedSP = estimate.SkillPointsNeeded - remaining
+	estimate.RemainingSP = remaining
+	estimate.RemainingTrainingSeconds = trainingSecondsFor(training, remaining)
+	// What's left is less than one small injector grants, so one more closes it.
+	estimate.ClosingSmallInjectors = estimate.SmallInjectors
+	if remaining > 0 {
+		estimate.ClosingSmallInjectors++
+	}
+
+	estimate.TotalCost = injectorCost(estimate.LargeInjectors, estimate.SmallInjectors, prices)
+	estimate.ClosingCost = injectorCost(estimate.LargeInjectors, estimate.ClosingSmallInjectors, prices)
+	return estimate, nil
+}
+
+// injectorCost is the price of the given injectors, or nil if the price of
+// one of the kinds used isn't set.
+func injectorCost(large, small int, prices model.InjectorPrices) *float64 {
+	if (large > 0 && prices.Large <= 0) || (small > 0 && prices.Small <= 0) {
+		return nil
+	}
+	cost := float64(large)*prices.Large + float64(small)*prices.Small
+	return &cost
+}
+
+// trainingSecondsFor is how long the SP left after injecting takes to train.
+// Injected SP can go to any skill, so it is put into the slowest training and
+// the remainder is trained in the levels with the most SP per second.
+func trainingSecondsFor(steps []model.TrainingStep, remainingSP int64) int64 {
+	sort.SliceStable(steps, func(i, j int) bool {
+		return steps[i].TrainingSeconds*steps[j].SkillPoints < steps[j].TrainingSeconds*steps[i].SkillPoints
+	})
+	var seconds int64
+	for _, step := range steps {
+		if remainingSP <= 0 {
+			break
+		}
+		if step.SkillPoints == 0 {
+			continue
+		}
+		sp := min(step.SkillPoints, remainingSP)
+		seconds += (step.TrainingSeconds*sp + step.SkillPoints - 1) / step.SkillPoints
+		remainingSP -= sp
+	}
+	return seconds
+}
+
+// injectorUnits counts injectors in large injector equivalents.
+func injectorUnits(estimate model.InjectorEstimate) float64 {
+	return float64(estimate.LargeInjectors) + float64(estimate.SmallInjectors)/smallInjectorDivisor
+}
+
+// injectorPrices returns the configured injector prices. Estimates are still
+// useful without them, so a config that can't be read only leaves them uncosted.
+func (s *Service) injectorPrices() model.InjectorPrices {
+	configData, err := s.config.FetchConfigData()
+	if err != nil {
+		s.logger.Warnf("Failed to load injector prices: %v", err)
+		return model.InjectorPrices{}
+	}
+	if configData.InjectorPrices == nil {
+		return model.InjectorPrices{}
+	}
+	return *configData.InjectorPrices
+}
@@ -0,0 +1,136 @@
+package injector_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	flyErrors "github.com/guarzo/canifly/internal/errors"
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/services/injector"
+	"github.com/guarzo/canifly/internal/testutil"
+)
+
+func newTestService(prices *model.InjectorPrices) (*injector.Service, *testutil.MockSkillService) {
+	skillPlans := &testutil.MockSkillService{}
+	skillPlans.On("GetSkillPlans").Return(map[string]model.SkillPlan{"Cruiser": {Name: "Cruiser"}})
+	config := &testutil.MockConfigService{}
+	config.On("FetchConfigData").Return(&model.ConfigData{InjectorPrices: prices}, nil)
+	return injector.NewService(&testutil.MockLogger{}, skillPlans, config), skillPlans
+}
+
+// expectQueue makes PlanTrainingQueue return steps for a character's Cruiser plan.
+func expectQueue(skillPlans *testutil.MockSkillService, characterName string, steps ...model.TrainingStep) {
+	skillPlans.On("PlanTrainingQueue", mock.MatchedBy(func(c model.Character) bool {
+		return c.CharacterName == characterName
+	}), mock.Anything, "Cruiser").Return(steps, nil)
+}
+
+func character(id int64, name string, totalSP int64, unallocatedSP int32) model.CharacterIdentity {
+	var c model.Character
+	c.CharacterID = id
+	c.CharacterName = name
+	c.TotalSP = totalSP
+	c.UnallocatedSP = unallocatedSP
+	return model.CharacterIdentity{Character: c}
+}
+
+func TestEstimateInjectors_CrossesTiers(t *testing.T) {
+	s, skillPlans := newTestService(&model.InjectorPrices{Large: 800_000_000, Small: 170_000_000})
+	expectQueue(skillPlans, "Pilot", model.TrainingStep{SkillName: "Caldari Cruiser", Level: 5, SkillPoints: 1_000_000, TrainingSeconds: 1_000_000})
+	accounts := []model.Account{{Characters: []model.CharacterIdentity{character(1, "Pilot", 4_800_000, 0)}}}
+
+	estimate, err := s.EstimateInjectors(accounts, 1, "Cruiser")
+	require.NoError(t, err)
+
+	// 500k below 5M SP, then 400k; the last 100k takes one 80k small injector.
+	assert.Equal(t, int64(1_000_000), estimate.SkillPointsNeeded)
+	assert.Equal(t, 2, estimate.LargeInjectors)
+	assert.Equal(t, 1, estimate.SmallInjectors)
+	assert.Equal(t, int64(980_000), estimate.InjectedSP)
+	assert.Equal(t, int64(20_000), estimate.RemainingSP)
+	assert.Equal(t, int64(20_000), estimate.RemainingTrainingSeconds)
+	require.NotNil(t, estimate.TotalCost)
+	assert.Equal(t, 1_770_000_000.0, *estimate.TotalCost)
+	// A second small injector closes the gap, overshooting it by 60k.
+	assert.Equal(t, 2, estimate.ClosingSmallInjectors)
+	require.NotNil(t, estimate.ClosingCost)
+	assert.Equal(t, 1_940_000_000.0, *estimate.ClosingCost)
+}
+
+func TestEstimateInjectors_SkipsQueuedAndUnallocated(t *testing.T) {
+	s, skillPlans := newTestService(nil)
+	expectQueue(skillPlans, "Pilot",
+		model.TrainingStep{SkillName: "Spaceship Command", Level: 4, SkillPoints: 100_000, Queued: true},
+		model.TrainingStep{SkillName: "Caldari Cruiser", Level: 5, SkillPoints: 300_000, TrainingSeconds: 300_000},
+		model.TrainingStep{SkillName: "Heavy Assault Cruisers", Level: 1, SkillPoints: 200_000, TrainingSeconds: 100_000},
+		model.TrainingStep{SkillName: "Heavy Assault Cruisers", Level: 2, OmegaOnly: true},
+	)
+	accounts := []model.Account{{Characters: []model.CharacterIdentity{character(1, "Pilot", 10_000_000, 50_000)}}}
+
+	estimate, err := s.EstimateInjectors(accounts, 1, "Cruiser")
+	require.NoError(t, err)
+
+	assert.True(t, estimate.NeedsOmega)
+	assert.Equal(t, int64(10_050_000), estimate.TotalSP)
+	assert.Equal(t, int64(450_000), estimate.SkillPointsNeeded)
+	assert.Equal(t, 1, estimate.LargeInjectors)
+	assert.Equal(t, 0, estimate.SmallInjectors)
+	// The 50k left train in the faster skill, at 2 SP per second.
+	assert.Equal(t, int64(50_000), estimate.RemainingSP)
+	assert.Equal(t, int64(25_000), estimate.RemainingTrainingSeconds)
+	assert.Nil(t, estimate.TotalCost, "no prices are configured")
+	assert.Equal(t, 1, estimate.ClosingSmallInjectors)
+	assert.Nil(t, estimate.ClosingCost)
+}
+
+func TestEstimateInjectors_ExactFitNeedsNoClosingInjector(t *testing.T) {
+	s, skillPlans := newTestService(&model.InjectorPrices{Large: 100})
+	expectQueue(skillPlans, "Pilot", model.TrainingStep{SkillName: "Caldari Cruiser", Level: 5, SkillPoints: 500_000})
+	accounts := []model.Account{{Characters: []model.CharacterIdentity{character(1, "Pilot", 1_000_000, 0)}}}
+
+	estimate, err := s.EstimateInjectors(accounts, 1, "Cruiser")
+	require.NoError(t, err)
+
+	assert.Equal(t, int64(0), estimate.RemainingSP)
+	assert.Equal(t, 0, estimate.ClosingSmallInjectors)
+	require.NotNil(t, estimate.ClosingCost, "only large injectors are used, and their price is set")
+	assert.Equal(t, 100.0, *estimate.ClosingCost)
+}
+
+func TestRankInjectionCandidates(t *testing.T) {
+	s, skillPlans := newTestService(&model.InjectorPrices{Large: 100, Small: 30})
+	expectQueue(skillPlans, "Large", model.TrainingStep{SkillName: "Caldari Cruiser", Level: 5, SkillPoints: 500_000})
+	expectQueue(skillPlans, "Smalls", model.TrainingStep{SkillName: "Caldari Cruiser", Level: 4, SkillPoints: 200_000})
+	expectQueue(skillPlans, "Alpha", model.TrainingStep{SkillName: "Caldari Cruiser", Level: 5, OmegaOnly: true})
+	accounts := []model.Account{
+		{Characters: []model.CharacterIdentity{character(1, "Large", 1_000_000, 0), character(2, "Alpha", 1_000_000, 0)}},
+		{Characters: []model.CharacterIdentity{character(3, "Smalls", 1_000_000, 0)}},
+	}
+
+	estimates, err := s.RankInjectionCandidates(accounts, "Cruiser")
+	require.NoError(t, err)
+
+	require.Len(t, estimates, 3)
+	assert.Equal(t, "Smalls", estimates[0].CharacterName)
+	assert.Equal(t, 60.0, *estimates[0].TotalCost)
+	assert.Equal(t, "Large", estimates[1].CharacterName)
+	assert.Equal(t, 100.0, *estimates[1].TotalCost)
+	assert.Equal(t, "Alpha", estimates[2].CharacterName)
+}
+
+func TestInjectors_NotFound(t *testing.T) {
+	s, _ := newTestService(nil)
+
+	_, err := s.RankInjectionCandidates(nil, "Battleship")
+	var customErr *flyErrors.CustomError
+	require.ErrorAs(t, err, &customErr)
+	assert.Equal(t, http.StatusNotFound, customErr.StatusCode)
+
+	_, err = s.EstimateInjectors(nil, 42, "Cruiser")
+	require.ErrorAs(t, err, &customErr)
+	assert.Equal(t, http.StatusNotFound, customErr.StatusCode)
+}
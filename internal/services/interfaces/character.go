@@ -1,12 +1,18 @@
 package interfaces
 
-import "github.com/guarzo/canifly/internal/model"
+import (
+	"context"
+
+	"github.com/guarzo/canifly/internal/model"
+)
 
 // CharacterService handles character management operations
 type CharacterService interface {
-	ProcessIdentity(charIdentity *model.CharacterIdentity) (*model.CharacterIdentity, error)
+	ProcessIdentity(ctx context.Context, charIdentity *model.CharacterIdentity) (*model.CharacterIdentity, error)
 	DoesCharacterExist(characterID int64) (bool, *model.CharacterIdentity, error)
 	UpdateCharacter(characterID int64, update model.CharacterUpdate) error
 	RemoveCharacter(characterID int64) error
-	RefreshCharacterData(characterID int64) (bool, error)
+	RefreshCharacterData(ctx context.Context, characterID int64) (bool, error)
+	RefreshCharacterEndpoints(ctx context.Context, characterID int64, endpoints ...model.CharacterEndpoint) (bool, error)
+	RefreshAllCharacters(ctx context.Context, concurrency int, progress func(model.CharacterRefreshProgress)) (*model.BulkRefreshResult, error)
 }
@@ -0,0 +1,9 @@
+package interfaces
+
+import "github.com/guarzo/canifly/internal/model"
+
+// CloneService reports where every character's jump clones are and what
+// implants they carry.
+type CloneService interface {
+	GetCloneInventory() (*model.CloneInventory, error)
+}
@@ -17,6 +17,7 @@ type ConfigurationService interface {
 	BackupJSONFiles(backupDir string) error
 	FetchConfigData() (*model.ConfigData, error)
 	SaveRoles(roles []string) error
+	SaveInjectorPrices(prices model.InjectorPrices) error
 
 	// EVE Credentials Management
 	NeedsEVEConfiguration() (bool, error)
@@ -1,11 +1,14 @@
 package interfaces
 
 import (
+	"context"
 	"net/http"
 	"time"
 
 	"github.com/gorilla/sessions"
 	"golang.org/x/oauth2"
+
+	"github.com/guarzo/canifly/internal/model"
 )
 
 // Logger interface
@@ -42,8 +45,13 @@ type AuthClient interface {
 
 // EsiHttpClient interface
 type EsiHttpClient interface {
-	GetJSON(endpoint string, token *oauth2.Token, useCache bool, target interface{}) error
-	GetJSONFromURL(url string, token *oauth2.Token, useCache bool, target interface{}) error
+	GetJSON(ctx context.Context, endpoint string, token *oauth2.Token, useCache bool, target interface{}) error
+	GetJSONFromURL(ctx context.Context, url string, token *oauth2.Token, useCache bool, target interface{}) error
+}
+
+// ESIGovernor paces ESI calls to stay within ESI's error and rate limits
+type ESIGovernor interface {
+	State() model.ESIGovernorState
 }
 
 // LoginService interface
@@ -1,18 +1,25 @@
 package interfaces
 
 import (
+	"context"
+
 	"github.com/guarzo/canifly/internal/model"
 	"golang.org/x/oauth2"
 )
 
 // ESIAPIService handles all EVE ESI API operations
 type ESIAPIService interface {
-	GetUserInfo(token *oauth2.Token) (*model.UserInfoResponse, error)
-	GetCharacter(id string) (*model.CharacterResponse, error)
-	GetCharacterSkills(characterID int64, token *oauth2.Token) (*model.CharacterSkillsResponse, error)
-	GetCharacterSkillQueue(characterID int64, token *oauth2.Token) (*[]model.SkillQueue, error)
-	GetCharacterLocation(characterID int64, token *oauth2.Token) (int64, error)
-	ResolveCharacterNames(charIds []string) (map[string]string, error)
-	GetCorporation(id int64, token *oauth2.Token) (*model.Corporation, error)
-	GetAlliance(id int64, token *oauth2.Token) (*model.Alliance, error)
+	GetUserInfo(ctx context.Context, token *oauth2.Token) (*model.UserInfoResponse, error)
+	GetCharacter(ctx context.Context, id string) (*model.CharacterResponse, error)
+	GetCharacterSkills(ctx context.Context, characterID int64, token *oauth2.Token) (*model.CharacterSkillsResponse, error)
+	GetCharacterSkillQueue(ctx context.Context, characterID int64, token *oauth2.Token) (*[]model.SkillQueue, error)
+	GetCharacterLocation(ctx context.Context, characterID int64, token *oauth2.Token) (int64, error)
+	GetCharacterAttributes(ctx context.Context, characterID int64, token *oauth2.Token) (*model.CharacterAttributes, error)
+	GetCharacterImplants(ctx context.Context, characterID int64, token *oauth2.Token) ([]int32, error)
+	GetCharacterClones(ctx context.Context, characterID int64, token *oauth2.Token) (*model.CloneLocation, error)
+	GetStation(ctx context.Context, id int64) (*model.Station, error)
+	GetStructure(ctx context.Context, id int64, token *oauth2.Token) (*model.Structure, error)
+	ResolveCharacterNames(ctx context.Context, charIds []string) (map[string]string, error)
+	GetCorporation(ctx context.Context, id int64, token *oauth2.Token) (*model.Corporation, error)
+	GetAlliance(ctx context.Context, id int64, token *oauth2.Token) (*model.Alliance, error)
 }
@@ -2,6 +2,8 @@
 package interfaces
 
 import (
+	"context"
+
 	"github.com/guarzo/canifly/internal/model"
 	"golang.org/x/oauth2"
 )
@@ -10,9 +12,16 @@ type SkillRepository interface {
 	GetSkillPlans() map[string]model.SkillPlan
 	GetSkillPlanFile(name string) ([]byte, error)
 	GetSkillTypes() map[string]model.SkillType
-	SaveSkillPlan(planName string, skills map[string]model.Skill) error
+	SaveSkillPlan(planName string, content []byte, source model.PlanSource) error
+	GetSkillPlanHistory(planName string) ([]model.PlanVersion, error)
+	GetSkillPlanVersion(planName, versionID string) ([]byte, error)
 	DeleteSkillPlan(planName string) error
 	GetSkillTypeByID(id string) (model.SkillType, bool)
+	GetSkillPrerequisites(typeID int32) []model.SkillPrerequisite
+	GetSkillAttributes(typeID int32) (model.SkillAttributes, bool)
+	GetAttributeBonus(typeID int32) (model.CharacterAttributes, bool)
+	GetAlphaSkillCap(typeID int32) (int32, bool)
+	IsShipType(typeID int32) (bool, bool)
 	LoadSkillPlans() error
 }
 
@@ -39,13 +48,15 @@ type SystemRepository interface {
 }
 
 type ESIService interface {
-	GetUserInfo(token *oauth2.Token) (*model.UserInfoResponse, error)
-	GetCharacter(id string) (*model.CharacterResponse, error)
-	GetCharacterSkills(characterID int64, token *oauth2.Token) (*model.CharacterSkillsResponse, error)
-	GetCharacterSkillQueue(characterID int64, token *oauth2.Token) (*[]model.SkillQueue, error)
-	GetCharacterLocation(characterID int64, token *oauth2.Token) (int64, error)
-	ResolveCharacterNames(charIds []string) (map[string]string, error)
+	GetUserInfo(ctx context.Context, token *oauth2.Token) (*model.UserInfoResponse, error)
+	GetCharacter(ctx context.Context, id string) (*model.CharacterResponse, error)
+	GetCharacterSkills(ctx context.Context, characterID int64, token *oauth2.Token) (*model.CharacterSkillsResponse, error)
+	GetCharacterSkillQueue(ctx context.Context, characterID int64, token *oauth2.Token) (*[]model.SkillQueue, error)
+	GetCharacterLocation(ctx context.Context, characterID int64, token *oauth2.Token) (int64, error)
+	GetCharacterAttributes(ctx context.Context, characterID int64, token *oauth2.Token) (*model.CharacterAttributes, error)
+	GetCharacterImplants(ctx context.Context, characterID int64, token *oauth2.Token) ([]int32, error)
+	ResolveCharacterNames(ctx context.Context, charIds []string) (map[string]string, error)
 	SaveEsiCache() error
-	GetCorporation(id int64, token *oauth2.Token) (*model.Corporation, error)
-	GetAlliance(id int64, token *oauth2.Token) (*model.Alliance, error)
+	GetCorporation(ctx context.Context, id int64, token *oauth2.Token) (*model.Corporation, error)
+	GetAlliance(ctx context.Context, id int64, token *oauth2.Token) (*model.Alliance, error)
 }
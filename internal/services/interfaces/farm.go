@@ -0,0 +1,20 @@
+package interfaces
+
+import (
+	"context"
+
+	"github.com/guarzo/canifly/internal/model"
+)
+
+// Broadcaster pushes real-time updates to connected clients.
+type Broadcaster interface {
+	BroadcastUpdate(updateType string, data interface{})
+}
+
+// FarmService tracks skill farm characters and raises alerts when a farm needs
+// attention.
+type FarmService interface {
+	GetFarmSummary() (*model.FarmSummary, error)
+	Start()
+	Shutdown(ctx context.Context) error
+}
@@ -0,0 +1,9 @@
+package interfaces
+
+import "github.com/guarzo/canifly/internal/model"
+
+// InjectorService estimates the skill injectors that train characters into plans.
+type InjectorService interface {
+	EstimateInjectors(accounts []model.Account, characterID int64, planName string) (*model.InjectorEstimate, error)
+	RankInjectionCandidates(accounts []model.Account, planName string) ([]model.InjectorEstimate, error)
+}
@@ -0,0 +1,8 @@
+package interfaces
+
+import "github.com/guarzo/canifly/internal/model"
+
+// PlanningService assigns skill plans to characters across accounts
+type PlanningService interface {
+	AssignTraining(accounts []model.Account, request model.PlanningRequest) (*model.PlanningResult, error)
+}
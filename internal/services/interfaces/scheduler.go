@@ -0,0 +1,9 @@
+package interfaces
+
+import "context"
+
+// SchedulerService runs background refresh tasks until it is shut down.
+type SchedulerService interface {
+	Start()
+	Shutdown(ctx context.Context) error
+}
@@ -8,10 +8,28 @@ type SkillPlanService interface {
 	GetSkillName(id int32) string
 	GetSkillTypes() map[string]model.SkillType
 	CheckIfDuplicatePlan(name string) bool
-	ParseAndSaveSkillPlan(contents, name string) error
+	ParseAndSaveSkillPlan(contents, name string, source model.PlanSource) error
+	GetSkillPlanHistory(name string) ([]model.PlanVersion, error)
+	DiffSkillPlan(name, from, to string) (*model.PlanDiff, error)
+	RestoreSkillPlan(name, versionID string) error
+	RecommendNextSkills(character model.Character, status model.AccountStatus, request model.SkillRecommendationRequest) []model.SkillRecommendation
+	ValidateSkillPlan(name, contents string) model.PlanValidation
+	AuditSkillPlans() []model.PlanValidation
 	GetSkillPlanFile(name string) ([]byte, error)
 	DeleteSkillPlan(name string) error
 	GetSkillTypeByID(id string) (model.SkillType, bool)
+	GetExpandedSkillPlan(name string) (model.SkillPlan, bool)
+	GetRemapAdvice(character model.Character, status model.AccountStatus, planName string) (*model.RemapAdvice, error)
+	PlanFromFitting(fitting string) (model.SkillPlan, []string, error)
+	SaveFittingPlan(fitting, name string) (model.SkillPlan, []string, error)
+	EvaluateFitting(accounts []model.Account, fitting string) (*model.FittingEvaluation, error)
+	GetShipStatus(accounts []model.Account, typeName string) (*model.SkillPlanWithStatus, error)
+	ImportEVEMonPlan(data []byte, name string) (string, error)
+	ExportEVEMonPlan(name string) ([]byte, error)
+	ExportTextPlan(name string) ([]byte, error)
+	ExportSkillQueue(character model.Character, status model.AccountStatus, planName string) (string, error)
+	PlanTrainingQueue(character model.Character, status model.AccountStatus, planName string) ([]model.TrainingStep, error)
+	CompareTrainingScenario(character model.Character, status model.AccountStatus, planName string, scenario model.TrainingScenario) (*model.TrainingScenarioComparison, error)
 	GetPlanAndConversionData(accounts []model.Account, skillPlans map[string]model.SkillPlan, skillTypes map[string]model.SkillType) (map[string]model.SkillPlanWithStatus, map[string]string)
 	ListSkillPlans() ([]string, error)
 	RefreshRemotePlans() error
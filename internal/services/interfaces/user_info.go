@@ -1,6 +1,8 @@
 package interfaces
 
 import (
+	"context"
+
 	"golang.org/x/oauth2"
 
 	"github.com/guarzo/canifly/internal/model"
@@ -8,5 +10,5 @@ import (
 
 // UserInfoFetcher provides a minimal interface for fetching user info
 type UserInfoFetcher interface {
-	GetUserInfo(token *oauth2.Token) (*model.UserInfoResponse, error)
+	GetUserInfo(ctx context.Context, token *oauth2.Token) (*model.UserInfoResponse, error)
 }
@@ -0,0 +1,414 @@
+package planning
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	flyErrors "github.com/guarzo/canifly/internal/errors"
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/services/interfaces"
+)
+
+// Compile-time interface check.
+var _ interfaces.PlanningService = (*Service)(nil)
+
+// maxTrainingSlots is the most characters an account can train at once.
+const maxTrainingSlots = 3
+
+// Service implements interfaces.PlanningService.
+type Service struct {
+	logger     interfaces.Logger
+	skillPlans interfaces.SkillPlanService
+	now        func() time.Time
+}
+
+// NewService constructs a PlanningService.
+func NewService(logger interfaces.Logger, skillPlans interfaces.SkillPlanService) *Service {
+	return &Service{logger: logger, skillPlans: skillPlans, now: time.Now}
+}
+
+// plannedAccount is an account whose training slots are shared by its characters.
+type plannedAccount struct {
+	name       string
+	status     model.AccountStatus
+	slots      int
+	characters []*plannedCharacter
+}
+
+// plannedCharacter is a character's training state while plans are assigned.
+type plannedCharacter struct {
+	character   model.Character
+	account     *plannedAccount
+	queueEnd    time.Time        // when the current skill queue finishes
+	order       int              // slot priority within the account; -1 until given work
+	reached     map[string]int32 // highest level planned for each skill so far
+	workSeconds int64            // new training assigned
+	start       time.Time        // when new training starts, set by schedule
+	assignments []assignment
+	covers      map[int]bool // indices of the targets this character counts toward
+	queues      map[string][]model.TrainingStep
+}
+
+// assignment is a plan a character trains to count toward a target.
+type assignment struct {
+	target      int
+	plan        string
+	steps       []model.TrainingStep // new levels only, in training order
+	queuedReady time.Time            // when the plan's already queued levels finish
+	workBefore  int64                // seconds of earlier assignments
+}
+
+// AssignTraining greedily assigns plans to characters until every target has
+// enough characters, each time choosing the character and plan that would be
+// ready soonest. New training starts after a character's current queue, and an
+// account trains at most as many characters at once as it has slots.
+func (s *Service) AssignTraining(accounts []model.Account, request model.PlanningRequest) (*model.PlanningResult, error) {
+	targetPlans, err := s.resolveTargets(request)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now().UTC()
+	characters := planCharacters(accounts, request.TrainingSlots, now)
+
+	deficits := make([]int, len(request.Targets))
+	for i, target := range request.Targets {
+		deficits[i] = target.Count
+	}
+	nextOrder := 0
+	for _, c := range characters {
+		nextOrder = max(nextOrder, c.order+1)
+	}
+
+	for {
+		var best *plannedCharacter
+		var bestAssignment assignment
+		var bestReady time.Time
+		for targetIndex, plans := range targetPlans {
+			if deficits[targetIndex] == 0 {
+				continue
+			}
+			for _, c := range characters {
+				if c.covers[targetIndex] {
+					continue
+				}
+				for _, planName := range plans {
+					candidate, ok := s.candidate(c, targetIndex, planName)
+					if !ok {
+						continue
+					}
+					ready := c.tentativeReady(candidate, nextOrder, now)
+					if best == nil || betterCandidate(ready, candidate, c, bestReady, bestAssignment, best) {
+						best, bestAssignment, bestReady = c, candidate, ready
+					}
+				}
+			}
+		}
+		if best == nil {
+			break
+		}
+
+		if best.order == -1 && len(bestAssignment.steps) > 0 {
+			best.order = nextOrder
+			nextOrder++
+		}
+		bestAssignment.workBefore = best.workSeconds
+		for _, step := range bestAssignment.steps {
+			best.workSeconds += step.TrainingSeconds
+			best.reached[step.SkillName] = max(best.reached[step.SkillName], step.Level)
+		}
+		best.assignments = append(best.assignments, bestAssignment)
+		best.covers[bestAssignment.target] = true
+		deficits[bestAssignment.target]--
+	}
+
+	return buildResult(request, characters, now), nil
+}
+
+// resolveTargets validates the request and lists the plans that satisfy each
+// target, in name order.
+func (s *Service) resolveTargets(request model.PlanningRequest) ([][]string, error) {
+	if len(request.Targets) == 0 {
+		return nil, flyErrors.NewCustomError(http.StatusBadRequest, "at least one target is required")
+	}
+	for account, slots := range request.TrainingSlots {
+		if slots < 1 || slots > maxTrainingSlots {
+			return nil, flyErrors.NewCustomError(http.StatusBadRequest,
+				fmt.Sprintf("account %s must have between 1 and %d training slots", account, maxTrainingSlots))
+		}
+	}
+
+	plans := s.skillPlans.GetSkillPlans()
+	targetPlans := make([][]string, len(request.Targets))
+	for i, target := range request.Targets {
+		if target.Count < 1 {
+			return nil, flyErrors.NewCustomError(http.StatusBadRequest, "target count must be at least 1")
+		}
+		switch {
+		case target.Plan != "" && target.Role != "":
+			return nil, flyErrors.NewCustomError(http.StatusBadRequest, "a target sets either a plan or a role, not both")
+		case target.Plan != "":
+			if _, exists := plans[target.Plan]; !exists {
+				return nil, flyErrors.NewCustomError(http.StatusNotFound, fmt.Sprintf("skill plan %s not found", target.Plan))
+			}
+			targetPlans[i] = []string{target.Plan}
+		case target.Role != "":
+			for name, plan := range plans {
+				for _, role := range plan.Metadata.Roles {
+					if strings.EqualFold(role, target.Role) {
+						targetPlans[i] = append(targetPlans[i], name)
+						break
+					}
+				}
+			}
+			if len(targetPlans[i]) == 0 {
+				return nil, flyErrors.NewCustomError(http.StatusBadRequest, fmt.Sprintf("no skill plan has role %s", target.Role))
+			}
+			sort.Strings(targetPlans[i])
+		default:
+			return nil, flyErrors.NewCustomError(http.StatusBadRequest, "a target needs a plan or a role")
+		}
+	}
+	return targetPlans, nil
+}
+
+// planCharacters sets up every character with its account's training slots.
+// Characters that are training already hold a slot, in queue end order.
+func planCharacters(accounts []model.Account, trainingSlots map[string]int, now time.Time) []*plannedCharacter {
+	var characters []*plannedCharacter
+	for _, account := range accounts {
+		planned := &plannedAccount{name: account.Name, status: account.Status, slots: 1}
+		if slots, exists := trainingSlots[account.Name]; exists {
+			planned.slots = slots
+		}
+
+		for _, identity := range account.Characters {
+			c := &plannedCharacter{
+				character: identity.Character,
+				account:   planned,
+				queueEnd:  now,
+				order:     -1,
+				reached:   make(map[string]int32),
+				covers:    make(map[int]bool),
+				queues:    make(map[string][]model.TrainingStep),
+			}
+			for _, queued := range identity.Character.SkillQueue {
+				if queued.FinishDate != nil && queued.FinishDate.After(c.queueEnd) {
+					c.queueEnd = queued.FinishDate.UTC()
+				}
+			}
+			planned.characters = append(planned.characters, c)
+			characters = append(characters, c)
+		}
+
+		training := make([]*plannedCharacter, 0, len(planned.characters))
+		for _, c := range planned.characters {
+			if c.queueEnd.After(now) {
+				training = append(training, c)
+			}
+		}
+		sort.SliceStable(training, func(i, j int) bool { return training[i].queueEnd.Before(training[j].queueEnd) })
+		for i, c := range training {
+			c.order = i
+		}
+	}
+
+	sort.SliceStable(characters, func(i, j int) bool {
+		return characters[i].character.CharacterName < characters[j].character.CharacterName
+	})
+	return characters
+}
+
+// candidate is what a character would still train for a plan, given what it
+// has been assigned so far. Alpha characters cannot take plans that need
+// skills above the Alpha caps.
+func (s *Service) candidate(c *plannedCharacter, targetIndex int, planName string) (assignment, bool) {
+	steps, cached := c.queues[planName]
+	if !cached {
+		var err error
+		steps, err = s.skillPlans.PlanTrainingQueue(c.character, c.account.status, planName)
+		if err != nil {
+			s.logger.Warnf("Cannot plan %s for %s: %v", planName, c.character.CharacterName, err)
+			return assignment{}, false
+		}
+		c.queues[planName] = steps
+	}
+
+	candidate := assignment{target: targetIndex, plan: planName}
+	for _, step := range steps {
+		switch {
+		case step.OmegaOnly:
+			return assignment{}, false
+		case step.Queued && step.QueuedUntil != nil:
+			if step.QueuedUntil.After(candidate.queuedReady) {
+				candidate.queuedReady = step.QueuedUntil.UTC()
+			}
+		case step.Level > c.reached[step.SkillName]:
+			// A paused queue trains nothing, so its levels are new training.
+			candidate.steps = append(candidate.steps, step)
+		}
+	}
+	return candidate, true
+}
+
+// tentativeReady is when c would be ready for the candidate plan if it were
+// added to the end of c's schedule. A character that needs no new training for
+// it is ready once the plan's queued levels finish, without taking a slot.
+func (c *plannedCharacter) tentativeReady(candidate assignment, nextOrder int, now time.Time) time.Time {
+	if c.workSeconds == 0 && len(candidate.steps) == 0 {
+		return laterOf(now, candidate.queuedReady)
+	}
+	order, work := c.order, c.workSeconds
+	if c.order == -1 {
+		c.order = nextOrder
+	}
+	for _, step := range candidate.steps {
+		c.workSeconds += step.TrainingSeconds
+	}
+	c.account.schedule(now)
+	ready := c.start.Add(time.Duration(c.workSeconds) * time.Second)
+
+	c.order, c.workSeconds = order, work
+	c.account.schedule(now)
+	return laterOf(ready, candidate.queuedReady)
+}
+
+// schedule sets when each character's new training starts: characters take
+// the account's slots in order, each once both its slot is free and its own
+// current queue has finished.
+func (a *plannedAccount) schedule(now time.Time) {
+	active := make([]*plannedCharacter, 0, len(a.characters))
+	for _, c := range a.characters {
+		c.start = c.queueEnd
+		if c.order >= 0 {
+			active = append(active, c)
+		}
+	}
+	sort.Slice(active, func(i, j int) bool { return active[i].order < active[j].order })
+
+	slots := make([]time.Time, a.slots)
+	for i := range slots {
+		slots[i] = now
+	}
+	for _, c := range active {
+		slot := 0
+		for i := range slots {
+			if slots[i].Before(slots[slot]) {
+				slot = i
+			}
+		}
+		c.start = laterOf(slots[slot], c.queueEnd)
+		slots[slot] = c.start.Add(time.Duration(c.workSeconds) * time.Second)
+	}
+}
+
+// betterCandidate orders candidates by ready time, then least new training,
+// then character and plan name so results are deterministic.
+func betterCandidate(ready time.Time, candidate assignment, c *plannedCharacter, bestReady time.Time, best assignment, bestCharacter *plannedCharacter) bool {
+	if !ready.Equal(bestReady) {
+		return ready.Before(bestReady)
+	}
+	if work, bestWork := trainingSeconds(candidate.steps), trainingSeconds(best.steps); work != bestWork {
+		return work < bestWork
+	}
+	if c.character.CharacterName != bestCharacter.character.CharacterName {
+		return c.character.CharacterName < bestCharacter.character.CharacterName
+	}
+	return candidate.plan < best.plan
+}
+
+// buildResult lays out the final schedules and coverage dates.
+func buildResult(request model.PlanningRequest, characters []*plannedCharacter, now time.Time) *model.PlanningResult {
+	accounts := make(map[*plannedAccount]bool)
+	for _, c := range characters {
+		accounts[c.account] = true
+	}
+	for account := range accounts {
+		account.schedule(now)
+	}
+
+	result := &model.PlanningResult{
+		Coverage:  make([]model.TargetCoverage, len(request.Targets)),
+		Schedules: []model.CharacterSchedule{},
+	}
+	for i, target := range request.Targets {
+		result.Coverage[i] = model.TargetCoverage{Target: target, Characters: []model.CoverageAssignment{}}
+	}
+
+	for _, c := range characters {
+		for _, a := range c.assignments {
+			ready := now
+			if work := a.workBefore + trainingSeconds(a.steps); work > 0 {
+				ready = c.start.Add(time.Duration(work) * time.Second)
+			}
+			result.Coverage[a.target].Characters = append(result.Coverage[a.target].Characters, model.CoverageAssignment{
+				CharacterName: c.character.CharacterName,
+				PlanName:      a.plan,
+				ReadyAt:       laterOf(ready, a.queuedReady),
+			})
+		}
+		if c.workSeconds == 0 {
+			continue
+		}
+
+		schedule := model.CharacterSchedule{
+			CharacterName: c.character.CharacterName,
+			AccountName:   c.account.name,
+			Start:         c.start,
+		}
+		at := c.start
+		for _, a := range c.assignments {
+			for _, step := range a.steps {
+				finish := at.Add(time.Duration(step.TrainingSeconds) * time.Second)
+				schedule.Steps = append(schedule.Steps, model.ScheduledStep{
+					PlanName:  a.plan,
+					SkillName: step.SkillName,
+					Level:     step.Level,
+					Start:     at,
+					Finish:    finish,
+				})
+				at = finish
+			}
+		}
+		schedule.Finish = at
+		result.Schedules = append(result.Schedules, schedule)
+	}
+
+	allMet := true
+	var coveredAt time.Time
+	for i := range result.Coverage {
+		coverage := &result.Coverage[i]
+		sort.Slice(coverage.Characters, func(a, b int) bool {
+			return coverage.Characters[a].ReadyAt.Before(coverage.Characters[b].ReadyAt)
+		})
+		coverage.Met = len(coverage.Characters) >= coverage.Target.Count
+		if !coverage.Met {
+			allMet = false
+			continue
+		}
+		last := coverage.Characters[coverage.Target.Count-1].ReadyAt
+		coverage.CoveredAt = &last
+		coveredAt = laterOf(coveredAt, last)
+	}
+	if allMet {
+		result.CoveredAt = &coveredAt
+	}
+	return result
+}
+
+func trainingSeconds(steps []model.TrainingStep) int64 {
+	var total int64
+	for _, step := range steps {
+		total += step.TrainingSeconds
+	}
+	return total
+}
+
+func laterOf(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
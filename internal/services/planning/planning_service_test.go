@@ -0,0 +1,295 @@
+package planning
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	flyErrors "github.com/guarzo/canifly/internal/errors"
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/testutil"
+)
+
+var planningNow = time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestService(plans map[string]model.SkillPlan) (*Service, *testutil.MockSkillService) {
+	skillPlans := &testutil.MockSkillService{}
+	skillPlans.On("GetSkillPlans").Return(plans)
+	s := NewService(&testutil.MockLogger{}, skillPlans)
+	s.now = func() time.Time { return planningNow }
+	return s, skillPlans
+}
+
+// expectQueue makes PlanTrainingQueue return steps for a character and plan.
+func expectQueue(skillPlans *testutil.MockSkillService, characterName, planName string, steps ...model.TrainingStep) {
+	skillPlans.On("PlanTrainingQueue", mock.MatchedBy(func(c model.Character) bool {
+		return c.CharacterName == characterName
+	}), mock.Anything, planName).Return(steps, nil)
+}
+
+func step(skill string, level int32, hours int64) model.TrainingStep {
+	return model.TrainingStep{SkillName: skill, Level: level, TrainingSeconds: hours * 3600}
+}
+
+func account(name string, characters ...model.Character) model.Account {
+	account := model.Account{Name: name}
+	for _, character := range characters {
+		account.Characters = append(account.Characters, model.CharacterIdentity{Character: character})
+	}
+	return account
+}
+
+func character(name string) model.Character {
+	var c model.Character
+	c.CharacterName = name
+	return c
+}
+
+func hours(h int) time.Time {
+	return planningNow.Add(time.Duration(h) * time.Hour)
+}
+
+func TestAssignTraining_PicksFastestCharacter(t *testing.T) {
+	s, skillPlans := newTestService(map[string]model.SkillPlan{"Cruiser": {Name: "Cruiser"}})
+	expectQueue(skillPlans, "Alpha", "Cruiser", step("Gallente Cruiser", 3, 10))
+	expectQueue(skillPlans, "Bravo", "Cruiser", step("Gallente Cruiser", 3, 5))
+
+	result, err := s.AssignTraining(
+		[]model.Account{account("Main", character("Alpha"), character("Bravo"))},
+		model.PlanningRequest{Targets: []model.CoverageTarget{{Plan: "Cruiser", Count: 1}}},
+	)
+	require.NoError(t, err)
+
+	require.Len(t, result.Coverage, 1)
+	coverage := result.Coverage[0]
+	assert.True(t, coverage.Met)
+	require.Len(t, coverage.Characters, 1)
+	assert.Equal(t, "Bravo", coverage.Characters[0].CharacterName)
+	assert.Equal(t, hours(5), *coverage.CoveredAt)
+	require.NotNil(t, result.CoveredAt)
+	assert.Equal(t, hours(5), *result.CoveredAt)
+
+	require.Len(t, result.Schedules, 1)
+	assert.Equal(t, "Bravo", result.Schedules[0].CharacterName)
+	assert.Equal(t, "Main", result.Schedules[0].AccountName)
+}
+
+func TestAssignTraining_SharesAccountTrainingSlots(t *testing.T) {
+	plans := map[string]model.SkillPlan{"Cruiser": {Name: "Cruiser"}}
+	request := model.PlanningRequest{Targets: []model.CoverageTarget{{Plan: "Cruiser", Count: 2}}}
+	accounts := []model.Account{account("Main", character("Alpha"), character("Bravo"))}
+
+	s, skillPlans := newTestService(plans)
+	expectQueue(skillPlans, "Alpha", "Cruiser", step("Gallente Cruiser", 3, 10))
+	expectQueue(skillPlans, "Bravo", "Cruiser", step("Gallente Cruiser", 3, 5))
+
+	result, err := s.AssignTraining(accounts, request)
+	require.NoError(t, err)
+	require.NotNil(t, result.CoveredAt)
+	assert.Equal(t, hours(15), *result.CoveredAt, "one slot trains the characters one after the other")
+	require.Len(t, result.Schedules, 2)
+	assert.Equal(t, hours(5), result.Schedules[0].Start)
+	assert.Equal(t, "Alpha", result.Schedules[0].CharacterName)
+
+	request.TrainingSlots = map[string]int{"Main": 2}
+	result, err = s.AssignTraining(accounts, request)
+	require.NoError(t, err)
+	require.NotNil(t, result.CoveredAt)
+	assert.Equal(t, hours(10), *result.CoveredAt, "two slots train the characters together")
+}
+
+func TestAssignTraining_StartsAfterCurrentQueue(t *testing.T) {
+	s, skillPlans := newTestService(map[string]model.SkillPlan{"Cruiser": {Name: "Cruiser"}})
+	expectQueue(skillPlans, "Alpha", "Cruiser", step("Gallente Cruiser", 3, 1))
+	expectQueue(skillPlans, "Bravo", "Cruiser", step("Gallente Cruiser", 3, 5))
+
+	busy := character("Alpha")
+	queueEnd := hours(20)
+	busy.SkillQueue = []model.SkillQueue{{SkillID: 1, FinishDate: &queueEnd}}
+
+	result, err := s.AssignTraining(
+		[]model.Account{account("Main", busy), account("Second", character("Bravo"))},
+		model.PlanningRequest{Targets: []model.CoverageTarget{{Plan: "Cruiser", Count: 1}}},
+	)
+	require.NoError(t, err)
+	require.Len(t, result.Coverage[0].Characters, 1)
+	assert.Equal(t, "Bravo", result.Coverage[0].Characters[0].CharacterName)
+}
+
+func TestAssignTraining_QualifiedCharacterIsReadyNow(t *testing.T) {
+	s, skillPlans := newTestService(map[string]model.SkillPlan{"Cruiser": {Name: "Cruiser"}})
+	expectQueue(skillPlans, "Alpha", "Cruiser")
+	expectQueue(skillPlans, "Bravo", "Cruiser", step("Gallente Cruiser", 3, 5))
+
+	// Alpha already flies the plan; its queue trains something unrelated.
+	qualified := character("Alpha")
+	queueEnd := hours(20)
+	qualified.SkillQueue = []model.SkillQueue{{SkillID: 1, FinishDate: &queueEnd}}
+
+	result, err := s.AssignTraining(
+		[]model.Account{account("Main", qualified), account("Second", character("Bravo"))},
+		model.PlanningRequest{Targets: []model.CoverageTarget{{Plan: "Cruiser", Count: 1}}},
+	)
+	require.NoError(t, err)
+	require.Len(t, result.Coverage[0].Characters, 1)
+	assert.Equal(t, "Alpha", result.Coverage[0].Characters[0].CharacterName)
+	require.NotNil(t, result.CoveredAt)
+	assert.Equal(t, planningNow, *result.CoveredAt)
+	assert.Empty(t, result.Schedules)
+}
+
+func TestAssignTraining_QueuedLevelsAreNotRetrained(t *testing.T) {
+	s, skillPlans := newTestService(map[string]model.SkillPlan{"Cruiser": {Name: "Cruiser"}})
+	queuedUntil := hours(3)
+	queued := step("Gallente Cruiser", 2, 2)
+	queued.Queued, queued.QueuedUntil = true, &queuedUntil
+	expectQueue(skillPlans, "Alpha", "Cruiser", queued, step("Gallente Cruiser", 3, 4))
+
+	alpha := character("Alpha")
+	alpha.SkillQueue = []model.SkillQueue{{SkillID: 1, FinishDate: &queuedUntil}}
+
+	result, err := s.AssignTraining(
+		[]model.Account{account("Main", alpha)},
+		model.PlanningRequest{Targets: []model.CoverageTarget{{Plan: "Cruiser", Count: 1}}},
+	)
+	require.NoError(t, err)
+	require.Len(t, result.Schedules, 1)
+	schedule := result.Schedules[0]
+	require.Len(t, schedule.Steps, 1)
+	assert.Equal(t, int32(3), schedule.Steps[0].Level)
+	assert.Equal(t, hours(3), schedule.Start)
+	assert.Equal(t, hours(7), *result.CoveredAt)
+}
+
+func TestAssignTraining_PausedQueueIsTrainedAsNew(t *testing.T) {
+	s, skillPlans := newTestService(map[string]model.SkillPlan{"Cruiser": {Name: "Cruiser"}})
+	paused := step("Gallente Cruiser", 2, 2)
+	paused.Queued = true
+	expectQueue(skillPlans, "Alpha", "Cruiser", paused, step("Gallente Cruiser", 3, 4))
+
+	alpha := character("Alpha")
+	alpha.SkillQueue = []model.SkillQueue{{SkillID: 1, FinishedLevel: 2}}
+
+	result, err := s.AssignTraining(
+		[]model.Account{account("Main", alpha)},
+		model.PlanningRequest{Targets: []model.CoverageTarget{{Plan: "Cruiser", Count: 1}}},
+	)
+	require.NoError(t, err)
+	require.Len(t, result.Schedules, 1)
+	schedule := result.Schedules[0]
+	require.Len(t, schedule.Steps, 2)
+	assert.Equal(t, int32(2), schedule.Steps[0].Level)
+	assert.Equal(t, planningNow, schedule.Start)
+	assert.Equal(t, hours(6), *result.CoveredAt)
+}
+
+func TestAssignTraining_WaitsForSlotAndQueue(t *testing.T) {
+	s, skillPlans := newTestService(map[string]model.SkillPlan{"Cruiser": {Name: "Cruiser"}})
+	expectQueue(skillPlans, "Alpha", "Cruiser", step("Gallente Cruiser", 3, 1))
+	expectQueue(skillPlans, "Bravo", "Cruiser", step("Gallente Cruiser", 3, 1))
+
+	alpha, alphaEnd := character("Alpha"), hours(4)
+	alpha.SkillQueue = []model.SkillQueue{{SkillID: 1, FinishDate: &alphaEnd}}
+	bravo, bravoEnd := character("Bravo"), hours(6)
+	bravo.SkillQueue = []model.SkillQueue{{SkillID: 1, FinishDate: &bravoEnd}}
+
+	result, err := s.AssignTraining(
+		[]model.Account{account("Main", alpha, bravo)},
+		model.PlanningRequest{Targets: []model.CoverageTarget{{Plan: "Cruiser", Count: 2}}},
+	)
+	require.NoError(t, err)
+	require.Len(t, result.Schedules, 2)
+	assert.Equal(t, hours(4), result.Schedules[0].Start)
+	// Alpha frees the slot at 5h, but Bravo's own queue runs until 6h.
+	assert.Equal(t, hours(6), result.Schedules[1].Start)
+	assert.Equal(t, hours(7), *result.CoveredAt)
+}
+
+func TestAssignTraining_RoleTargetsAndSharedSkills(t *testing.T) {
+	s, skillPlans := newTestService(map[string]model.SkillPlan{
+		"Cruiser":   {Name: "Cruiser"},
+		"Logistics": {Name: "Logistics", Metadata: model.PlanMetadata{Roles: []string{"Logi"}}},
+	})
+	expectQueue(skillPlans, "Alpha", "Cruiser", step("Gallente Cruiser", 3, 5))
+	expectQueue(skillPlans, "Alpha", "Logistics", step("Gallente Cruiser", 3, 5), step("Logistics Cruisers", 1, 2))
+
+	result, err := s.AssignTraining(
+		[]model.Account{account("Main", character("Alpha"))},
+		model.PlanningRequest{Targets: []model.CoverageTarget{
+			{Plan: "Cruiser", Count: 1},
+			{Role: "logi", Count: 1},
+		}},
+	)
+	require.NoError(t, err)
+	require.Len(t, result.Schedules, 1)
+	assert.Len(t, result.Schedules[0].Steps, 2, "Gallente Cruiser III is trained once")
+	assert.Equal(t, hours(7), result.Schedules[0].Finish)
+	assert.Equal(t, "Logistics", result.Coverage[1].Characters[0].PlanName)
+	assert.Equal(t, hours(7), *result.CoveredAt)
+}
+
+func TestAssignTraining_AlphaCannotTrainOmegaSkills(t *testing.T) {
+	s, skillPlans := newTestService(map[string]model.SkillPlan{"Cruiser": {Name: "Cruiser"}})
+	omegaOnly := step("Gallente Cruiser", 5, 0)
+	omegaOnly.OmegaOnly = true
+	expectQueue(skillPlans, "Alpha", "Cruiser", step("Gallente Cruiser", 4, 1), omegaOnly)
+	expectQueue(skillPlans, "Bravo", "Cruiser", step("Gallente Cruiser", 5, 30))
+
+	alphaAccount := account("Free", character("Alpha"))
+	alphaAccount.Status = model.Alpha
+	result, err := s.AssignTraining(
+		[]model.Account{alphaAccount, account("Main", character("Bravo"))},
+		model.PlanningRequest{Targets: []model.CoverageTarget{{Plan: "Cruiser", Count: 1}}},
+	)
+	require.NoError(t, err)
+	require.Len(t, result.Coverage[0].Characters, 1)
+	assert.Equal(t, "Bravo", result.Coverage[0].Characters[0].CharacterName)
+}
+
+func TestAssignTraining_UnmetTarget(t *testing.T) {
+	s, skillPlans := newTestService(map[string]model.SkillPlan{"Cruiser": {Name: "Cruiser"}})
+	expectQueue(skillPlans, "Alpha", "Cruiser", step("Gallente Cruiser", 3, 5))
+
+	result, err := s.AssignTraining(
+		[]model.Account{account("Main", character("Alpha"))},
+		model.PlanningRequest{Targets: []model.CoverageTarget{{Plan: "Cruiser", Count: 2}}},
+	)
+	require.NoError(t, err)
+	assert.False(t, result.Coverage[0].Met)
+	assert.Nil(t, result.Coverage[0].CoveredAt)
+	assert.Nil(t, result.CoveredAt)
+	assert.Len(t, result.Coverage[0].Characters, 1)
+}
+
+func TestAssignTraining_InvalidRequests(t *testing.T) {
+	s, _ := newTestService(map[string]model.SkillPlan{"Cruiser": {Name: "Cruiser"}})
+
+	tests := []struct {
+		name    string
+		request model.PlanningRequest
+		status  int
+	}{
+		{"no targets", model.PlanningRequest{}, http.StatusBadRequest},
+		{"plan and role", model.PlanningRequest{Targets: []model.CoverageTarget{{Plan: "Cruiser", Role: "Logi", Count: 1}}}, http.StatusBadRequest},
+		{"zero count", model.PlanningRequest{Targets: []model.CoverageTarget{{Plan: "Cruiser"}}}, http.StatusBadRequest},
+		{"unknown plan", model.PlanningRequest{Targets: []model.CoverageTarget{{Plan: "Titan", Count: 1}}}, http.StatusNotFound},
+		{"unknown role", model.PlanningRequest{Targets: []model.CoverageTarget{{Role: "Logi", Count: 1}}}, http.StatusBadRequest},
+		{"too many slots", model.PlanningRequest{
+			Targets:       []model.CoverageTarget{{Plan: "Cruiser", Count: 1}},
+			TrainingSlots: map[string]int{"Main": 4},
+		}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.AssignTraining(nil, tt.request)
+			var customErr *flyErrors.CustomError
+			require.True(t, errors.As(err, &customErr), "expected a CustomError, got %v", err)
+			assert.Equal(t, tt.status, customErr.StatusCode)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package profile
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/guarzo/canifly/internal/model"
@@ -35,7 +36,7 @@ func NewService(
 
 var _ interfaces.ProfileService = (*Service)(nil)
 
-func (s *Service) LoadCharacterSettings() ([]model.EveProfile, error) {
+func (s *Service) LoadCharacterSettings(ctx context.Context) ([]model.EveProfile, error) {
 	settingsDir, err := s.config.GetSettingsDir()
 	if err != nil {
 		s.logger.Errorf("Failed to get settings directory: %v", err)
@@ -104,7 +105,7 @@ func (s *Service) LoadCharacterSettings() ([]model.EveProfile, error) {
 	for id := range allCharIDs {
 		charIdList = append(charIdList, id)
 	}
-	charIdToName, err := s.esi.ResolveCharacterNames(charIdList)
+	charIdToName, err := s.esi.ResolveCharacterNames(ctx, charIdList)
 	if err != nil {
 		return nil, fmt.Errorf("failed to resolve character names: %w", err)
 	}
@@ -0,0 +1,129 @@
+package scheduler
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"time"
+
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/services/interfaces"
+)
+
+// characterRefreshes are the parts of every character's ESI data the
+// scheduler refreshes, each on its own schedule. The skill queue and location
+// change as a character trains and flies; skills, attributes and implants only
+// as skills finish or clones jump; clones and affiliation hardly ever.
+var characterRefreshes = []struct {
+	name      string
+	interval  time.Duration
+	jitter    time.Duration
+	endpoints []model.CharacterEndpoint
+}{
+	{"skill queue and location", 5 * time.Minute, time.Minute,
+		[]model.CharacterEndpoint{model.EndpointSkillQueue, model.EndpointLocation}},
+	{"skills", time.Hour, 10 * time.Minute,
+		[]model.CharacterEndpoint{model.EndpointSkills, model.EndpointAttributes, model.EndpointImplants}},
+	{"clones and affiliation", 6 * time.Hour, 30 * time.Minute,
+		[]model.CharacterEndpoint{model.EndpointClones, model.EndpointAffiliation}},
+}
+
+// characterRefreshedEvent is the WebSocket update sent after each refresh.
+const characterRefreshedEvent = "character:refreshed"
+
+// NewCharacterRefreshTasks returns a task for each group of character
+// endpoints, refreshing them for every character and broadcasting a
+// character:refreshed event with what changed for each.
+func NewCharacterRefreshTasks(
+	logger interfaces.Logger,
+	accounts interfaces.AccountManagementService,
+	characters interfaces.CharacterService,
+	cache interfaces.HTTPCacheService,
+	broadcaster interfaces.Broadcaster,
+) []Task {
+	tasks := make([]Task, 0, len(characterRefreshes))
+	for _, refresh := range characterRefreshes {
+		endpoints := refresh.endpoints
+		tasks = append(tasks, Task{
+			Name:     refresh.name,
+			Interval: refresh.interval,
+			Jitter:   refresh.jitter,
+			Run: func(ctx context.Context) {
+				refreshCharacters(ctx, endpoints, logger, accounts, characters, cache, broadcaster)
+			},
+		})
+	}
+	return tasks
+}
+
+func refreshCharacters(
+	ctx context.Context,
+	endpoints []model.CharacterEndpoint,
+	logger interfaces.Logger,
+	accounts interfaces.AccountManagementService,
+	characters interfaces.CharacterService,
+	cache interfaces.HTTPCacheService,
+	broadcaster interfaces.Broadcaster,
+) {
+	snapshot, err := accounts.FetchAccounts()
+	if err != nil {
+		logger.Warnf("Scheduled character refresh could not fetch accounts: %v", err)
+		return
+	}
+
+	for _, account := range snapshot {
+		for _, before := range account.Characters {
+			if ctx.Err() != nil {
+				return
+			}
+
+			characterID := before.Character.CharacterID
+			if _, err := characters.RefreshCharacterEndpoints(ctx, characterID, endpoints...); err != nil {
+				logger.Warnf("Scheduled refresh failed for character %s: %v", before.Character.CharacterName, err)
+				continue
+			}
+			_, after, err := characters.DoesCharacterExist(characterID)
+			if err != nil || after == nil {
+				continue
+			}
+
+			cache.Invalidate("accounts:")
+			broadcaster.BroadcastUpdate(characterRefreshedEvent, map[string]interface{}{
+				"characterId":   characterID,
+				"characterName": after.Character.CharacterName,
+				"changes":       characterChanges(before, *after),
+			})
+		}
+	}
+}
+
+// characterChanges lists the parts of a character's data a refresh changed.
+func characterChanges(before, after model.CharacterIdentity) []string {
+	changes := []string{}
+	b, a := before.Character, after.Character
+	if b.TotalSP != a.TotalSP || b.UnallocatedSP != a.UnallocatedSP || !reflect.DeepEqual(b.Skills, a.Skills) {
+		changes = append(changes, "skills")
+	}
+	if !reflect.DeepEqual(b.SkillQueue, a.SkillQueue) {
+		changes = append(changes, "skillQueue")
+	}
+	if b.Location != a.Location {
+		changes = append(changes, "location")
+	}
+	if !reflect.DeepEqual(b.Attributes, a.Attributes) {
+		changes = append(changes, "attributes")
+	}
+	if !slices.Equal(b.Implants, a.Implants) {
+		changes = append(changes, "implants")
+	}
+	if !reflect.DeepEqual(b.Clones, a.Clones) {
+		changes = append(changes, "clones")
+	}
+	if before.CorporationName != after.CorporationName || before.AllianceName != after.AllianceName {
+		changes = append(changes, "affiliation")
+	}
+	if before.MCT != after.MCT || before.Training != after.Training {
+		changes = append(changes, "training")
+	}
+	return changes
+}
@@ -0,0 +1,139 @@
+package scheduler
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/guarzo/canifly/internal/services/interfaces"
+)
+
+// Compile-time interface check.
+var _ interfaces.SchedulerService = (*Service)(nil)
+
+// ESI goes down for daily maintenance at 11:00 UTC and is normally back
+// within a quarter of an hour.
+const (
+	downtimeStartHour = 11
+	downtimeLength    = 15 * time.Minute
+)
+
+// Task is a refresh the scheduler runs every Interval, plus a random delay of
+// up to Jitter so that tasks don't all hit ESI at once. The context Run is
+// given is cancelled when the scheduler shuts down, and Run should pass it to
+// its ESI calls and return early once it is done.
+type Task struct {
+	Name     string
+	Interval time.Duration
+	Jitter   time.Duration
+	Run      func(ctx context.Context)
+}
+
+// Service implements interfaces.SchedulerService.
+type Service struct {
+	logger interfaces.Logger
+	tasks  []Task
+	now    func() time.Time
+	jitter func(max time.Duration) time.Duration
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup
+	startOnce sync.Once
+}
+
+// NewService constructs a SchedulerService for the given tasks.
+func NewService(logger interfaces.Logger, tasks ...Task) *Service {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Service{
+		logger: logger,
+		tasks:  tasks,
+		now:    time.Now,
+		jitter: randomJitter,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Start runs every task on its own schedule in the background.
+func (s *Service) Start() {
+	s.startOnce.Do(func() {
+		for _, task := range s.tasks {
+			s.wg.Add(1)
+			go s.runTask(task)
+			s.logger.Infof("Scheduled %s refresh every %s (+ up to %s)", task.Name, task.Interval, task.Jitter)
+		}
+	})
+}
+
+// Shutdown stops scheduling, cancels running tasks and waits for them to
+// return, giving up with ctx's error once ctx is done.
+func (s *Service) Shutdown(ctx context.Context) error {
+	s.cancel()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+		s.logger.Info("Scheduler stopped")
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Service) runTask(task Task) {
+	defer s.wg.Done()
+
+	for {
+		now := s.now()
+		timer := time.NewTimer(s.nextRun(now, task).Sub(now))
+		select {
+		case <-s.ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		// The clock may have moved into downtime while waiting, e.g. after
+		// the machine slept.
+		if _, down := downtimeEnd(s.now()); down {
+			s.logger.Debugf("Skipping %s refresh during ESI downtime", task.Name)
+			continue
+		}
+		s.logger.Debugf("Running scheduled %s refresh", task.Name)
+		task.Run(s.ctx)
+	}
+}
+
+// nextRun is when a task runs next after from, moved past ESI downtime.
+func (s *Service) nextRun(from time.Time, task Task) time.Time {
+	next := from.Add(task.Interval + s.jitter(task.Jitter))
+	if end, down := downtimeEnd(next); down {
+		next = end.Add(s.jitter(task.Jitter))
+	}
+	return next
+}
+
+// downtimeEnd reports whether t falls in ESI's daily downtime, and when that
+// downtime ends.
+func downtimeEnd(t time.Time) (time.Time, bool) {
+	t = t.UTC()
+	start := time.Date(t.Year(), t.Month(), t.Day(), downtimeStartHour, 0, 0, 0, time.UTC)
+	end := start.Add(downtimeLength)
+	if t.Before(start) || !t.Before(end) {
+		return time.Time{}, false
+	}
+	return end, true
+}
+
+func randomJitter(max time.Duration) time.Duration {
+	if max <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(max)))
+}
@@ -0,0 +1,123 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/testutil"
+)
+
+func utc(hour, minute int) time.Time {
+	return time.Date(2026, 1, 1, hour, minute, 0, 0, time.UTC)
+}
+
+func TestNextRun_SkipsDowntime(t *testing.T) {
+	s := NewService(&testutil.MockLogger{})
+	s.jitter = func(max time.Duration) time.Duration { return max / 2 }
+	task := Task{Name: "test", Interval: 30 * time.Minute, Jitter: 4 * time.Minute}
+
+	assert.Equal(t, utc(10, 2), s.nextRun(utc(9, 30), task))
+	// 10:45 + 32 minutes lands in downtime, so the run waits until 11:15.
+	assert.Equal(t, utc(11, 17), s.nextRun(utc(10, 45), task))
+	assert.Equal(t, utc(11, 47), s.nextRun(utc(11, 15), task))
+}
+
+func TestDowntimeEnd(t *testing.T) {
+	_, down := downtimeEnd(utc(10, 59))
+	assert.False(t, down)
+
+	end, down := downtimeEnd(utc(11, 0).In(time.FixedZone("EST", -5*3600)))
+	assert.True(t, down)
+	assert.Equal(t, utc(11, 15), end)
+
+	_, down = downtimeEnd(utc(11, 15))
+	assert.False(t, down)
+}
+
+func TestStartAndShutdown(t *testing.T) {
+	runs := make(chan struct{}, 10)
+	s := NewService(&testutil.MockLogger{}, Task{
+		Name:     "test",
+		Interval: time.Millisecond,
+		Run: func(ctx context.Context) {
+			runs <- struct{}{}
+		},
+	})
+	s.now = func() time.Time { return utc(9, 0) }
+
+	s.Start()
+	select {
+	case <-runs:
+	case <-time.After(time.Second):
+		t.Fatal("task did not run")
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		assert.NoError(t, s.Shutdown(context.Background()))
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("scheduler did not stop")
+	}
+}
+
+func TestShutdown_GivesUpOnStuckTask(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	running := make(chan struct{})
+	s := NewService(&testutil.MockLogger{}, Task{
+		Name:     "stuck",
+		Interval: time.Millisecond,
+		Run: func(ctx context.Context) {
+			close(running)
+			<-release
+		},
+	})
+	s.now = func() time.Time { return utc(9, 0) }
+
+	s.Start()
+	<-running
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.ErrorIs(t, s.Shutdown(ctx), context.DeadlineExceeded)
+}
+
+func TestCharacterChanges(t *testing.T) {
+	before := model.CharacterIdentity{CorporationName: "Corp"}
+	before.Character.TotalSP = 1_000_000
+	before.Character.Location = 30000142
+
+	after := before
+	assert.Empty(t, characterChanges(before, after))
+
+	after.Character.TotalSP = 1_100_000
+	after.Character.SkillQueue = []model.SkillQueue{{SkillID: 3327}}
+	after.CorporationName = "Other Corp"
+	assert.Equal(t, []string{"skills", "skillQueue", "affiliation"}, characterChanges(before, after))
+}
+
+func TestNewCharacterRefreshTasks_CoverEveryEndpointOnce(t *testing.T) {
+	tasks := NewCharacterRefreshTasks(&testutil.MockLogger{}, nil, nil, nil, nil)
+	assert.Len(t, tasks, len(characterRefreshes))
+
+	seen := make(map[model.CharacterEndpoint]int)
+	for _, refresh := range characterRefreshes {
+		for _, endpoint := range refresh.endpoints {
+			seen[endpoint]++
+		}
+	}
+	for _, endpoint := range []model.CharacterEndpoint{
+		model.EndpointAffiliation, model.EndpointSkills, model.EndpointSkillQueue, model.EndpointLocation,
+		model.EndpointAttributes, model.EndpointImplants, model.EndpointClones,
+	} {
+		assert.Equal(t, 1, seen[endpoint], "endpoint %s", endpoint)
+	}
+}
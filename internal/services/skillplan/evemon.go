@@ -0,0 +1,83 @@
+package skillplan
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	flyErrors "github.com/guarzo/canifly/internal/errors"
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/services/skillplans"
+)
+
+// ImportEVEMonPlan converts an EVEMon plan (XML or gzip-compressed .emp) into a
+// text plan and saves it. The plan is saved under name, or under the name
+// stored in the EVEMon file when name is empty; the name used is returned.
+func (s *Service) ImportEVEMonPlan(data []byte, name string) (string, error) {
+	plan, err := skillplans.DecodeEVEMonPlan(data)
+	if errors.Is(err, skillplans.ErrEVEMonPlanTooLarge) {
+		return "", flyErrors.NewCustomError(http.StatusRequestEntityTooLarge, err.Error())
+	}
+	if err != nil {
+		return "", flyErrors.NewCustomError(http.StatusBadRequest, err.Error())
+	}
+	if name == "" {
+		name = strings.TrimSpace(plan.Name)
+	}
+	if err := validatePlanName(name); err != nil {
+		return "", err
+	}
+	if s.CheckIfDuplicatePlan(name) {
+		return "", flyErrors.NewCustomError(http.StatusConflict, fmt.Sprintf("skill plan %s already exists", name))
+	}
+
+	var sb strings.Builder
+	for _, entry := range plan.Entries {
+		skillType, found := s.skillRepo.GetSkillTypeByID(strconv.Itoa(int(entry.SkillID)))
+		if !found {
+			s.logger.Warnf("EVEMon plan %s: unknown skill ID %d (%s); skipping", name, entry.SkillID, entry.Skill)
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("%s %d\n", skillType.TypeName, entry.Level))
+	}
+
+	if sb.Len() == 0 {
+		return "", flyErrors.NewCustomError(http.StatusBadRequest, fmt.Sprintf("EVEMon plan %s contains no known skills", name))
+	}
+	if err := s.ParseAndSaveSkillPlan(sb.String(), name, model.PlanSourceImport); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// ExportEVEMonPlan writes a stored plan as EVEMon XML, one entry per step.
+func (s *Service) ExportEVEMonPlan(name string) ([]byte, error) {
+	plan, exists := s.skillRepo.GetSkillPlans()[name]
+	if !exists {
+		return nil, flyErrors.NewCustomError(http.StatusNotFound, fmt.Sprintf("skill plan %s not found", name))
+	}
+	skillTypes := s.skillRepo.GetSkillTypes()
+
+	evemonPlan := skillplans.EVEMonPlan{Name: name}
+	for _, step := range planSteps(plan) {
+		skillType, exists := skillTypes[step.Name]
+		if !exists {
+			s.logger.Warnf("Skill type not found for skill: %s", step.Name)
+			continue
+		}
+		skillID, err := strconv.Atoi(skillType.TypeID)
+		if err != nil {
+			s.logger.Warnf("Failed to convert TypeID to int for skill %s: %v", step.Name, err)
+			continue
+		}
+		evemonPlan.Entries = append(evemonPlan.Entries, skillplans.EVEMonEntry{
+			SkillID: int32(skillID),
+			Skill:   step.Name,
+			Level:   step.Level,
+		})
+	}
+
+	return skillplans.EncodeEVEMonPlan(evemonPlan)
+}
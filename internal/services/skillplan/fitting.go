@@ -0,0 +1,187 @@
+package skillplan
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	flyErrors "github.com/guarzo/canifly/internal/errors"
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/services/skillplans"
+)
+
+// PlanFromFitting builds a skill plan from an EFT fitting. Every item is
+// resolved through invTypes and contributes its SDE required skills; items
+// that cannot be resolved are returned so the caller can report them.
+func (s *Service) PlanFromFitting(fitting string) (model.SkillPlan, []string, error) {
+	parsed, err := skillplans.ParseEFT(fitting)
+	if err != nil {
+		return model.SkillPlan{}, nil, flyErrors.NewCustomError(http.StatusBadRequest, err.Error())
+	}
+	skillTypes := s.skillRepo.GetSkillTypes()
+	shipType, exists := skillTypes[parsed.Ship]
+	if !exists {
+		return model.SkillPlan{}, nil, flyErrors.NewCustomError(http.StatusBadRequest, fmt.Sprintf("unknown ship type %s", parsed.Ship))
+	}
+	if err := s.checkShipType(shipType); err != nil {
+		return model.SkillPlan{}, nil, err
+	}
+
+	items := append([]skillplans.EFTItem{{Name: parsed.Ship, Quantity: 1}}, parsed.Items...)
+	var steps []model.Skill
+	unknownItems := []string{}
+	planned := make(map[string]int)
+	for _, item := range items {
+		itemType, exists := skillTypes[item.Name]
+		if !exists {
+			unknownItems = append(unknownItems, item.Name)
+			continue
+		}
+		typeID, err := strconv.Atoi(itemType.TypeID)
+		if err != nil {
+			unknownItems = append(unknownItems, item.Name)
+			continue
+		}
+		for _, prereq := range s.skillRepo.GetSkillPrerequisites(int32(typeID)) {
+			name := s.GetSkillName(prereq.SkillID)
+			if name == "" {
+				s.logger.Warnf("Skill type not found for prerequisite ID: %d", prereq.SkillID)
+				continue
+			}
+			if planned[name] >= int(prereq.Level) {
+				continue
+			}
+			planned[name] = int(prereq.Level)
+			steps = append(steps, model.Skill{Name: name, Level: int(prereq.Level)})
+		}
+	}
+
+	return model.SkillPlan{
+		Name:   fittingPlanName(parsed),
+		Steps:  steps,
+		Skills: skillplans.IndexSteps(steps),
+	}, unknownItems, nil
+}
+
+// SaveFittingPlan builds a plan from an EFT fitting and saves it under name, or
+// under "Ship - Fitting Name" when name is empty.
+func (s *Service) SaveFittingPlan(fitting, name string) (model.SkillPlan, []string, error) {
+	plan, unknownItems, err := s.PlanFromFitting(fitting)
+	if err != nil {
+		return model.SkillPlan{}, unknownItems, err
+	}
+	if name != "" {
+		plan.Name = name
+	}
+	if err := validatePlanName(plan.Name); err != nil {
+		return model.SkillPlan{}, unknownItems, err
+	}
+	if s.CheckIfDuplicatePlan(plan.Name) {
+		return model.SkillPlan{}, unknownItems, flyErrors.NewCustomError(http.StatusConflict, fmt.Sprintf("skill plan %s already exists", plan.Name))
+	}
+	if len(plan.Steps) == 0 {
+		return model.SkillPlan{}, unknownItems, flyErrors.NewCustomError(http.StatusBadRequest, "fitting requires no skills")
+	}
+
+	var sb strings.Builder
+	for _, step := range plan.Steps {
+		sb.WriteString(fmt.Sprintf("%s %d\n", step.Name, step.Level))
+	}
+	if err := s.skillRepo.SaveSkillPlan(plan.Name, []byte(sb.String()), model.PlanSourceFitting); err != nil {
+		return model.SkillPlan{}, unknownItems, err
+	}
+	return plan, unknownItems, nil
+}
+
+// EvaluateFitting checks every character against the skills an EFT fitting
+// needs without saving a plan or touching the characters' stored plan status.
+func (s *Service) EvaluateFitting(accounts []model.Account, fitting string) (*model.FittingEvaluation, error) {
+	plan, unknownItems, err := s.PlanFromFitting(fitting)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.FittingEvaluation{
+		Plan:         s.evaluateVirtualPlan(accounts, plan),
+		UnknownItems: unknownItems,
+	}, nil
+}
+
+// GetShipStatus evaluates every character against a ship type's own required
+// skills, as if the ship were a stored plan.
+func (s *Service) GetShipStatus(accounts []model.Account, typeName string) (*model.SkillPlanWithStatus, error) {
+	shipType, exists := s.skillRepo.GetSkillTypes()[typeName]
+	if !exists {
+		return nil, flyErrors.NewCustomError(http.StatusNotFound, fmt.Sprintf("type %s not found", typeName))
+	}
+	if err := s.checkShipType(shipType); err != nil {
+		return nil, err
+	}
+	typeID, _ := strconv.Atoi(shipType.TypeID)
+
+	var steps []model.Skill
+	for _, prereq := range s.skillRepo.GetSkillPrerequisites(int32(typeID)) {
+		if name := s.GetSkillName(prereq.SkillID); name != "" {
+			steps = append(steps, model.Skill{Name: name, Level: int(prereq.Level)})
+		}
+	}
+
+	status := s.evaluateVirtualPlan(accounts, model.SkillPlan{
+		Name:   typeName,
+		Steps:  steps,
+		Skills: skillplans.IndexSteps(steps),
+	})
+	status.TypeId = int64(typeID)
+	return &status, nil
+}
+
+// evaluateVirtualPlan returns the status of a plan that is not stored. It works
+// on copies of the characters so the plan is not recorded in their
+// QualifiedPlans/PendingPlans/MissingSkills maps.
+func (s *Service) evaluateVirtualPlan(accounts []model.Account, plan model.SkillPlan) model.SkillPlanWithStatus {
+	detached := make([]model.Account, len(accounts))
+	for i, account := range accounts {
+		account.Characters = make([]model.CharacterIdentity, len(accounts[i].Characters))
+		for j, identity := range accounts[i].Characters {
+			identity.Character.QualifiedPlans = nil
+			identity.Character.PendingPlans = nil
+			identity.Character.PendingFinishDates = nil
+			identity.Character.MissingSkills = nil
+			identity.Character.OmegaPlans = nil
+			identity.Character.UnallocatedPlans = nil
+			account.Characters[j] = identity
+		}
+		detached[i] = account
+	}
+
+	statuses, _ := s.GetPlanAndConversionData(detached, map[string]model.SkillPlan{plan.Name: plan}, s.skillRepo.GetSkillTypes())
+	return statuses[plan.Name]
+}
+
+// checkShipType fails with 503 while the SDE type attributes are not loaded,
+// since without them no skills would be required, and with 400 for a type
+// that is not a ship.
+func (s *Service) checkShipType(shipType model.SkillType) error {
+	typeID, err := strconv.Atoi(shipType.TypeID)
+	if err != nil {
+		return flyErrors.NewCustomError(http.StatusBadRequest, fmt.Sprintf("type %s has no valid type ID", shipType.TypeName))
+	}
+	ship, loaded := s.skillRepo.IsShipType(int32(typeID))
+	if !loaded {
+		return flyErrors.NewCustomError(http.StatusServiceUnavailable, "skill requirements are not loaded yet")
+	}
+	if !ship {
+		return flyErrors.NewCustomError(http.StatusBadRequest, fmt.Sprintf("%s is not a ship", shipType.TypeName))
+	}
+	return nil
+}
+
+// fittingPlanName names a plan after its fitting, keeping it usable as a file name.
+func fittingPlanName(fitting *skillplans.EFTFitting) string {
+	name := fitting.Ship
+	if fitting.Name != "" {
+		name += " - " + fitting.Name
+	}
+	return strings.NewReplacer("/", "-", "\\", "-").Replace(name)
+}
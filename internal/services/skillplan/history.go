@@ -0,0 +1,90 @@
+package skillplan
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+
+	flyErrors "github.com/guarzo/canifly/internal/errors"
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/services/skillplans"
+)
+
+// GetSkillPlanHistory lists the saved versions of a plan, newest first. The
+// history of a deleted plan is kept, so it can still be restored.
+func (s *Service) GetSkillPlanHistory(name string) ([]model.PlanVersion, error) {
+	if err := validatePlanName(name); err != nil {
+		return nil, err
+	}
+	versions, err := s.skillRepo.GetSkillPlanHistory(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 && !s.CheckIfDuplicatePlan(name) {
+		return nil, flyErrors.NewCustomError(http.StatusNotFound, fmt.Sprintf("skill plan %s not found", name))
+	}
+	return versions, nil
+}
+
+// DiffSkillPlan compares the skills of two versions of a plan. An empty to
+// compares against the current plan file. Only each version's own skills are
+// compared; included plans have their own history.
+func (s *Service) DiffSkillPlan(name, from, to string) (*model.PlanDiff, error) {
+	if err := validatePlanName(name); err != nil {
+		return nil, err
+	}
+	if from == "" {
+		return nil, flyErrors.NewCustomError(http.StatusBadRequest, "from version is required")
+	}
+	fromContent, err := s.planVersionContent(name, from)
+	if err != nil {
+		return nil, err
+	}
+	toContent, err := s.planVersionContent(name, to)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &model.PlanDiff{PlanName: name, From: from, To: to}
+	diff.Added, diff.Removed, diff.Raised, diff.Lowered = skillplans.DiffSteps(
+		skillplans.ParsePlan(string(fromContent)).Steps,
+		skillplans.ParsePlan(string(toContent)).Steps,
+	)
+	return diff, nil
+}
+
+// RestoreSkillPlan saves an earlier version of a plan as its current content.
+// The restore is itself recorded as a new version.
+func (s *Service) RestoreSkillPlan(name, versionID string) error {
+	if err := validatePlanName(name); err != nil {
+		return err
+	}
+	if versionID == "" {
+		return flyErrors.NewCustomError(http.StatusBadRequest, "version is required")
+	}
+	content, err := s.planVersionContent(name, versionID)
+	if err != nil {
+		return err
+	}
+	return s.ParseAndSaveSkillPlan(string(content), name, model.PlanSourceRestore)
+}
+
+// planVersionContent returns a saved version of a plan, or the current plan
+// file when versionID is empty.
+func (s *Service) planVersionContent(name, versionID string) ([]byte, error) {
+	var content []byte
+	var err error
+	if versionID == "" {
+		content, err = s.skillRepo.GetSkillPlanFile(name)
+	} else {
+		content, err = s.skillRepo.GetSkillPlanVersion(name, versionID)
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		if versionID == "" {
+			return nil, flyErrors.NewCustomError(http.StatusNotFound, fmt.Sprintf("skill plan %s not found", name))
+		}
+		return nil, flyErrors.NewCustomError(http.StatusNotFound, fmt.Sprintf("version %s of skill plan %s not found", versionID, name))
+	}
+	return content, err
+}
@@ -0,0 +1,121 @@
+package skillplan
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	flyErrors "github.com/guarzo/canifly/internal/errors"
+	"github.com/guarzo/canifly/internal/model"
+)
+
+// ExportSkillQueue returns the levels a character still has to train for a plan
+// as "Skill Name Level" lines the EVE client accepts when pasted into the skill
+// queue. Prerequisites come before the skills that need them, every
+// intermediate level is listed, and levels already trained or queued are left
+// out, as are levels above the Alpha skill caps for Alpha characters.
+func (s *Service) ExportSkillQueue(character model.Character, status model.AccountStatus, planName string) (string, error) {
+	steps, err := s.PlanTrainingQueue(character, status, planName)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	for _, step := range steps {
+		if !step.Queued && !step.OmegaOnly {
+			sb.WriteString(fmt.Sprintf("%s %d\n", step.SkillName, step.Level))
+		}
+	}
+	return sb.String(), nil
+}
+
+// PlanTrainingQueue returns every level a character still needs for a plan in
+// training order: prerequisites before the skills that need them, and every
+// intermediate level. Levels already in the skill queue are included and
+// marked Queued, with the training they would take if the queue is paused;
+// trained levels are left out. Levels of skills without SDE
+// attributes have no training time. For Alpha characters, training is at the
+// Alpha rate and levels above the Alpha skill caps are marked OmegaOnly.
+func (s *Service) PlanTrainingQueue(character model.Character, status model.AccountStatus, planName string) ([]model.TrainingStep, error) {
+	plan, exists := s.skillRepo.GetSkillPlans()[planName]
+	if !exists {
+		return nil, flyErrors.NewCustomError(http.StatusNotFound, fmt.Sprintf("skill plan %s not found", planName))
+	}
+	skillTypes := s.skillRepo.GetSkillTypes()
+	profile := s.newTrainingProfile(character, status)
+	queued := s.mapSkillQueueLevels(character)
+
+	var typeIds []int32
+	reached := s.mapCharacterSkills(character, &typeIds)
+	steps := []model.TrainingStep{}
+	inProgress := make(map[int32]bool)
+	var train func(skillID int32, name string, level int32)
+	train = func(skillID int32, name string, level int32) {
+		if reached[skillID] >= level || inProgress[skillID] {
+			return
+		}
+		inProgress[skillID] = true
+		for _, prereq := range s.skillRepo.GetSkillPrerequisites(skillID) {
+			prereqName := s.GetSkillName(prereq.SkillID)
+			if prereqName == "" {
+				s.logger.Warnf("Skill type not found for prerequisite ID: %d", prereq.SkillID)
+				continue
+			}
+			train(prereq.SkillID, prereqName, prereq.Level)
+		}
+		inProgress[skillID] = false
+
+		skillAttrs, hasAttrs := s.skillRepo.GetSkillAttributes(skillID)
+		trainable := s.trainableLevel(profile, skillID, level)
+		for next := reached[skillID] + 1; next <= level; next++ {
+			step := model.TrainingStep{SkillName: name, Level: next}
+			if queue, isQueued := queued[skillID]; isQueued && queue.level >= next {
+				step.Queued = true
+				step.QueuedUntil = queue.finishDate
+			}
+			if !step.Queued && next > trainable {
+				step.OmegaOnly = true
+			} else if hasAttrs {
+				start := max(profile.skillPoints[skillID], skillPointsForLevel(skillAttrs.Rank, next-1))
+				step.SkillPoints = max(skillPointsForLevel(skillAttrs.Rank, next)-start, 0)
+				step.TrainingSeconds = trainingSeconds(step.SkillPoints, profile.skillPointsPerMinute(skillAttrs))
+			}
+			steps = append(steps, step)
+		}
+		reached[skillID] = level
+	}
+
+	for _, step := range planSteps(plan) {
+		skillType, exists := skillTypes[step.Name]
+		if !exists {
+			s.logger.Warnf("Skill type not found for skill: %s", step.Name)
+			continue
+		}
+		skillID, err := strconv.Atoi(skillType.TypeID)
+		if err != nil {
+			s.logger.Warnf("Failed to convert TypeID to int for skill %s: %v", step.Name, err)
+			continue
+		}
+		train(int32(skillID), step.Name, int32(step.Level))
+	}
+
+	return steps, nil
+}
+
+// planSteps returns a plan's ordered steps, falling back to its skill index in
+// name order for plans built without steps.
+func planSteps(plan model.SkillPlan) []model.Skill {
+	if len(plan.Steps) > 0 {
+		return plan.Steps
+	}
+	steps := make([]model.Skill, 0, len(plan.Skills))
+	for _, skill := range plan.Skills {
+		steps = append(steps, skill)
+	}
+	sort.Slice(steps, func(i, j int) bool {
+		return steps[i].Name < steps[j].Name
+	})
+	return steps
+}
@@ -0,0 +1,167 @@
+package skillplan
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/guarzo/canifly/internal/model"
+)
+
+// defaultRecommendationLimit is the number of suggestions returned when the
+// request doesn't set one.
+const defaultRecommendationLimit = 10
+
+// skillCandidate accumulates the value of training one skill's next level.
+type skillCandidate struct {
+	recommendation model.SkillRecommendation
+	value          float64
+}
+
+// RecommendNextSkills ranks the next level of every skill a character still
+// needs for any plan by the plan value it adds per hour of training. Only
+// levels the character can start now are suggested, i.e. skills whose
+// prerequisites are trained and, for Alpha characters, that are within the
+// Alpha skill caps. Like the training estimates, it works from trained skills
+// and ignores the skill queue.
+func (s *Service) RecommendNextSkills(character model.Character, status model.AccountStatus, request model.SkillRecommendationRequest) []model.SkillRecommendation {
+	skillTypes := s.skillRepo.GetSkillTypes()
+	plans := s.skillRepo.GetSkillPlans()
+	profile := s.newTrainingProfile(character, status)
+	var typeIds []int32
+	characterSkills := s.mapCharacterSkills(character, &typeIds)
+
+	planNames := make([]string, 0, len(plans))
+	for name := range plans {
+		planNames = append(planNames, name)
+	}
+	sort.Strings(planNames)
+
+	candidates := make(map[string]*skillCandidate)
+	for _, planName := range planNames {
+		plan := plans[planName]
+		weight := planWeight(planName, plan.Metadata, request)
+		if weight <= 0 {
+			continue
+		}
+
+		plan.Skills = s.expandPrerequisites(plan.Skills, skillTypes)
+		result := s.evaluatePlanForCharacter(plan, skillTypes, characterSkills, nil)
+		if result.Qualifies {
+			continue
+		}
+		_, requiredSP := s.planProgress(profile, plan.Skills, skillTypes)
+
+		for skillName, targetLevel := range result.MissingSkills {
+			candidate := s.nextLevelCandidate(candidates, skillName, skillTypes, profile, characterSkills)
+			if candidate == nil {
+				continue
+			}
+
+			if requiredSP > 0 {
+				gained := float64(candidate.recommendation.SkillPoints) / float64(requiredSP)
+				candidate.value += weight * gained
+				candidate.recommendation.AdvancesPlans = append(candidate.recommendation.AdvancesPlans, model.PlanAdvance{
+					PlanName:       planName,
+					ProgressGained: progressPercent(candidate.recommendation.SkillPoints, requiredSP, false),
+				})
+			}
+			if len(result.MissingSkills) == 1 && targetLevel == candidate.recommendation.Level {
+				candidate.value += weight
+				candidate.recommendation.UnlocksPlans = append(candidate.recommendation.UnlocksPlans, planName)
+			}
+		}
+	}
+
+	recommendations := make([]model.SkillRecommendation, 0, len(candidates))
+	for _, candidate := range candidates {
+		hours := float64(max(candidate.recommendation.TrainingSeconds, 1)) / 3600
+		candidate.recommendation.Score = candidate.value / hours
+		recommendations = append(recommendations, candidate.recommendation)
+	}
+	sort.Slice(recommendations, func(i, j int) bool {
+		a, b := recommendations[i], recommendations[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if a.TrainingSeconds != b.TrainingSeconds {
+			return a.TrainingSeconds < b.TrainingSeconds
+		}
+		return a.SkillName < b.SkillName
+	})
+
+	limit := request.Limit
+	if limit <= 0 {
+		limit = defaultRecommendationLimit
+	}
+	if len(recommendations) > limit {
+		recommendations = recommendations[:limit]
+	}
+	return recommendations
+}
+
+// nextLevelCandidate returns the candidate for the next level of a skill,
+// creating it on first use. It returns nil when the skill has no SDE training
+// attributes, its prerequisites aren't trained yet or the level is above the
+// character's Alpha cap.
+func (s *Service) nextLevelCandidate(
+	candidates map[string]*skillCandidate,
+	skillName string,
+	skillTypes map[string]model.SkillType,
+	profile trainingProfile,
+	characterSkills map[int32]int32,
+) *skillCandidate {
+	if candidate, exists := candidates[skillName]; exists {
+		return candidate
+	}
+
+	skillType, exists := skillTypes[skillName]
+	if !exists {
+		return nil
+	}
+	skillID, err := strconv.Atoi(skillType.TypeID)
+	if err != nil {
+		return nil
+	}
+	skillAttrs, found := s.skillRepo.GetSkillAttributes(int32(skillID))
+	if !found {
+		return nil
+	}
+	for _, prereq := range s.skillRepo.GetSkillPrerequisites(int32(skillID)) {
+		if characterSkills[prereq.SkillID] < prereq.Level {
+			return nil
+		}
+	}
+
+	nextLevel := characterSkills[int32(skillID)] + 1
+	if s.trainableLevel(profile, int32(skillID), nextLevel) < nextLevel {
+		return nil
+	}
+	skillPoints := max(skillPointsForLevel(skillAttrs.Rank, nextLevel)-profile.skillPoints[int32(skillID)], 0)
+	candidate := &skillCandidate{recommendation: model.SkillRecommendation{
+		SkillName:       skillName,
+		Level:           nextLevel,
+		SkillPoints:     skillPoints,
+		TrainingSeconds: trainingSeconds(skillPoints, profile.skillPointsPerMinute(skillAttrs)),
+		UnlocksPlans:    []string{},
+		AdvancesPlans:   []model.PlanAdvance{},
+	}}
+	candidates[skillName] = candidate
+	return candidate
+}
+
+// planWeight is how much a plan counts toward recommendations.
+func planWeight(planName string, metadata model.PlanMetadata, request model.SkillRecommendationRequest) float64 {
+	if weight, exists := request.PlanWeights[planName]; exists {
+		return weight
+	}
+	weight, weighted := 0.0, false
+	for tag, tagWeight := range request.TagWeights {
+		if metadata.HasTag(tag) && (!weighted || tagWeight > weight) {
+			weight, weighted = tagWeight, true
+		}
+	}
+	if !weighted {
+		return 1
+	}
+	return weight
+}
@@ -0,0 +1,133 @@
+package skillplan
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/guarzo/canifly/internal/model"
+)
+
+// Attribute remap rules: every attribute starts at 17, may not exceed 27, and
+// 14 bonus points are distributed between them.
+const (
+	remapBaseAttribute = 17
+	remapMaxAttribute  = 27
+	remapBonusPoints   = 14
+)
+
+// attributePair identifies the primary/secondary attributes a skill trains with.
+type attributePair struct {
+	primary   int32
+	secondary int32
+}
+
+// GetRemapAdvice searches every legal attribute distribution for the one that
+// trains the character's remaining skills for a plan in the least time.
+func (s *Service) GetRemapAdvice(character model.Character, status model.AccountStatus, planName string) (*model.RemapAdvice, error) {
+	missingSkills, skillTypes, err := s.untrainedPlanSkills(character, planName)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := s.newTrainingProfile(character, status)
+	remainingByPair := s.remainingSkillPointsByAttributes(profile, missingSkills, skillTypes)
+
+	current := model.CharacterAttributes{
+		Charisma:     profile.attributes.Charisma,
+		Intelligence: profile.attributes.Intelligence,
+		Memory:       profile.attributes.Memory,
+		Perception:   profile.attributes.Perception,
+		Willpower:    profile.attributes.Willpower,
+	}
+	advice := &model.RemapAdvice{
+		CharacterName:          character.CharacterName,
+		PlanName:               planName,
+		CurrentAttributes:      current,
+		OptimalAttributes:      current,
+		CurrentTrainingSeconds: trainingSecondsByAttributes(remainingByPair, profile.effectiveAttributes(), profile.trainingSpeed()),
+	}
+	advice.OptimalTrainingSeconds = advice.CurrentTrainingSeconds
+
+	forEachRemap(func(candidate model.CharacterAttributes) {
+		seconds := trainingSecondsByAttributes(remainingByPair, addAttributes(candidate, profile.bonuses), profile.trainingSpeed())
+		if seconds < advice.OptimalTrainingSeconds {
+			advice.OptimalAttributes = candidate
+			advice.OptimalTrainingSeconds = seconds
+		}
+	})
+	advice.TimeSavedSeconds = advice.CurrentTrainingSeconds - advice.OptimalTrainingSeconds
+
+	if character.Attributes != nil {
+		advice.BonusRemaps = character.Attributes.BonusRemaps
+		advice.NextRemapDate = character.Attributes.AccruedRemapCooldownDate
+	}
+	advice.RemapAvailable = advice.BonusRemaps > 0 || advice.NextRemapDate == nil || !advice.NextRemapDate.After(time.Now())
+
+	return advice, nil
+}
+
+// remainingSkillPointsByAttributes totals the SP still needed for the missing
+// skills, grouped by the attribute pair each skill trains with.
+func (s *Service) remainingSkillPointsByAttributes(
+	profile trainingProfile,
+	missingSkills map[string]int32,
+	skillTypes map[string]model.SkillType,
+) map[attributePair]int64 {
+	remaining := make(map[attributePair]int64)
+	for skillName, targetLevel := range missingSkills {
+		skillType, exists := skillTypes[skillName]
+		if !exists {
+			continue
+		}
+		skillID, err := strconv.Atoi(skillType.TypeID)
+		if err != nil {
+			continue
+		}
+		skillAttrs, found := s.skillRepo.GetSkillAttributes(int32(skillID))
+		if !found {
+			continue
+		}
+		targetLevel = s.trainableLevel(profile, int32(skillID), targetLevel)
+		sp := skillPointsForLevel(skillAttrs.Rank, targetLevel) - profile.skillPoints[int32(skillID)]
+		if sp > 0 {
+			remaining[attributePair{skillAttrs.PrimaryAttribute, skillAttrs.SecondaryAttribute}] += sp
+		}
+	}
+	return remaining
+}
+
+func trainingSecondsByAttributes(remaining map[attributePair]int64, attrs model.CharacterAttributes, speed float64) int64 {
+	var total float64
+	for pair, sp := range remaining {
+		perMinute := (attributeValue(attrs, pair.primary) + attributeValue(attrs, pair.secondary)/2) * speed
+		if perMinute > 0 {
+			total += float64(sp) / perMinute * 60
+		}
+	}
+	return int64(math.Ceil(total))
+}
+
+// forEachRemap calls fn with every legal attribute distribution.
+func forEachRemap(fn func(model.CharacterAttributes)) {
+	maxBonus := remapMaxAttribute - remapBaseAttribute
+	for c := 0; c <= maxBonus; c++ {
+		for i := 0; i <= maxBonus && c+i <= remapBonusPoints; i++ {
+			for m := 0; m <= maxBonus && c+i+m <= remapBonusPoints; m++ {
+				for p := 0; p <= maxBonus && c+i+m+p <= remapBonusPoints; p++ {
+					w := remapBonusPoints - c - i - m - p
+					if w > maxBonus {
+						continue
+					}
+					fn(model.CharacterAttributes{
+						Charisma:     int32(remapBaseAttribute + c),
+						Intelligence: int32(remapBaseAttribute + i),
+						Memory:       int32(remapBaseAttribute + m),
+						Perception:   int32(remapBaseAttribute + p),
+						Willpower:    int32(remapBaseAttribute + w),
+					})
+				}
+			}
+		}
+	}
+}
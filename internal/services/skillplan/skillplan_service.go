@@ -1,12 +1,18 @@
 package skillplan
 
 import (
+	"errors"
+	"fmt"
+	"net/http"
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
 
+	flyErrors "github.com/guarzo/canifly/internal/errors"
 	"github.com/guarzo/canifly/internal/model"
 	"github.com/guarzo/canifly/internal/services/interfaces"
+	"github.com/guarzo/canifly/internal/services/skillplans"
 )
 
 // Compile-time interface check.
@@ -46,60 +52,65 @@ func (s *Service) CheckIfDuplicatePlan(name string) bool {
 	return exists
 }
 
-func (s *Service) ParseAndSaveSkillPlan(contents, name string) error {
-	skills := s.parseSkillPlan(contents)
-	return s.skillRepo.SaveSkillPlan(name, skills)
+// validatePlanName rejects plan names that can't safely be used as a file
+// name in the plans directory. Every path that saves a plan checks its name
+// here, as names can come from uploaded files and pasted fittings.
+func validatePlanName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return flyErrors.NewCustomError(http.StatusBadRequest, "plan name is required")
+	}
+	if strings.ContainsAny(name, `/\`) || strings.Contains(name, "..") {
+		return flyErrors.NewCustomError(http.StatusBadRequest, fmt.Sprintf("invalid plan name %q: must not contain /, \\ or ..", name))
+	}
+	if strings.IndexFunc(name, unicode.IsControl) >= 0 {
+		return flyErrors.NewCustomError(http.StatusBadRequest, fmt.Sprintf("invalid plan name %q: must not contain control characters", name))
+	}
+	return nil
 }
 
-func (s *Service) parseSkillPlan(contents string) map[string]model.Skill {
-	skills := make(map[string]model.Skill)
-	lines := strings.Split(contents, "\n")
-
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-
-		// Find the last space to separate skill name from level
-		lastSpaceIndex := strings.LastIndex(line, " ")
-		if lastSpaceIndex == -1 {
-			s.logger.Warnf("No space found in line '%s'; skipping.", line)
-			continue
-		}
-
-		skillName := line[:lastSpaceIndex]
-		skillLevelStr := line[lastSpaceIndex+1:]
-
-		// Parse skill level, handling Roman numerals if necessary
-		skillLevel, err := parseSkillLevel(skillLevelStr)
-		if err != nil {
-			s.logger.Warnf("Invalid skill level '%s'; skipping line.", skillLevelStr)
-			continue
-		}
+// ParseAndSaveSkillPlan validates plan content and saves it unchanged, so the
+// author's ordering, comments and directives are kept. Problems with the
+// content itself, such as an include cycle, are reported as 400 errors. source
+// is recorded in the plan's history.
+func (s *Service) ParseAndSaveSkillPlan(contents, name string, source model.PlanSource) error {
+	if err := validatePlanName(name); err != nil {
+		return err
+	}
+	parsed := skillplans.ParsePlan(contents)
+	for _, lineErr := range parsed.Errors {
+		s.logger.Warnf("Skill plan %s: skipping %v", name, lineErr)
+	}
+	if len(parsed.Steps) == 0 && len(parsed.Includes) == 0 {
+		return flyErrors.NewCustomError(http.StatusBadRequest, fmt.Sprintf("skill plan %s contains no valid skills", name))
+	}
 
-		// Check if the skill already exists and add/update if necessary
-		if currentSkill, exists := skills[skillName]; !exists || skillLevel > currentSkill.Level {
-			skills[skillName] = model.Skill{Name: skillName, Level: skillLevel}
+	if err := s.skillRepo.SaveSkillPlan(name, []byte(contents), source); err != nil {
+		var includeErr *skillplans.IncludeError
+		if errors.As(err, &includeErr) {
+			return flyErrors.NewCustomError(http.StatusBadRequest, fmt.Sprintf("skill plan %s: %v", name, includeErr))
 		}
+		return err
 	}
-
-	return skills
+	return nil
 }
 
-var romanToInt = map[string]int{
-	"I": 1, "II": 2, "III": 3, "IV": 4, "V": 5,
+func (s *Service) GetSkillPlanFile(name string) ([]byte, error) {
+	return s.skillRepo.GetSkillPlanFile(name)
 }
 
-func parseSkillLevel(levelStr string) (int, error) {
-	if val, ok := romanToInt[levelStr]; ok {
-		return val, nil
+// ExportTextPlan writes a stored plan as "Skill Name Level" lines, one per
+// step, with its includes resolved.
+func (s *Service) ExportTextPlan(name string) ([]byte, error) {
+	plan, exists := s.skillRepo.GetSkillPlans()[name]
+	if !exists {
+		return nil, flyErrors.NewCustomError(http.StatusNotFound, fmt.Sprintf("skill plan %s not found", name))
 	}
-	return strconv.Atoi(levelStr)
-}
 
-func (s *Service) GetSkillPlanFile(name string) ([]byte, error) {
-	return s.skillRepo.GetSkillPlanFile(name)
+	var sb strings.Builder
+	for _, step := range planSteps(plan) {
+		sb.WriteString(fmt.Sprintf("%s %d\n", step.Name, step.Level))
+	}
+	return []byte(sb.String()), nil
 }
 
 func (s *Service) DeleteSkillPlan(name string) error {
@@ -117,20 +128,35 @@ func (s *Service) ListSkillPlans() ([]string, error) {
 
 func (s *Service) RefreshRemotePlans() error {
 	// Reload skill plans from repository (which will trigger GitHub download if configured)
-	return s.skillRepo.LoadSkillPlans()
+	if err := s.skillRepo.LoadSkillPlans(); err != nil {
+		return err
+	}
+	s.LogPlanDiagnostics()
+	return nil
 }
 
 func (s *Service) GetSkillTypeByID(id string) (model.SkillType, bool) {
 	return s.skillRepo.GetSkillTypeByID(id)
 }
 
+// GetExpandedSkillPlan returns the named plan with every prerequisite of its
+// skills merged in, so authors don't have to list the chains by hand.
+func (s *Service) GetExpandedSkillPlan(name string) (model.SkillPlan, bool) {
+	plan, exists := s.skillRepo.GetSkillPlans()[name]
+	if !exists {
+		return model.SkillPlan{}, false
+	}
+	plan.Skills = s.expandPrerequisites(plan.Skills, s.skillRepo.GetSkillTypes())
+	return plan, true
+}
+
 func (s *Service) GetPlanAndConversionData(accounts []model.Account, skillPlans map[string]model.SkillPlan, skillTypes map[string]model.SkillType) (map[string]model.SkillPlanWithStatus, map[string]string) {
 	// Step 1: Initialize updatedSkillPlans and eveConversions
 	updatedSkillPlans := s.initializeUpdatedPlans(skillPlans)
 	eveConversions := s.initializeEveConversions(skillPlans, skillTypes)
 
-	// Step 2: Process all accounts and characters
-	typeIds := s.processAccountsAndCharacters(accounts, skillPlans, skillTypes, updatedSkillPlans)
+	// Step 2: Process all accounts and characters against the prerequisite closure of each plan
+	typeIds := s.processAccountsAndCharacters(accounts, s.expandPlans(skillPlans, skillTypes), skillTypes, updatedSkillPlans)
 
 	// Step 3: Convert skill IDs into names and update eveConversions
 	s.updateEveConversionsWithSkillNames(typeIds, eveConversions)
@@ -138,17 +164,84 @@ func (s *Service) GetPlanAndConversionData(accounts []model.Account, skillPlans
 	return updatedSkillPlans, eveConversions
 }
 
+func (s *Service) expandPlans(skillPlans map[string]model.SkillPlan, skillTypes map[string]model.SkillType) map[string]model.SkillPlan {
+	expanded := make(map[string]model.SkillPlan, len(skillPlans))
+	for planName, plan := range skillPlans {
+		plan.Skills = s.expandPrerequisites(plan.Skills, skillTypes)
+		expanded[planName] = plan
+	}
+	return expanded
+}
+
+// expandPrerequisites returns the given skills plus the transitive closure of
+// their prerequisites, keeping the highest level required for each skill.
+func (s *Service) expandPrerequisites(skills map[string]model.Skill, skillTypes map[string]model.SkillType) map[string]model.Skill {
+	expanded := make(map[string]model.Skill, len(skills))
+	for name, skill := range skills {
+		expanded[name] = skill
+	}
+
+	visited := make(map[int32]bool)
+	var visit func(typeID int32)
+	visit = func(typeID int32) {
+		if visited[typeID] {
+			return
+		}
+		visited[typeID] = true
+
+		for _, prereq := range s.skillRepo.GetSkillPrerequisites(typeID) {
+			name := s.GetSkillName(prereq.SkillID)
+			if name == "" {
+				s.logger.Warnf("Skill type not found for prerequisite ID: %d", prereq.SkillID)
+				continue
+			}
+			// Prerequisites are always required, so they also raise a lower
+			// minimum level of a skill the plan lists as "required/recommended".
+			current, exists := expanded[name]
+			switch {
+			case !exists || int(prereq.Level) >= current.Level:
+				expanded[name] = model.Skill{Name: name, Level: int(prereq.Level)}
+			case int(prereq.Level) > current.MinimumLevel():
+				current.Required = int(prereq.Level)
+				expanded[name] = current
+			}
+			visit(prereq.SkillID)
+		}
+	}
+
+	for name := range skills {
+		skillType, exists := skillTypes[name]
+		if !exists {
+			continue
+		}
+		typeID, err := strconv.Atoi(skillType.TypeID)
+		if err != nil {
+			continue
+		}
+		visit(int32(typeID))
+	}
+
+	return expanded
+}
+
 func (s *Service) initializeUpdatedPlans(skillPlans map[string]model.SkillPlan) map[string]model.SkillPlanWithStatus {
 	updated := make(map[string]model.SkillPlanWithStatus)
 	for planName, plan := range skillPlans {
 		updated[planName] = model.SkillPlanWithStatus{
-			Name:                plan.Name,
-			Skills:              plan.Skills,
-			QualifiedCharacters: []string{},
-			PendingCharacters:   []string{},
-			MissingCharacters:   []string{},
-			MissingSkills:       make(map[string]map[string]int32),
-			Characters:          []model.CharacterSkillPlanStatus{},
+			Name:                  plan.Name,
+			TypeId:                plan.Metadata.ShipTypeID,
+			Steps:                 plan.Steps,
+			Skills:                plan.Skills,
+			Metadata:              plan.Metadata,
+			QualifiedCharacters:   []string{},
+			MinimumCharacters:     []string{},
+			PendingCharacters:     []string{},
+			MissingCharacters:     []string{},
+			OmegaCharacters:       []string{},
+			UnallocatedCharacters: []string{},
+			MissingSkills:         make(map[string]map[string]int32),
+			TrainingEstimates:     make(map[string]model.PlanTrainingEstimate),
+			Characters:            []model.CharacterSkillPlanStatus{},
 		}
 	}
 	return updated
@@ -181,12 +274,19 @@ func (s *Service) processAccountsAndCharacters(
 			// Extract character skill and queue info
 			characterSkills := s.mapCharacterSkills(character, &typeIds)
 			skillQueueLevels := s.mapSkillQueueLevels(character)
+			profile := s.newTrainingProfile(character, account.Status)
 
 			s.ensureCharacterMaps(&character)
 
 			// Evaluate each plan for this character
 			for planName, plan := range skillPlans {
 				planResult := s.evaluatePlanForCharacter(plan, skillTypes, characterSkills, skillQueueLevels)
+				s.applyAlphaCaps(profile, plan, skillTypes, &planResult)
+				if len(planResult.MissingSkills) > 0 && len(planResult.OmegaSkills) == 0 {
+					planResult.TrainingEstimate = s.estimateTraining(profile, planResult.MissingSkills, skillTypes)
+					s.applyUnallocatedSP(int64(character.UnallocatedSP), skillTypes, &planResult)
+				}
+				planResult.TrainedSP, planResult.RequiredSP = s.planProgress(profile, plan.Skills, skillTypes)
 
 				planStatus := updatedSkillPlans[planName]
 				s.updatePlanAndCharacterStatus(
@@ -244,13 +344,26 @@ func (s *Service) ensureCharacterMaps(character *model.Character) {
 	if character.PendingFinishDates == nil {
 		character.PendingFinishDates = make(map[string]*time.Time)
 	}
+	if character.OmegaPlans == nil {
+		character.OmegaPlans = make(map[string]bool)
+	}
+	if character.UnallocatedPlans == nil {
+		character.UnallocatedPlans = make(map[string]bool)
+	}
 }
 
 type planEvaluationResult struct {
-	Qualifies        bool
-	Pending          bool
-	MissingSkills    map[string]int32
-	LatestFinishDate *time.Time
+	Qualifies            bool // every recommended level is trained
+	MeetsMinimum         bool // every required level is trained
+	Pending              bool
+	MissingSkills        map[string]int32
+	OmegaSkills          map[string]int32     // skills above the Alpha cap; the plan needs Omega
+	Allocation           []model.SPAllocation // unallocated SP spending that qualifies the character
+	UnallocatedGapClosed float64
+	LatestFinishDate     *time.Time
+	TrainingEstimate     *model.PlanTrainingEstimate
+	TrainedSP            int64
+	RequiredSP           int64
 }
 
 func (s *Service) evaluatePlanForCharacter(
@@ -264,6 +377,7 @@ func (s *Service) evaluatePlanForCharacter(
 ) planEvaluationResult {
 	result := planEvaluationResult{
 		Qualifies:     true,
+		MeetsMinimum:  true,
 		MissingSkills: make(map[string]int32),
 	}
 
@@ -272,6 +386,7 @@ func (s *Service) evaluatePlanForCharacter(
 		if !exists {
 			s.logger.Warnf("Skill type not found for skill: %s", skillName)
 			result.Qualifies = false
+			result.MeetsMinimum = false
 			result.MissingSkills[skillName] = int32(requiredSkill.Level)
 			continue
 		}
@@ -280,6 +395,7 @@ func (s *Service) evaluatePlanForCharacter(
 		if err != nil {
 			s.logger.Warnf("Failed to convert TypeID to int for skill %s: %v", skillName, err)
 			result.Qualifies = false
+			result.MeetsMinimum = false
 			result.MissingSkills[skillName] = int32(requiredSkill.Level)
 			continue
 		}
@@ -288,6 +404,9 @@ func (s *Service) evaluatePlanForCharacter(
 		queueInfo, inQueue := skillQueueLevels[int32(skillID)]
 
 		requiredLevel := int32(requiredSkill.Level)
+		if !hasSkill || characterLevel < int32(requiredSkill.MinimumLevel()) {
+			result.MeetsMinimum = false
+		}
 
 		if hasSkill && characterLevel >= requiredLevel {
 			// Character already has this skill at the required level
@@ -308,15 +427,65 @@ func (s *Service) evaluatePlanForCharacter(
 		}
 	}
 
+	// The queue alone only qualifies the character when nothing else is missing.
+	if len(result.MissingSkills) > 0 {
+		result.Pending = false
+		result.LatestFinishDate = nil
+	}
+
 	return result
 }
 
-func (s *Service) getStatusString(qualifies, pending bool) string {
-	if qualifies {
-		return "qualified"
+// applyAlphaCaps marks a plan Omega-only for an Alpha character when any of its
+// skills is above the Alpha cap. Trained levels above the cap are unusable on
+// an Alpha clone, so such a plan can be neither qualified nor pending.
+func (s *Service) applyAlphaCaps(
+	profile trainingProfile,
+	plan model.SkillPlan,
+	skillTypes map[string]model.SkillType,
+	result *planEvaluationResult,
+) {
+	if !profile.alpha {
+		return
 	}
-	if pending {
+	for skillName, skill := range plan.Skills {
+		skillType, exists := skillTypes[skillName]
+		if !exists {
+			continue
+		}
+		skillID, err := strconv.Atoi(skillType.TypeID)
+		if err != nil {
+			continue
+		}
+		usable := s.trainableLevel(profile, int32(skillID), int32(skill.Level))
+		if usable >= int32(skill.Level) {
+			continue
+		}
+		if result.OmegaSkills == nil {
+			result.OmegaSkills = make(map[string]int32)
+		}
+		result.OmegaSkills[skillName] = int32(skill.Level)
+		if usable < int32(skill.MinimumLevel()) {
+			result.MeetsMinimum = false
+		}
+	}
+	if len(result.OmegaSkills) > 0 {
+		result.Qualifies = false
+		result.Pending = false
+		result.LatestFinishDate = nil
+	}
+}
+
+func (s *Service) getStatusString(result planEvaluationResult) string {
+	switch {
+	case len(result.OmegaSkills) > 0:
+		return "omega"
+	case result.Qualifies:
+		return "qualified"
+	case result.Pending:
 		return "pending"
+	case len(result.Allocation) > 0:
+		return "unallocated"
 	}
 	return "missing"
 }
@@ -328,31 +497,53 @@ func (s *Service) updatePlanAndCharacterStatus(
 	result planEvaluationResult,
 ) {
 	characterStatus := model.CharacterSkillPlanStatus{
-		CharacterName:     character.CharacterName,
-		Status:            s.getStatusString(result.Qualifies, result.Pending),
-		MissingSkills:     result.MissingSkills,
-		PendingFinishDate: result.LatestFinishDate,
+		CharacterName:        character.CharacterName,
+		Status:               s.getStatusString(result),
+		MeetsMinimum:         result.MeetsMinimum,
+		MeetsRecommended:     result.Qualifies,
+		MissingSkills:        result.MissingSkills,
+		OmegaSkills:          result.OmegaSkills,
+		Allocation:           result.Allocation,
+		UnallocatedGapClosed: result.UnallocatedGapClosed,
+		PendingFinishDate:    result.LatestFinishDate,
+		TrainingEstimate:     result.TrainingEstimate,
+		TrainedSP:            result.TrainedSP,
+		RequiredSP:           result.RequiredSP,
+		Progress:             progressPercent(result.TrainedSP, result.RequiredSP, result.Qualifies),
 	}
 
-	if result.Qualifies {
+	if result.MeetsMinimum {
+		planStatus.MinimumCharacters = append(planStatus.MinimumCharacters, character.CharacterName)
+	}
+
+	delete(character.QualifiedPlans, planName)
+	delete(character.PendingPlans, planName)
+	delete(character.PendingFinishDates, planName)
+	delete(character.MissingSkills, planName)
+	delete(character.OmegaPlans, planName)
+	delete(character.UnallocatedPlans, planName)
+
+	switch characterStatus.Status {
+	case "omega":
+		planStatus.OmegaCharacters = append(planStatus.OmegaCharacters, character.CharacterName)
+		character.OmegaPlans[planName] = true
+	case "qualified":
 		planStatus.QualifiedCharacters = append(planStatus.QualifiedCharacters, character.CharacterName)
 		character.QualifiedPlans[planName] = true
-		delete(character.PendingPlans, planName)
-		delete(character.MissingSkills, planName)
-		delete(character.PendingFinishDates, planName)
-	} else if result.Pending {
+	case "pending":
 		planStatus.PendingCharacters = append(planStatus.PendingCharacters, character.CharacterName)
 		character.PendingPlans[planName] = true
 		character.PendingFinishDates[planName] = result.LatestFinishDate
-		delete(character.QualifiedPlans, planName)
-		delete(character.MissingSkills, planName)
-	} else {
+	case "unallocated":
+		planStatus.UnallocatedCharacters = append(planStatus.UnallocatedCharacters, character.CharacterName)
+		character.UnallocatedPlans[planName] = true
+	default:
 		planStatus.MissingCharacters = append(planStatus.MissingCharacters, character.CharacterName)
 		planStatus.MissingSkills[character.CharacterName] = result.MissingSkills
+		if result.TrainingEstimate != nil {
+			planStatus.TrainingEstimates[character.CharacterName] = *result.TrainingEstimate
+		}
 		character.MissingSkills[planName] = result.MissingSkills
-		delete(character.QualifiedPlans, planName)
-		delete(character.PendingPlans, planName)
-		delete(character.PendingFinishDates, planName)
 	}
 
 	planStatus.Characters = append(planStatus.Characters, characterStatus)
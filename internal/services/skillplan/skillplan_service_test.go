@@ -1,8 +1,18 @@
 package skillplan_test
 
 import (
+	"fmt"
+	"net/http"
+	"os"
 	"testing"
+	"time"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	flyErrors "github.com/guarzo/canifly/internal/errors"
+	"github.com/guarzo/canifly/internal/model"
 	"github.com/guarzo/canifly/internal/services/skillplan"
 	"github.com/guarzo/canifly/internal/testutil"
 )
@@ -13,3 +23,783 @@ func TestNewService_Constructs(t *testing.T) {
 		t.Fatal("NewService returned nil")
 	}
 }
+
+// prerequisiteRepo builds a repository where Heavy Assault Cruisers requires
+// Caldari Cruiser 5, which in turn requires Spaceship Command 4.
+func prerequisiteRepo() *testutil.MockSkillRepository {
+	repo := &testutil.MockSkillRepository{}
+	repo.On("GetSkillPrerequisites", int32(16591)).Return([]model.SkillPrerequisite{{SkillID: 3334, Level: 5}})
+	repo.On("GetSkillPrerequisites", int32(3334)).Return([]model.SkillPrerequisite{{SkillID: 3327, Level: 4}})
+	repo.On("GetSkillPrerequisites", mock.Anything).Return([]model.SkillPrerequisite{})
+	repo.On("GetSkillTypeByID", "3334").Return(model.SkillType{TypeID: "3334", TypeName: "Caldari Cruiser"}, true)
+	repo.On("GetSkillTypeByID", "3327").Return(model.SkillType{TypeID: "3327", TypeName: "Spaceship Command"}, true)
+	repo.On("GetSkillTypeByID", "16591").Return(model.SkillType{TypeID: "16591", TypeName: "Heavy Assault Cruisers"}, true)
+	// Spaceship Command: rank 2, perception / willpower
+	repo.On("GetSkillAttributes", int32(3327)).Return(model.SkillAttributes{Rank: 2, PrimaryAttribute: 167, SecondaryAttribute: 168}, true)
+	repo.On("GetSkillAttributes", mock.Anything).Return(model.SkillAttributes{}, false)
+	return repo
+}
+
+func prerequisiteSkillTypes() map[string]model.SkillType {
+	return map[string]model.SkillType{
+		"Heavy Assault Cruisers": {TypeID: "16591", TypeName: "Heavy Assault Cruisers"},
+		"Caldari Cruiser":        {TypeID: "3334", TypeName: "Caldari Cruiser"},
+		"Spaceship Command":      {TypeID: "3327", TypeName: "Spaceship Command"},
+	}
+}
+
+func TestGetPlanAndConversionData_EvaluatesPrerequisites(t *testing.T) {
+	s := skillplan.NewService(&testutil.MockLogger{}, prerequisiteRepo())
+
+	plans := map[string]model.SkillPlan{
+		"HAC": {Name: "HAC", Skills: map[string]model.Skill{
+			"Heavy Assault Cruisers": {Name: "Heavy Assault Cruisers", Level: 1},
+		}},
+	}
+	character := model.Character{
+		UserInfoResponse: model.UserInfoResponse{CharacterID: 1, CharacterName: "Pilot"},
+		CharacterSkillsResponse: model.CharacterSkillsResponse{Skills: []model.SkillResponse{
+			{SkillID: 16591, TrainedSkillLevel: 1},
+			{SkillID: 3334, TrainedSkillLevel: 5},
+			{SkillID: 3327, TrainedSkillLevel: 3, SkillpointsInSkill: 16000},
+		}},
+		Attributes: &model.CharacterAttributes{Perception: 27, Willpower: 21},
+	}
+	accounts := []model.Account{{Characters: []model.CharacterIdentity{{Character: character}}}}
+
+	result, _ := s.GetPlanAndConversionData(accounts, plans, prerequisiteSkillTypes())
+
+	plan := result["HAC"]
+	assert.Equal(t, []string{"Pilot"}, plan.MissingCharacters)
+	assert.Equal(t, map[string]int32{"Spaceship Command": 4}, plan.MissingSkills["Pilot"])
+	assert.Len(t, plan.Skills, 1, "reported plan skills should stay as authored")
+
+	// Spaceship Command 4 at rank 2 is 90510 SP; 74510 remain at 27 + 21/2 SP per minute.
+	estimate := plan.TrainingEstimates["Pilot"]
+	assert.Equal(t, int64(74510), estimate.RemainingSP)
+	assert.Equal(t, int64(119216), estimate.TrainingSeconds)
+	assert.Equal(t, []model.SkillTrainingEstimate{{
+		SkillName: "Spaceship Command", CurrentLevel: 3, TargetLevel: 4, RemainingSP: 74510, TrainingSeconds: 119216,
+	}}, estimate.Skills)
+
+	// Only Spaceship Command has a rank, so it alone counts toward progress.
+	if assert.Len(t, plan.Characters, 1) {
+		assert.Equal(t, int64(16000), plan.Characters[0].TrainedSP)
+		assert.Equal(t, int64(90510), plan.Characters[0].RequiredSP)
+		assert.Equal(t, 17.7, plan.Characters[0].Progress)
+	}
+}
+
+func TestGetPlanAndConversionData_PartlyQueued(t *testing.T) {
+	s := skillplan.NewService(&testutil.MockLogger{}, prerequisiteRepo())
+
+	plans := map[string]model.SkillPlan{
+		"HAC": {Name: "HAC", Skills: map[string]model.Skill{
+			"Heavy Assault Cruisers": {Name: "Heavy Assault Cruisers", Level: 1},
+		}},
+	}
+	finish := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	character := model.Character{
+		UserInfoResponse: model.UserInfoResponse{CharacterID: 1, CharacterName: "Pilot"},
+		CharacterSkillsResponse: model.CharacterSkillsResponse{Skills: []model.SkillResponse{
+			{SkillID: 16591, TrainedSkillLevel: 1},
+			{SkillID: 3334, TrainedSkillLevel: 4},
+			{SkillID: 3327, TrainedSkillLevel: 3, SkillpointsInSkill: 16000},
+		}},
+		SkillQueue: []model.SkillQueue{{SkillID: 3334, FinishedLevel: 5, FinishDate: &finish}},
+		Attributes: &model.CharacterAttributes{Perception: 27, Willpower: 21},
+	}
+	accounts := []model.Account{{Characters: []model.CharacterIdentity{{Character: character}}}}
+
+	result, _ := s.GetPlanAndConversionData(accounts, plans, prerequisiteSkillTypes())
+
+	// Caldari Cruiser 5 is queued, but Spaceship Command 4 is not.
+	plan := result["HAC"]
+	assert.Empty(t, plan.PendingCharacters)
+	assert.Equal(t, []string{"Pilot"}, plan.MissingCharacters)
+	assert.Equal(t, map[string]int32{"Spaceship Command": 4}, plan.MissingSkills["Pilot"])
+	assert.Equal(t, int64(119216), plan.TrainingEstimates["Pilot"].TrainingSeconds)
+}
+
+func TestGetPlanAndConversionData_AlphaCaps(t *testing.T) {
+	repo := prerequisiteRepo()
+	repo.On("GetAlphaSkillCap", int32(3327)).Return(int32(3), true)
+	repo.On("GetAlphaSkillCap", mock.Anything).Return(int32(5), true)
+	s := skillplan.NewService(&testutil.MockLogger{}, repo)
+
+	plans := map[string]model.SkillPlan{
+		"Command": {Name: "Command", Skills: map[string]model.Skill{
+			"Spaceship Command": {Name: "Spaceship Command", Level: 4},
+		}},
+		"Cruiser": {Name: "Cruiser", Skills: map[string]model.Skill{
+			"Caldari Cruiser": {Name: "Caldari Cruiser", Level: 1},
+		}},
+		"Basics": {Name: "Basics", Skills: map[string]model.Skill{
+			"Spaceship Command": {Name: "Spaceship Command", Level: 3},
+		}},
+	}
+	character := model.Character{
+		UserInfoResponse: model.UserInfoResponse{CharacterID: 1, CharacterName: "Pilot"},
+		CharacterSkillsResponse: model.CharacterSkillsResponse{Skills: []model.SkillResponse{
+			{SkillID: 3334, TrainedSkillLevel: 1},
+			{SkillID: 3327, TrainedSkillLevel: 5, SkillpointsInSkill: 512000},
+		}},
+	}
+	accounts := []model.Account{{Status: model.Alpha, Characters: []model.CharacterIdentity{{Character: character}}}}
+
+	result, _ := s.GetPlanAndConversionData(accounts, plans, prerequisiteSkillTypes())
+
+	// Spaceship Command V is trained, but an Alpha clone can only use level 3.
+	command := result["Command"]
+	assert.Empty(t, command.QualifiedCharacters)
+	assert.Equal(t, []string{"Pilot"}, command.OmegaCharacters)
+	if assert.Len(t, command.Characters, 1) {
+		assert.Equal(t, "omega", command.Characters[0].Status)
+		assert.Equal(t, map[string]int32{"Spaceship Command": 4}, command.Characters[0].OmegaSkills)
+		assert.False(t, command.Characters[0].MeetsMinimum)
+	}
+
+	// Caldari Cruiser itself is within the caps, but needs Spaceship Command IV.
+	assert.Equal(t, []string{"Pilot"}, result["Cruiser"].OmegaCharacters)
+
+	basics := result["Basics"]
+	assert.Equal(t, []string{"Pilot"}, basics.QualifiedCharacters)
+	assert.Empty(t, basics.OmegaCharacters)
+}
+
+func TestGetPlanAndConversionData_UnallocatedSP(t *testing.T) {
+	s := skillplan.NewService(&testutil.MockLogger{}, prerequisiteRepo())
+
+	plans := map[string]model.SkillPlan{
+		"Command": {Name: "Command", Skills: map[string]model.Skill{
+			"Spaceship Command": {Name: "Spaceship Command", Level: 4},
+		}},
+	}
+	pilot := func(name string, unallocatedSP int32) model.CharacterIdentity {
+		return model.CharacterIdentity{Character: model.Character{
+			UserInfoResponse: model.UserInfoResponse{CharacterName: name},
+			CharacterSkillsResponse: model.CharacterSkillsResponse{
+				Skills:        []model.SkillResponse{{SkillID: 3327, TrainedSkillLevel: 3, SkillpointsInSkill: 16000}},
+				UnallocatedSP: unallocatedSP,
+			},
+		}}
+	}
+	accounts := []model.Account{{Characters: []model.CharacterIdentity{
+		pilot("Injected", 80000),
+		pilot("Short", 37255),
+	}}}
+
+	result, _ := s.GetPlanAndConversionData(accounts, plans, prerequisiteSkillTypes())
+
+	// Spaceship Command 4 needs 74510 more SP.
+	plan := result["Command"]
+	assert.Equal(t, []string{"Injected"}, plan.UnallocatedCharacters)
+	assert.Equal(t, []string{"Short"}, plan.MissingCharacters)
+	assert.NotContains(t, plan.MissingSkills, "Injected")
+
+	statuses := make(map[string]model.CharacterSkillPlanStatus)
+	for _, status := range plan.Characters {
+		statuses[status.CharacterName] = status
+	}
+	assert.Equal(t, "unallocated", statuses["Injected"].Status)
+	assert.Equal(t, []model.SPAllocation{{SkillName: "Spaceship Command", FromLevel: 3, ToLevel: 4, SkillPoints: 74510}}, statuses["Injected"].Allocation)
+	assert.Equal(t, 100.0, statuses["Injected"].UnallocatedGapClosed)
+
+	assert.Equal(t, "missing", statuses["Short"].Status)
+	assert.Empty(t, statuses["Short"].Allocation)
+	assert.Equal(t, 50.0, statuses["Short"].UnallocatedGapClosed)
+}
+
+func TestGetExpandedSkillPlan(t *testing.T) {
+	repo := prerequisiteRepo()
+	repo.On("GetSkillPlans").Return(map[string]model.SkillPlan{
+		"HAC": {Name: "HAC", Skills: map[string]model.Skill{
+			"Heavy Assault Cruisers": {Name: "Heavy Assault Cruisers", Level: 1},
+			"Spaceship Command":      {Name: "Spaceship Command", Level: 5},
+		}},
+	})
+	repo.On("GetSkillTypes").Return(prerequisiteSkillTypes())
+	s := skillplan.NewService(&testutil.MockLogger{}, repo)
+
+	plan, found := s.GetExpandedSkillPlan("HAC")
+	assert.True(t, found)
+	assert.Equal(t, map[string]model.Skill{
+		"Heavy Assault Cruisers": {Name: "Heavy Assault Cruisers", Level: 1},
+		"Caldari Cruiser":        {Name: "Caldari Cruiser", Level: 5},
+		"Spaceship Command":      {Name: "Spaceship Command", Level: 5},
+	}, plan.Skills)
+
+	_, found = s.GetExpandedSkillPlan("missing")
+	assert.False(t, found)
+}
+
+func TestGetRemapAdvice(t *testing.T) {
+	repo := prerequisiteRepo()
+	repo.On("GetSkillPlans").Return(map[string]model.SkillPlan{
+		"HAC": {Name: "HAC", Skills: map[string]model.Skill{
+			"Heavy Assault Cruisers": {Name: "Heavy Assault Cruisers", Level: 1},
+		}},
+	})
+	repo.On("GetSkillTypes").Return(prerequisiteSkillTypes())
+	s := skillplan.NewService(&testutil.MockLogger{}, repo)
+
+	character := model.Character{
+		UserInfoResponse: model.UserInfoResponse{CharacterID: 1, CharacterName: "Pilot"},
+		CharacterSkillsResponse: model.CharacterSkillsResponse{Skills: []model.SkillResponse{
+			{SkillID: 16591, TrainedSkillLevel: 1},
+			{SkillID: 3334, TrainedSkillLevel: 5},
+			{SkillID: 3327, TrainedSkillLevel: 3, SkillpointsInSkill: 16000},
+		}},
+		Attributes: &model.CharacterAttributes{Charisma: 20, Intelligence: 20, Memory: 20, Perception: 20, Willpower: 20, BonusRemaps: 1},
+	}
+
+	advice, err := s.GetRemapAdvice(character, model.Omega, "HAC")
+	assert.NoError(t, err)
+	assert.Equal(t, model.CharacterAttributes{Charisma: 17, Intelligence: 17, Memory: 17, Perception: 27, Willpower: 21}, advice.OptimalAttributes)
+	assert.Equal(t, int64(149020), advice.CurrentTrainingSeconds)
+	assert.Equal(t, int64(119216), advice.OptimalTrainingSeconds)
+	assert.Equal(t, int64(29804), advice.TimeSavedSeconds)
+	assert.True(t, advice.RemapAvailable)
+
+	_, err = s.GetRemapAdvice(character, model.Omega, "missing")
+	assert.Error(t, err)
+}
+
+func TestCompareTrainingScenario(t *testing.T) {
+	repo := prerequisiteRepo()
+	repo.On("GetSkillPlans").Return(map[string]model.SkillPlan{
+		"HAC": {Name: "HAC", Skills: map[string]model.Skill{
+			"Heavy Assault Cruisers": {Name: "Heavy Assault Cruisers", Level: 1},
+		}},
+	})
+	repo.On("GetSkillTypes").Return(prerequisiteSkillTypes())
+	// Ocular Filter - Basic: +3 perception
+	repo.On("GetAttributeBonus", int32(9899)).Return(model.CharacterAttributes{Perception: 3}, true)
+	s := skillplan.NewService(&testutil.MockLogger{}, repo)
+
+	character := model.Character{
+		UserInfoResponse: model.UserInfoResponse{CharacterID: 1, CharacterName: "Pilot"},
+		CharacterSkillsResponse: model.CharacterSkillsResponse{Skills: []model.SkillResponse{
+			{SkillID: 16591, TrainedSkillLevel: 1},
+			{SkillID: 3334, TrainedSkillLevel: 5},
+			{SkillID: 3327, TrainedSkillLevel: 3, SkillpointsInSkill: 16000},
+		}},
+		Attributes: &model.CharacterAttributes{Perception: 24, Willpower: 21},
+		Implants:   []int32{9899},
+	}
+
+	// Current: 27 + 21/2 with the +3 implant. Scenario: +5 implants and a +10
+	// accelerator give 39 + 36/2.
+	comparison, err := s.CompareTrainingScenario(character, model.Omega, "HAC", model.TrainingScenario{ImplantBonus: 5, AcceleratorBonus: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(119216), comparison.Current.TrainingSeconds)
+	assert.Equal(t, int64(78432), comparison.WithScenario.TrainingSeconds)
+	assert.Equal(t, int64(40784), comparison.TimeSavedSeconds)
+
+	_, err = s.CompareTrainingScenario(character, model.Omega, "missing", model.TrainingScenario{})
+	assert.Error(t, err)
+}
+
+func TestExportSkillQueue(t *testing.T) {
+	repo := prerequisiteRepo()
+	repo.On("GetSkillPlans").Return(map[string]model.SkillPlan{
+		"HAC": {Name: "HAC", Steps: []model.Skill{
+			{Name: "Heavy Assault Cruisers", Level: 2},
+			{Name: "Spaceship Command", Level: 5},
+		}},
+	})
+	repo.On("GetSkillTypes").Return(prerequisiteSkillTypes())
+	s := skillplan.NewService(&testutil.MockLogger{}, repo)
+
+	character := model.Character{
+		CharacterSkillsResponse: model.CharacterSkillsResponse{Skills: []model.SkillResponse{
+			{SkillID: 3327, TrainedSkillLevel: 3},
+			{SkillID: 3334, TrainedSkillLevel: 2},
+		}},
+		SkillQueue: []model.SkillQueue{{SkillID: 3334, FinishedLevel: 3}},
+	}
+
+	queue, err := s.ExportSkillQueue(character, model.Omega, "HAC")
+	assert.NoError(t, err)
+	assert.Equal(t, "Spaceship Command 4\n"+
+		"Caldari Cruiser 4\n"+
+		"Caldari Cruiser 5\n"+
+		"Heavy Assault Cruisers 1\n"+
+		"Heavy Assault Cruisers 2\n"+
+		"Spaceship Command 5\n", queue)
+
+	_, err = s.ExportSkillQueue(character, model.Omega, "missing")
+	assert.Error(t, err)
+}
+
+func TestPlanTrainingQueue(t *testing.T) {
+	repo := prerequisiteRepo()
+	repo.On("GetSkillPlans").Return(map[string]model.SkillPlan{
+		"Cruiser": {Name: "Cruiser", Steps: []model.Skill{{Name: "Caldari Cruiser", Level: 3}}},
+	})
+	repo.On("GetSkillTypes").Return(prerequisiteSkillTypes())
+	s := skillplan.NewService(&testutil.MockLogger{}, repo)
+
+	finish := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	character := model.Character{
+		CharacterSkillsResponse: model.CharacterSkillsResponse{Skills: []model.SkillResponse{
+			{SkillID: 3327, TrainedSkillLevel: 3, SkillpointsInSkill: 16000},
+			{SkillID: 3334, TrainedSkillLevel: 2},
+		}},
+		SkillQueue: []model.SkillQueue{{SkillID: 3334, FinishedLevel: 3, FinishDate: &finish}},
+		Attributes: &model.CharacterAttributes{Perception: 27, Willpower: 21},
+	}
+
+	steps, err := s.PlanTrainingQueue(character, model.Omega, "Cruiser")
+	require.NoError(t, err)
+	require.Len(t, steps, 2)
+
+	assert.Equal(t, "Spaceship Command", steps[0].SkillName)
+	assert.Equal(t, int32(4), steps[0].Level)
+	assert.Equal(t, int64(90510-16000), steps[0].SkillPoints)
+	assert.Positive(t, steps[0].TrainingSeconds)
+	assert.False(t, steps[0].Queued)
+
+	assert.Equal(t, "Caldari Cruiser", steps[1].SkillName)
+	assert.True(t, steps[1].Queued)
+	require.NotNil(t, steps[1].QueuedUntil)
+	assert.Equal(t, finish, *steps[1].QueuedUntil)
+
+	_, err = s.PlanTrainingQueue(character, model.Omega, "missing")
+	assert.Error(t, err)
+}
+
+func TestPlanTrainingQueue_PausedQueue(t *testing.T) {
+	repo := prerequisiteRepo()
+	repo.On("GetSkillPlans").Return(map[string]model.SkillPlan{
+		"Command": {Name: "Command", Steps: []model.Skill{{Name: "Spaceship Command", Level: 4}}},
+	})
+	repo.On("GetSkillTypes").Return(prerequisiteSkillTypes())
+	s := skillplan.NewService(&testutil.MockLogger{}, repo)
+
+	character := model.Character{
+		CharacterSkillsResponse: model.CharacterSkillsResponse{Skills: []model.SkillResponse{
+			{SkillID: 3327, TrainedSkillLevel: 3, SkillpointsInSkill: 16000},
+		}},
+		SkillQueue: []model.SkillQueue{{SkillID: 3327, FinishedLevel: 4}},
+		Attributes: &model.CharacterAttributes{Perception: 27, Willpower: 21},
+	}
+
+	steps, err := s.PlanTrainingQueue(character, model.Omega, "Command")
+	require.NoError(t, err)
+	require.Len(t, steps, 1)
+	assert.True(t, steps[0].Queued)
+	assert.Nil(t, steps[0].QueuedUntil)
+	assert.Equal(t, int64(90510-16000), steps[0].SkillPoints)
+	assert.Positive(t, steps[0].TrainingSeconds, "a paused level still has to be trained")
+}
+
+func TestPlanTrainingQueue_Alpha(t *testing.T) {
+	repo := prerequisiteRepo()
+	repo.On("GetSkillPlans").Return(map[string]model.SkillPlan{
+		"Command": {Name: "Command", Steps: []model.Skill{{Name: "Spaceship Command", Level: 4}}},
+	})
+	repo.On("GetSkillTypes").Return(prerequisiteSkillTypes())
+	repo.On("GetAlphaSkillCap", int32(3327)).Return(int32(3), true)
+	s := skillplan.NewService(&testutil.MockLogger{}, repo)
+
+	character := model.Character{
+		CharacterSkillsResponse: model.CharacterSkillsResponse{Skills: []model.SkillResponse{
+			{SkillID: 3327, TrainedSkillLevel: 2, SkillpointsInSkill: 2829},
+		}},
+		Attributes: &model.CharacterAttributes{Perception: 27, Willpower: 21},
+	}
+
+	omega, err := s.PlanTrainingQueue(character, model.Omega, "Command")
+	require.NoError(t, err)
+	alpha, err := s.PlanTrainingQueue(character, model.Alpha, "Command")
+	require.NoError(t, err)
+	require.Len(t, omega, 2)
+	require.Len(t, alpha, 2)
+
+	assert.False(t, alpha[0].OmegaOnly)
+	assert.InDelta(t, 2*omega[0].TrainingSeconds, alpha[0].TrainingSeconds, 1, "Alphas train at half speed")
+	assert.True(t, alpha[1].OmegaOnly, "Spaceship Command IV is above the Alpha cap")
+	assert.Zero(t, alpha[1].TrainingSeconds)
+	assert.False(t, omega[1].OmegaOnly)
+
+	queue, err := s.ExportSkillQueue(character, model.Alpha, "Command")
+	require.NoError(t, err)
+	assert.Equal(t, "Spaceship Command 3\n", queue)
+}
+
+func TestImportEVEMonPlan(t *testing.T) {
+	repo := prerequisiteRepo()
+	repo.On("GetSkillPlans").Return(map[string]model.SkillPlan{})
+	repo.On("GetSkillTypeByID", "99999").Return(model.SkillType{}, false)
+	repo.On("SaveSkillPlan", "HAC", []byte("Spaceship Command 4\nHeavy Assault Cruisers 1\n"), model.PlanSourceImport).Return(nil)
+	s := skillplan.NewService(&testutil.MockLogger{}, repo)
+
+	name, err := s.ImportEVEMonPlan([]byte(`<plan name="HAC">
+  <entry skillID="3327" skill="Spaceship Command" level="4" />
+  <entry skillID="99999" skill="Removed Skill" level="1" />
+  <entry skillID="16591" skill="Heavy Assault Cruisers" level="1" />
+</plan>`), "")
+	assert.NoError(t, err)
+	assert.Equal(t, "HAC", name)
+	repo.AssertCalled(t, "SaveSkillPlan", "HAC", []byte("Spaceship Command 4\nHeavy Assault Cruisers 1\n"), model.PlanSourceImport)
+
+	_, err = s.ImportEVEMonPlan([]byte("not xml"), "HAC")
+	assert.Error(t, err)
+}
+
+func TestImportEVEMonPlan_RejectsUnsafeNames(t *testing.T) {
+	repo := prerequisiteRepo()
+	repo.On("GetSkillPlans").Return(map[string]model.SkillPlan{})
+	s := skillplan.NewService(&testutil.MockLogger{}, repo)
+
+	for _, name := range []string{"../../x", `..\x`, "a/b", "bad\x00name"} {
+		_, err := s.ImportEVEMonPlan([]byte(`<plan name="`+name+`"><entry skillID="3327" skill="Spaceship Command" level="4" /></plan>`), "")
+		var customErr *flyErrors.CustomError
+		require.ErrorAs(t, err, &customErr, name)
+		assert.Equal(t, http.StatusBadRequest, customErr.StatusCode, name)
+
+		err = s.ParseAndSaveSkillPlan("Spaceship Command 4\n", name, model.PlanSourceUser)
+		require.ErrorAs(t, err, &customErr, name)
+		assert.Equal(t, http.StatusBadRequest, customErr.StatusCode, name)
+	}
+	repo.AssertNotCalled(t, "SaveSkillPlan", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestExportEVEMonPlan(t *testing.T) {
+	repo := prerequisiteRepo()
+	repo.On("GetSkillPlans").Return(map[string]model.SkillPlan{
+		"HAC": {Name: "HAC", Steps: []model.Skill{
+			{Name: "Spaceship Command", Level: 4},
+			{Name: "Heavy Assault Cruisers", Level: 1},
+		}},
+	})
+	repo.On("GetSkillTypes").Return(prerequisiteSkillTypes())
+	s := skillplan.NewService(&testutil.MockLogger{}, repo)
+
+	data, err := s.ExportEVEMonPlan("HAC")
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `<plan name="HAC">`)
+	assert.Contains(t, string(data), `<entry skillID="3327" skill="Spaceship Command" level="4" priority="3" type="Planned"></entry>`)
+	assert.Contains(t, string(data), `<entry skillID="16591" skill="Heavy Assault Cruisers" level="1" priority="3" type="Planned"></entry>`)
+
+	_, err = s.ExportEVEMonPlan("missing")
+	assert.Error(t, err)
+}
+
+func TestExportTextPlan(t *testing.T) {
+	repo := &testutil.MockSkillRepository{}
+	// The stored plan includes another; its steps are already expanded.
+	repo.On("GetSkillPlans").Return(map[string]model.SkillPlan{
+		"HAC": {Name: "HAC", Steps: []model.Skill{
+			{Name: "Spaceship Command", Level: 4},
+			{Name: "Heavy Assault Cruisers", Level: 1},
+		}},
+	})
+	s := skillplan.NewService(&testutil.MockLogger{}, repo)
+
+	data, err := s.ExportTextPlan("HAC")
+	require.NoError(t, err)
+	assert.Equal(t, "Spaceship Command 4\nHeavy Assault Cruisers 1\n", string(data))
+
+	_, err = s.ExportTextPlan("missing")
+	var customErr *flyErrors.CustomError
+	require.ErrorAs(t, err, &customErr)
+	assert.Equal(t, http.StatusNotFound, customErr.StatusCode)
+}
+
+// fittingRepo extends prerequisiteRepo with a Cerberus, which needs Heavy
+// Assault Cruisers 1 and Caldari Cruiser 5, and a Damage Control II.
+func fittingRepo() *testutil.MockSkillRepository {
+	repo := &testutil.MockSkillRepository{}
+	skillTypes := prerequisiteSkillTypes()
+	skillTypes["Cerberus"] = model.SkillType{TypeID: "11993", TypeName: "Cerberus"}
+	skillTypes["Damage Control II"] = model.SkillType{TypeID: "2048", TypeName: "Damage Control II"}
+	repo.On("GetSkillTypes").Return(skillTypes)
+	repo.On("GetSkillPrerequisites", int32(11993)).Return([]model.SkillPrerequisite{{SkillID: 16591, Level: 1}, {SkillID: 3334, Level: 5}})
+	repo.On("GetSkillPrerequisites", int32(2048)).Return([]model.SkillPrerequisite{{SkillID: 3327, Level: 2}, {SkillID: 16591, Level: 2}})
+	repo.On("IsShipType", int32(11993)).Return(true, true)
+	repo.On("IsShipType", mock.Anything).Return(false, true)
+	// Registered last so the catch-all expectations don't shadow the ones above.
+	repo.ExpectedCalls = append(repo.ExpectedCalls, prerequisiteRepo().ExpectedCalls...)
+	return repo
+}
+
+const cerberusFit = `[Cerberus, Fleet]
+Damage Control II
+Unobtainium Launcher
+
+Warrior II x2`
+
+func TestPlanFromFitting(t *testing.T) {
+	repo := fittingRepo()
+	repo.On("GetSkillPlans").Return(map[string]model.SkillPlan{})
+	repo.On("SaveSkillPlan", "Cerberus - Fleet", []byte("Heavy Assault Cruisers 1\nCaldari Cruiser 5\nSpaceship Command 2\nHeavy Assault Cruisers 2\n"), model.PlanSourceFitting).Return(nil)
+	s := skillplan.NewService(&testutil.MockLogger{}, repo)
+
+	plan, unknown, err := s.SaveFittingPlan(cerberusFit, "")
+	assert.NoError(t, err)
+	assert.Equal(t, "Cerberus - Fleet", plan.Name)
+	assert.Equal(t, []string{"Unobtainium Launcher", "Warrior II"}, unknown)
+	assert.Equal(t, map[string]model.Skill{
+		"Heavy Assault Cruisers": {Name: "Heavy Assault Cruisers", Level: 2},
+		"Caldari Cruiser":        {Name: "Caldari Cruiser", Level: 5},
+		"Spaceship Command":      {Name: "Spaceship Command", Level: 2},
+	}, plan.Skills)
+	repo.AssertCalled(t, "SaveSkillPlan", "Cerberus - Fleet", mock.Anything, model.PlanSourceFitting)
+
+	_, _, err = s.PlanFromFitting("[Unknown Ship, Fit]")
+	assert.Error(t, err)
+}
+
+func TestPlanFromFitting_NeedsLoadedShip(t *testing.T) {
+	s := skillplan.NewService(&testutil.MockLogger{}, fittingRepo())
+	var customErr *flyErrors.CustomError
+
+	_, _, err := s.PlanFromFitting("[Damage Control II, Not A Ship]")
+	require.ErrorAs(t, err, &customErr)
+	assert.Equal(t, http.StatusBadRequest, customErr.StatusCode)
+
+	// Without the SDE attributes every fitting would need no skills.
+	repo := &testutil.MockSkillRepository{}
+	repo.On("IsShipType", mock.Anything).Return(false, false)
+	repo.ExpectedCalls = append(repo.ExpectedCalls, fittingRepo().ExpectedCalls...)
+	s = skillplan.NewService(&testutil.MockLogger{}, repo)
+
+	_, err = s.EvaluateFitting(nil, cerberusFit)
+	require.ErrorAs(t, err, &customErr)
+	assert.Equal(t, http.StatusServiceUnavailable, customErr.StatusCode)
+}
+
+func TestSaveFittingPlan_RejectsUnsafeNames(t *testing.T) {
+	repo := fittingRepo()
+	repo.On("GetSkillPlans").Return(map[string]model.SkillPlan{})
+	s := skillplan.NewService(&testutil.MockLogger{}, repo)
+
+	// The name comes from the pasted EFT header unless one is given.
+	_, _, err := s.SaveFittingPlan("[Cerberus, ../../x]\nDamage Control II", "")
+	var customErr *flyErrors.CustomError
+	require.ErrorAs(t, err, &customErr)
+	assert.Equal(t, http.StatusBadRequest, customErr.StatusCode)
+
+	_, _, err = s.SaveFittingPlan(cerberusFit, `..\plans`)
+	require.ErrorAs(t, err, &customErr)
+	assert.Equal(t, http.StatusBadRequest, customErr.StatusCode)
+
+	repo.AssertNotCalled(t, "SaveSkillPlan", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestEvaluateFitting_DoesNotTouchCharacters(t *testing.T) {
+	s := skillplan.NewService(&testutil.MockLogger{}, fittingRepo())
+
+	character := model.Character{
+		UserInfoResponse: model.UserInfoResponse{CharacterID: 1, CharacterName: "Pilot"},
+		CharacterSkillsResponse: model.CharacterSkillsResponse{Skills: []model.SkillResponse{
+			{SkillID: 16591, TrainedSkillLevel: 2},
+			{SkillID: 3334, TrainedSkillLevel: 5},
+			{SkillID: 3327, TrainedSkillLevel: 5},
+		}},
+		QualifiedPlans: map[string]bool{"HAC": true},
+	}
+	accounts := []model.Account{{Characters: []model.CharacterIdentity{{Character: character}}}}
+
+	evaluation, err := s.EvaluateFitting(accounts, cerberusFit)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Pilot"}, evaluation.Plan.QualifiedCharacters)
+	assert.Equal(t, map[string]bool{"HAC": true}, accounts[0].Characters[0].Character.QualifiedPlans)
+}
+
+func TestGetShipStatus(t *testing.T) {
+	s := skillplan.NewService(&testutil.MockLogger{}, fittingRepo())
+
+	qualified := model.Character{
+		UserInfoResponse: model.UserInfoResponse{CharacterID: 1, CharacterName: "Veteran"},
+		CharacterSkillsResponse: model.CharacterSkillsResponse{Skills: []model.SkillResponse{
+			{SkillID: 16591, TrainedSkillLevel: 1},
+			{SkillID: 3334, TrainedSkillLevel: 5},
+			{SkillID: 3327, TrainedSkillLevel: 4},
+		}},
+	}
+	missing := model.Character{
+		UserInfoResponse: model.UserInfoResponse{CharacterID: 2, CharacterName: "Rookie"},
+		CharacterSkillsResponse: model.CharacterSkillsResponse{Skills: []model.SkillResponse{
+			{SkillID: 3334, TrainedSkillLevel: 4},
+		}},
+	}
+	accounts := []model.Account{{Characters: []model.CharacterIdentity{{Character: qualified}, {Character: missing}}}}
+
+	status, err := s.GetShipStatus(accounts, "Cerberus")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(11993), status.TypeId)
+	assert.Equal(t, []string{"Veteran"}, status.QualifiedCharacters)
+	assert.Equal(t, []string{"Rookie"}, status.MissingCharacters)
+	assert.Equal(t, map[string]int32{
+		"Heavy Assault Cruisers": 1,
+		"Caldari Cruiser":        5,
+		"Spaceship Command":      4,
+	}, status.MissingSkills["Rookie"])
+
+	var customErr *flyErrors.CustomError
+	_, err = s.GetShipStatus(accounts, "Titan Of Nothing")
+	require.ErrorAs(t, err, &customErr)
+	assert.Equal(t, http.StatusNotFound, customErr.StatusCode)
+
+	_, err = s.GetShipStatus(accounts, "Damage Control II")
+	require.ErrorAs(t, err, &customErr)
+	assert.Equal(t, http.StatusBadRequest, customErr.StatusCode, "not a ship")
+
+	repo := &testutil.MockSkillRepository{}
+	repo.On("IsShipType", mock.Anything).Return(false, false)
+	repo.ExpectedCalls = append(repo.ExpectedCalls, fittingRepo().ExpectedCalls...)
+	s = skillplan.NewService(&testutil.MockLogger{}, repo)
+	_, err = s.GetShipStatus(accounts, "Cerberus")
+	require.ErrorAs(t, err, &customErr)
+	assert.Equal(t, http.StatusServiceUnavailable, customErr.StatusCode, "SDE attributes not loaded")
+}
+
+func TestValidateSkillPlan(t *testing.T) {
+	repo := &testutil.MockSkillRepository{}
+	repo.On("GetSkillAttributes", int32(3334)).Return(model.SkillAttributes{Rank: 5, PrimaryAttribute: 167, SecondaryAttribute: 168}, true)
+	repo.On("GetSkillPlans").Return(map[string]model.SkillPlan{"Magic 14": {Name: "Magic 14"}})
+	repo.ExpectedCalls = append(repo.ExpectedCalls, fittingRepo().ExpectedCalls...)
+	s := skillplan.NewService(&testutil.MockLogger{}, repo)
+
+	validation := s.ValidateSkillPlan("Cruisers", "Spaceship Command 4\nSpaceshp Command 3\nCerberus 1\nCaldari Cruiser VI\n# include: Magic 41\n# include: Magic 14\n")
+
+	assert.False(t, validation.Valid)
+	assert.Equal(t, []model.PlanDiagnostic{
+		{Line: 2, Text: "Spaceshp Command 3", Problem: "unknown skill Spaceshp Command", Suggestions: []string{"Spaceship Command"}},
+		{Line: 3, Text: "Cerberus 1", Problem: "Cerberus is not a skill", Suggestions: []string{}},
+		{Line: 4, Text: "Caldari Cruiser VI", Problem: `invalid skill level "VI"`, IsSkill: true},
+		{Line: 5, Text: "# include: Magic 41", Problem: "included plan Magic 41 not found", Suggestions: []string{"Magic 14"}},
+	}, validation.Diagnostics)
+
+	assert.True(t, s.ValidateSkillPlan("Cruisers", "Spaceship Command 4\n# include: Magic 14\n").Valid)
+}
+
+func TestGetPlanAndConversionData_MinimumAndRecommended(t *testing.T) {
+	s := skillplan.NewService(&testutil.MockLogger{}, prerequisiteRepo())
+
+	plans := map[string]model.SkillPlan{
+		"Command": {
+			Name:     "Command",
+			Skills:   map[string]model.Skill{"Spaceship Command": {Name: "Spaceship Command", Level: 5, Required: 3}},
+			Metadata: model.PlanMetadata{ShipTypeID: 11993},
+		},
+	}
+	pilot := func(name string, level int32) model.CharacterIdentity {
+		return model.CharacterIdentity{Character: model.Character{
+			UserInfoResponse: model.UserInfoResponse{CharacterName: name},
+			CharacterSkillsResponse: model.CharacterSkillsResponse{Skills: []model.SkillResponse{
+				{SkillID: 3327, TrainedSkillLevel: level},
+			}},
+		}}
+	}
+	accounts := []model.Account{{Characters: []model.CharacterIdentity{
+		pilot("Novice", 2), pilot("Minimum", 4), pilot("Veteran", 5),
+	}}}
+
+	result, _ := s.GetPlanAndConversionData(accounts, plans, prerequisiteSkillTypes())
+
+	plan := result["Command"]
+	assert.Equal(t, int64(11993), plan.TypeId)
+	assert.Equal(t, []string{"Minimum", "Veteran"}, plan.MinimumCharacters)
+	assert.Equal(t, []string{"Veteran"}, plan.QualifiedCharacters)
+	assert.Equal(t, []string{"Novice", "Minimum"}, plan.MissingCharacters)
+	for _, status := range plan.Characters {
+		assert.Equal(t, status.CharacterName != "Novice", status.MeetsMinimum, status.CharacterName)
+		assert.Equal(t, status.CharacterName == "Veteran", status.MeetsRecommended, status.CharacterName)
+	}
+}
+
+func TestDiffAndRestoreSkillPlan(t *testing.T) {
+	repo := &testutil.MockSkillRepository{}
+	repo.On("GetSkillPlanVersion", "HAC", "100").Return([]byte("Spaceship Command 3\nCaldari Cruiser 4\n"), nil)
+	repo.On("GetSkillPlanVersion", "HAC", "999").Return(nil, fmt.Errorf("version 999: %w", os.ErrNotExist))
+	repo.On("GetSkillPlanFile", "HAC").Return([]byte("Spaceship Command 4\nHeavy Assault Cruisers 1\n"), nil)
+	repo.On("SaveSkillPlan", "HAC", []byte("Spaceship Command 3\nCaldari Cruiser 4\n"), model.PlanSourceRestore).Return(nil)
+	s := skillplan.NewService(&testutil.MockLogger{}, repo)
+
+	diff, err := s.DiffSkillPlan("HAC", "100", "")
+	require.NoError(t, err)
+	assert.Equal(t, []model.Skill{{Name: "Heavy Assault Cruisers", Level: 1}}, diff.Added)
+	assert.Equal(t, []model.Skill{{Name: "Caldari Cruiser", Level: 4}}, diff.Removed)
+	assert.Equal(t, []model.SkillLevelChange{{Name: "Spaceship Command", FromLevel: 3, ToLevel: 4}}, diff.Raised)
+	assert.Empty(t, diff.Lowered)
+
+	_, err = s.DiffSkillPlan("HAC", "999", "")
+	var customErr *flyErrors.CustomError
+	require.ErrorAs(t, err, &customErr)
+	assert.Equal(t, http.StatusNotFound, customErr.StatusCode)
+
+	require.NoError(t, s.RestoreSkillPlan("HAC", "100"))
+	repo.AssertCalled(t, "SaveSkillPlan", "HAC", []byte("Spaceship Command 3\nCaldari Cruiser 4\n"), model.PlanSourceRestore)
+}
+
+func TestSkillPlanHistory_RejectsUnsafeNames(t *testing.T) {
+	repo := &testutil.MockSkillRepository{}
+	s := skillplan.NewService(&testutil.MockLogger{}, repo)
+	var customErr *flyErrors.CustomError
+
+	_, err := s.GetSkillPlanHistory("../config")
+	require.ErrorAs(t, err, &customErr)
+	assert.Equal(t, http.StatusBadRequest, customErr.StatusCode)
+
+	_, err = s.DiffSkillPlan(`..\plans`, "100", "")
+	require.ErrorAs(t, err, &customErr)
+	assert.Equal(t, http.StatusBadRequest, customErr.StatusCode)
+
+	err = s.RestoreSkillPlan("../config", "100")
+	require.ErrorAs(t, err, &customErr)
+	assert.Equal(t, http.StatusBadRequest, customErr.StatusCode)
+
+	repo.AssertNotCalled(t, "GetSkillPlanHistory", mock.Anything)
+	repo.AssertNotCalled(t, "GetSkillPlanVersion", mock.Anything, mock.Anything)
+}
+
+func TestRecommendNextSkills(t *testing.T) {
+	repo := &testutil.MockSkillRepository{}
+	skillTypes := prerequisiteSkillTypes()
+	skillTypes["Drones"] = model.SkillType{TypeID: "3436", TypeName: "Drones"}
+	repo.On("GetSkillTypes").Return(skillTypes)
+	repo.On("GetSkillPlans").Return(map[string]model.SkillPlan{
+		"Command": {Name: "Command", Skills: map[string]model.Skill{"Spaceship Command": {Name: "Spaceship Command", Level: 4}}},
+		"Cruiser": {Name: "Cruiser", Skills: map[string]model.Skill{"Caldari Cruiser": {Name: "Caldari Cruiser", Level: 1}}},
+		"Drones": {Name: "Drones", Skills: map[string]model.Skill{"Drones": {Name: "Drones", Level: 1}},
+			Metadata: model.PlanMetadata{Tags: []string{"drones"}}},
+	})
+	repo.On("GetSkillAttributes", int32(3334)).Return(model.SkillAttributes{Rank: 5, PrimaryAttribute: 167, SecondaryAttribute: 168}, true)
+	repo.On("GetSkillAttributes", int32(3436)).Return(model.SkillAttributes{Rank: 1, PrimaryAttribute: 166, SecondaryAttribute: 168}, true)
+	repo.ExpectedCalls = append(repo.ExpectedCalls, prerequisiteRepo().ExpectedCalls...)
+	s := skillplan.NewService(&testutil.MockLogger{}, repo)
+
+	character := model.Character{
+		CharacterSkillsResponse: model.CharacterSkillsResponse{Skills: []model.SkillResponse{
+			{SkillID: 3327, TrainedSkillLevel: 3, SkillpointsInSkill: 16000},
+		}},
+		Attributes: &model.CharacterAttributes{Perception: 27, Willpower: 21},
+	}
+
+	recommendations := s.RecommendNextSkills(character, model.Omega, model.SkillRecommendationRequest{
+		TagWeights: map[string]float64{"Drones": 0},
+	})
+
+	// Caldari Cruiser needs Spaceship Command 4 first, and the Drones plan is
+	// weighted out, so Spaceship Command 4 is the only suggestion.
+	require.Len(t, recommendations, 1)
+	recommendation := recommendations[0]
+	assert.Equal(t, "Spaceship Command", recommendation.SkillName)
+	assert.Equal(t, int32(4), recommendation.Level)
+	assert.Equal(t, int64(74510), recommendation.SkillPoints)
+	assert.Equal(t, int64(119216), recommendation.TrainingSeconds)
+	assert.Equal(t, []string{"Command"}, recommendation.UnlocksPlans)
+	assert.Equal(t, []model.PlanAdvance{
+		{PlanName: "Command", ProgressGained: 82.3},
+		{PlanName: "Cruiser", ProgressGained: 81.2},
+	}, recommendation.AdvancesPlans)
+	assert.Greater(t, recommendation.Score, 0.0)
+
+	recommendations = s.RecommendNextSkills(character, model.Omega, model.SkillRecommendationRequest{Limit: 1})
+	require.Len(t, recommendations, 1)
+	assert.Equal(t, "Drones", recommendations[0].SkillName, "a quick level completing a plan ranks first")
+}
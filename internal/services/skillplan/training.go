@@ -0,0 +1,306 @@
+package skillplan
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+
+	flyErrors "github.com/guarzo/canifly/internal/errors"
+	"github.com/guarzo/canifly/internal/model"
+)
+
+// Dogma attribute IDs of the five character attributes, as referenced by a
+// skill's primary and secondary attribute.
+const (
+	attributeCharisma     int32 = 164
+	attributeIntelligence int32 = 165
+	attributeMemory       int32 = 166
+	attributePerception   int32 = 167
+	attributeWillpower    int32 = 168
+)
+
+// defaultAttributes are the attributes of a character that has never remapped.
+// They are used until ESI attributes have been fetched for a character.
+var defaultAttributes = model.CharacterAttributes{
+	Charisma:     19,
+	Intelligence: 20,
+	Memory:       20,
+	Perception:   20,
+	Willpower:    20,
+}
+
+// alphaTrainingSpeed is the fraction of the Omega training rate an Alpha clone
+// trains at.
+const alphaTrainingSpeed = 0.5
+
+// skillPointsPerRank is the total SP each skill level needs per point of
+// rank: 250 * sqrt(32)^(level-1), rounded as the game does. It is kept as
+// integers because the float formula lands one SP high on levels 3 and 5.
+var skillPointsPerRank = [...]int64{0, 250, 1415, 8000, 45255, 256000}
+
+// skillPointsForLevel returns the total SP a skill of the given rank needs to
+// reach level.
+func skillPointsForLevel(rank, level int32) int64 {
+	if level <= 0 {
+		return 0
+	}
+	level = min(level, int32(len(skillPointsPerRank)-1))
+	return skillPointsPerRank[level] * int64(rank)
+}
+
+func attributeValue(attrs model.CharacterAttributes, attributeID int32) float64 {
+	switch attributeID {
+	case attributeCharisma:
+		return float64(attrs.Charisma)
+	case attributeIntelligence:
+		return float64(attrs.Intelligence)
+	case attributeMemory:
+		return float64(attrs.Memory)
+	case attributePerception:
+		return float64(attrs.Perception)
+	case attributeWillpower:
+		return float64(attrs.Willpower)
+	}
+	return 0
+}
+
+// addAttributes returns the attribute-wise sum of base and bonus.
+func addAttributes(base, bonus model.CharacterAttributes) model.CharacterAttributes {
+	return model.CharacterAttributes{
+		Charisma:     base.Charisma + bonus.Charisma,
+		Intelligence: base.Intelligence + bonus.Intelligence,
+		Memory:       base.Memory + bonus.Memory,
+		Perception:   base.Perception + bonus.Perception,
+		Willpower:    base.Willpower + bonus.Willpower,
+	}
+}
+
+// skillPointsPerMinute is the training rate of a skill: primary + secondary/2.
+func skillPointsPerMinute(attrs model.CharacterAttributes, skillAttrs model.SkillAttributes) float64 {
+	return attributeValue(attrs, skillAttrs.PrimaryAttribute) + attributeValue(attrs, skillAttrs.SecondaryAttribute)/2
+}
+
+func trainingSeconds(skillPoints int64, perMinute float64) int64 {
+	if skillPoints <= 0 || perMinute <= 0 {
+		return 0
+	}
+	return int64(math.Ceil(float64(skillPoints) / perMinute * 60))
+}
+
+// trainingProfile is the per-character state needed to estimate training time.
+// attributes are the character's base attributes; bonuses are what the active
+// clone's implants add on top of them. Characters on Alpha accounts train at
+// half speed and only up to the Alpha skill caps.
+type trainingProfile struct {
+	attributes  model.CharacterAttributes
+	bonuses     model.CharacterAttributes
+	levels      map[int32]int32
+	skillPoints map[int32]int64
+	alpha       bool
+}
+
+func (s *Service) newTrainingProfile(character model.Character, status model.AccountStatus) trainingProfile {
+	profile := trainingProfile{
+		attributes:  defaultAttributes,
+		levels:      make(map[int32]int32, len(character.Skills)),
+		skillPoints: make(map[int32]int64, len(character.Skills)),
+		alpha:       status == model.Alpha,
+	}
+	if character.Attributes != nil {
+		profile.attributes = *character.Attributes
+	}
+	for _, implantID := range character.Implants {
+		if bonus, found := s.skillRepo.GetAttributeBonus(implantID); found {
+			profile.bonuses = addAttributes(profile.bonuses, bonus)
+		}
+	}
+	for _, skill := range character.Skills {
+		profile.levels[skill.SkillID] = skill.TrainedSkillLevel
+		profile.skillPoints[skill.SkillID] = skill.SkillpointsInSkill
+	}
+	return profile
+}
+
+// effectiveAttributes are the attributes skills actually train with.
+func (p trainingProfile) effectiveAttributes() model.CharacterAttributes {
+	return addAttributes(p.attributes, p.bonuses)
+}
+
+// trainingSpeed is the fraction of the full training rate the character gets.
+func (p trainingProfile) trainingSpeed() float64 {
+	if p.alpha {
+		return alphaTrainingSpeed
+	}
+	return 1
+}
+
+// skillPointsPerMinute is the rate the character trains a skill at.
+func (p trainingProfile) skillPointsPerMinute(skillAttrs model.SkillAttributes) float64 {
+	return skillPointsPerMinute(p.effectiveAttributes(), skillAttrs) * p.trainingSpeed()
+}
+
+// alphaCap returns the highest level of a skill the character can use. ok is
+// false for Omega characters and when the Alpha caps are not loaded.
+func (s *Service) alphaCap(profile trainingProfile, skillID int32) (level int32, ok bool) {
+	if !profile.alpha {
+		return 0, false
+	}
+	return s.skillRepo.GetAlphaSkillCap(skillID)
+}
+
+// trainableLevel caps a target level at what the character can train.
+func (s *Service) trainableLevel(profile trainingProfile, skillID, level int32) int32 {
+	if skillCap, capped := s.alphaCap(profile, skillID); capped {
+		return min(level, skillCap)
+	}
+	return level
+}
+
+// estimateTraining returns the SP and time a character still needs to train the
+// given missing skills. Skills without SDE attributes are left out, and Alpha
+// characters are only estimated up to the Alpha skill caps.
+func (s *Service) estimateTraining(
+	profile trainingProfile,
+	missingSkills map[string]int32,
+	skillTypes map[string]model.SkillType,
+) *model.PlanTrainingEstimate {
+	estimate := &model.PlanTrainingEstimate{Skills: []model.SkillTrainingEstimate{}}
+
+	for skillName, targetLevel := range missingSkills {
+		skillType, exists := skillTypes[skillName]
+		if !exists {
+			continue
+		}
+		skillID, err := strconv.Atoi(skillType.TypeID)
+		if err != nil {
+			continue
+		}
+		skillAttrs, found := s.skillRepo.GetSkillAttributes(int32(skillID))
+		if !found {
+			s.logger.Debugf("No training attributes for skill %s; skipping estimate", skillName)
+			continue
+		}
+
+		targetLevel = s.trainableLevel(profile, int32(skillID), targetLevel)
+		if targetLevel <= profile.levels[int32(skillID)] {
+			continue
+		}
+
+		remaining := skillPointsForLevel(skillAttrs.Rank, targetLevel) - profile.skillPoints[int32(skillID)]
+		if remaining < 0 {
+			remaining = 0
+		}
+		seconds := trainingSeconds(remaining, profile.skillPointsPerMinute(skillAttrs))
+
+		estimate.Skills = append(estimate.Skills, model.SkillTrainingEstimate{
+			SkillName:       skillName,
+			CurrentLevel:    profile.levels[int32(skillID)],
+			TargetLevel:     targetLevel,
+			RemainingSP:     remaining,
+			TrainingSeconds: seconds,
+		})
+		estimate.RemainingSP += remaining
+		estimate.TrainingSeconds += seconds
+	}
+
+	sort.Slice(estimate.Skills, func(i, j int) bool {
+		return estimate.Skills[i].SkillName < estimate.Skills[j].SkillName
+	})
+	return estimate
+}
+
+// planProgress returns the SP a character has trained toward each plan skill's
+// target level, capped at that level, and the SP all target levels need.
+// Skills without SDE attributes are left out.
+func (s *Service) planProgress(
+	profile trainingProfile,
+	skills map[string]model.Skill,
+	skillTypes map[string]model.SkillType,
+) (trained, required int64) {
+	for skillName, skill := range skills {
+		skillType, exists := skillTypes[skillName]
+		if !exists {
+			continue
+		}
+		skillID, err := strconv.Atoi(skillType.TypeID)
+		if err != nil {
+			continue
+		}
+		skillAttrs, found := s.skillRepo.GetSkillAttributes(int32(skillID))
+		if !found {
+			continue
+		}
+
+		target := skillPointsForLevel(skillAttrs.Rank, int32(skill.Level))
+		required += target
+		trained += min(profile.skillPoints[int32(skillID)], target)
+	}
+	return trained, required
+}
+
+// progressPercent is trained as a percentage of required, to one decimal.
+// A plan with no known SP requirement is complete once it qualifies.
+func progressPercent(trained, required int64, qualifies bool) float64 {
+	if required == 0 {
+		if qualifies {
+			return 100
+		}
+		return 0
+	}
+	return math.Round(float64(trained)/float64(required)*1000) / 10
+}
+
+// untrainedPlanSkills returns every skill, prerequisites included, that a
+// character has not trained to the level a plan requires. Queued skills still
+// have to be trained, so the skill queue is ignored.
+func (s *Service) untrainedPlanSkills(character model.Character, planName string) (map[string]int32, map[string]model.SkillType, error) {
+	plan, exists := s.skillRepo.GetSkillPlans()[planName]
+	if !exists {
+		return nil, nil, flyErrors.NewCustomError(http.StatusNotFound, fmt.Sprintf("skill plan %s not found", planName))
+	}
+	skillTypes := s.skillRepo.GetSkillTypes()
+	plan.Skills = s.expandPrerequisites(plan.Skills, skillTypes)
+
+	var typeIds []int32
+	result := s.evaluatePlanForCharacter(plan, skillTypes, s.mapCharacterSkills(character, &typeIds), nil)
+	return result.MissingSkills, skillTypes, nil
+}
+
+// CompareTrainingScenario estimates the training a character needs for a plan
+// as they are today and with the scenario's implants and accelerator plugged in.
+func (s *Service) CompareTrainingScenario(
+	character model.Character,
+	status model.AccountStatus,
+	planName string,
+	scenario model.TrainingScenario,
+) (*model.TrainingScenarioComparison, error) {
+	missingSkills, skillTypes, err := s.untrainedPlanSkills(character, planName)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := s.newTrainingProfile(character, status)
+	current := s.estimateTraining(profile, missingSkills, skillTypes)
+
+	// Scenario implants replace weaker ones in the same slot; the accelerator
+	// stacks on top of whatever implants are plugged in.
+	profile.bonuses = model.CharacterAttributes{
+		Charisma:     max(profile.bonuses.Charisma, scenario.ImplantBonus) + scenario.AcceleratorBonus,
+		Intelligence: max(profile.bonuses.Intelligence, scenario.ImplantBonus) + scenario.AcceleratorBonus,
+		Memory:       max(profile.bonuses.Memory, scenario.ImplantBonus) + scenario.AcceleratorBonus,
+		Perception:   max(profile.bonuses.Perception, scenario.ImplantBonus) + scenario.AcceleratorBonus,
+		Willpower:    max(profile.bonuses.Willpower, scenario.ImplantBonus) + scenario.AcceleratorBonus,
+	}
+	withScenario := s.estimateTraining(profile, missingSkills, skillTypes)
+
+	return &model.TrainingScenarioComparison{
+		CharacterName:    character.CharacterName,
+		PlanName:         planName,
+		Scenario:         scenario,
+		Current:          *current,
+		WithScenario:     *withScenario,
+		TimeSavedSeconds: current.TrainingSeconds - withScenario.TrainingSeconds,
+	}, nil
+}
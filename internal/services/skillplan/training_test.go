@@ -0,0 +1,15 @@
+package skillplan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSkillPointsForLevel(t *testing.T) {
+	assert.Equal(t, int64(0), skillPointsForLevel(1, 0))
+	assert.Equal(t, int64(8000), skillPointsForLevel(1, 3))
+	assert.Equal(t, int64(256000), skillPointsForLevel(1, 5))
+	assert.Equal(t, int64(16000), skillPointsForLevel(2, 3))
+	assert.Equal(t, int64(512000), skillPointsForLevel(2, 5))
+}
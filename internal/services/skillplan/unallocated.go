@@ -0,0 +1,70 @@
+package skillplan
+
+import (
+	"strconv"
+
+	"github.com/guarzo/canifly/internal/model"
+)
+
+// applyUnallocatedSP checks a missing character's unallocated SP against the
+// SP the plan still needs. When it covers all of it, the character qualifies
+// as soon as the SP is allocated and the result carries the allocation;
+// otherwise only the share of the gap it closes is recorded. Plans with a
+// missing skill that has no SDE rank are never reported as covered, since
+// their remaining SP is unknown.
+func (s *Service) applyUnallocatedSP(unallocatedSP int64, skillTypes map[string]model.SkillType, result *planEvaluationResult) {
+	estimate := result.TrainingEstimate
+	if unallocatedSP <= 0 || estimate == nil || estimate.RemainingSP == 0 {
+		return
+	}
+
+	result.UnallocatedGapClosed = progressPercent(min(unallocatedSP, estimate.RemainingSP), estimate.RemainingSP, false)
+	if unallocatedSP < estimate.RemainingSP || len(estimate.Skills) < len(result.MissingSkills) {
+		return
+	}
+	result.Allocation = s.allocationOrder(estimate.Skills, skillTypes)
+}
+
+// allocationOrder turns the remaining training into SP allocations,
+// prerequisites first: the client only lets SP be applied to a skill whose
+// prerequisites are trained.
+func (s *Service) allocationOrder(skills []model.SkillTrainingEstimate, skillTypes map[string]model.SkillType) []model.SPAllocation {
+	byID := make(map[int32]model.SkillTrainingEstimate, len(skills))
+	var order []int32
+	for _, skill := range skills {
+		skillType, exists := skillTypes[skill.SkillName]
+		if !exists {
+			continue
+		}
+		skillID, err := strconv.Atoi(skillType.TypeID)
+		if err != nil {
+			continue
+		}
+		byID[int32(skillID)] = skill
+		order = append(order, int32(skillID))
+	}
+
+	allocation := make([]model.SPAllocation, 0, len(order))
+	visited := make(map[int32]bool, len(order))
+	var visit func(skillID int32)
+	visit = func(skillID int32) {
+		skill, needed := byID[skillID]
+		if !needed || visited[skillID] {
+			return
+		}
+		visited[skillID] = true
+		for _, prereq := range s.skillRepo.GetSkillPrerequisites(skillID) {
+			visit(prereq.SkillID)
+		}
+		allocation = append(allocation, model.SPAllocation{
+			SkillName:   skill.SkillName,
+			FromLevel:   skill.CurrentLevel,
+			ToLevel:     skill.TargetLevel,
+			SkillPoints: skill.RemainingSP,
+		})
+	}
+	for _, skillID := range order {
+		visit(skillID)
+	}
+	return allocation
+}
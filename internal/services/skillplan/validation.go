@@ -0,0 +1,160 @@
+package skillplan
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/guarzo/canifly/internal/model"
+	"github.com/guarzo/canifly/internal/services/skillplans"
+)
+
+// maxSuggestions is the number of similar names offered for an unknown skill.
+const maxSuggestions = 3
+
+// ValidateSkillPlan checks plan content line by line: unparseable lines, skill
+// names missing from invTypes, types that are not skills and includes of plans
+// that do not exist. name is the plan's own name, used to recognise it among
+// the stored plans.
+func (s *Service) ValidateSkillPlan(name, contents string) model.PlanValidation {
+	parsed := skillplans.ParsePlan(contents)
+	skillTypes := s.skillRepo.GetSkillTypes()
+	candidates, skillsKnown := s.suggestionCandidates(skillTypes)
+	// Without SDE skill attributes every invTypes entry is assumed to be a skill.
+	isSkill := func(skillType model.SkillType) bool {
+		return !skillsKnown || s.isSkill(skillType)
+	}
+	var diagnostics []model.PlanDiagnostic
+
+	lines := strings.Split(contents, "\n")
+	for _, lineErr := range parsed.Errors {
+		diagnostic := model.PlanDiagnostic{Line: lineErr.Line, Text: lineErr.Text, Problem: lineErr.Reason}
+		// A bad level on a known skill is still worth flagging as a skill.
+		if fields := strings.Fields(lineErr.Text); len(fields) > 1 {
+			skillName := strings.Join(fields[:len(fields)-1], " ")
+			if skillType, exists := skillTypes[skillName]; exists {
+				diagnostic.IsSkill = isSkill(skillType)
+			}
+		}
+		diagnostics = append(diagnostics, diagnostic)
+	}
+
+	for i, step := range parsed.Steps {
+		line := parsed.StepLines[i]
+		text := strings.TrimSpace(lines[line-1])
+		skillType, exists := skillTypes[step.Name]
+		switch {
+		case !exists:
+			diagnostics = append(diagnostics, model.PlanDiagnostic{
+				Line:        line,
+				Text:        text,
+				Problem:     fmt.Sprintf("unknown skill %s", step.Name),
+				Suggestions: skillplans.Suggest(step.Name, candidates, maxSuggestions),
+			})
+		case !isSkill(skillType):
+			diagnostics = append(diagnostics, model.PlanDiagnostic{
+				Line:        line,
+				Text:        text,
+				Problem:     fmt.Sprintf("%s is not a skill", step.Name),
+				Suggestions: skillplans.Suggest(step.Name, candidates, maxSuggestions),
+			})
+		}
+	}
+
+	plans := s.skillRepo.GetSkillPlans()
+	for _, include := range parsed.Includes {
+		if _, exists := plans[include.Name]; exists || include.Name == name {
+			continue
+		}
+		planNames := make([]string, 0, len(plans))
+		for planName := range plans {
+			planNames = append(planNames, planName)
+		}
+		diagnostics = append(diagnostics, model.PlanDiagnostic{
+			Line:        include.Line,
+			Text:        strings.TrimSpace(lines[include.Line-1]),
+			Problem:     fmt.Sprintf("included plan %s not found", include.Name),
+			Suggestions: skillplans.Suggest(include.Name, planNames, maxSuggestions),
+		})
+	}
+
+	if len(parsed.Steps) == 0 && len(parsed.Includes) == 0 && len(diagnostics) == 0 {
+		diagnostics = append(diagnostics, model.PlanDiagnostic{Problem: "plan contains no skills"})
+	}
+
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		return diagnostics[i].Line < diagnostics[j].Line
+	})
+	return model.PlanValidation{
+		Name:        name,
+		Valid:       len(diagnostics) == 0,
+		Diagnostics: diagnostics,
+	}
+}
+
+// AuditSkillPlans validates every stored plan, sorted by name.
+func (s *Service) AuditSkillPlans() []model.PlanValidation {
+	plans := s.skillRepo.GetSkillPlans()
+	names := make([]string, 0, len(plans))
+	for name := range plans {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	validations := make([]model.PlanValidation, 0, len(names))
+	for _, name := range names {
+		content, err := s.skillRepo.GetSkillPlanFile(name)
+		if err != nil {
+			validations = append(validations, model.PlanValidation{
+				Name:        name,
+				Diagnostics: []model.PlanDiagnostic{{Problem: fmt.Sprintf("failed to read plan: %v", err)}},
+			})
+			continue
+		}
+		validations = append(validations, s.ValidateSkillPlan(name, string(content)))
+	}
+	return validations
+}
+
+// LogPlanDiagnostics warns about every problem in the stored plans. Invalid
+// plans are still loaded; this just makes typos visible.
+func (s *Service) LogPlanDiagnostics() {
+	for _, validation := range s.AuditSkillPlans() {
+		for _, diagnostic := range validation.Diagnostics {
+			if len(diagnostic.Suggestions) > 0 {
+				s.logger.Warnf("Skill plan %s line %d: %s (did you mean %s?)",
+					validation.Name, diagnostic.Line, diagnostic.Problem, strings.Join(diagnostic.Suggestions, ", "))
+				continue
+			}
+			s.logger.Warnf("Skill plan %s line %d: %s", validation.Name, diagnostic.Line, diagnostic.Problem)
+		}
+	}
+}
+
+// suggestionCandidates lists the names offered as suggestions: every skill
+// when SDE skill attributes are loaded, otherwise every invTypes name. The
+// second result reports whether skills could be told apart from other types.
+func (s *Service) suggestionCandidates(skillTypes map[string]model.SkillType) ([]string, bool) {
+	var skills, all []string
+	for name, skillType := range skillTypes {
+		all = append(all, name)
+		if s.isSkill(skillType) {
+			skills = append(skills, name)
+		}
+	}
+	if len(skills) > 0 {
+		return skills, true
+	}
+	return all, false
+}
+
+// isSkill reports whether a type has a training rank, which only skills do.
+func (s *Service) isSkill(skillType model.SkillType) bool {
+	typeID, err := strconv.Atoi(skillType.TypeID)
+	if err != nil {
+		return false
+	}
+	_, ok := s.skillRepo.GetSkillAttributes(int32(typeID))
+	return ok
+}
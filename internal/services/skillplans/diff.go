@@ -0,0 +1,37 @@
+package skillplans
+
+import (
+	"sort"
+
+	"github.com/guarzo/canifly/internal/model"
+)
+
+// DiffSteps compares two versions of a plan by the highest level of each
+// skill, returning skills added, removed, raised and lowered, sorted by name.
+func DiffSteps(from, to []model.Skill) (added, removed []model.Skill, raised, lowered []model.SkillLevelChange) {
+	fromSkills := IndexSteps(from)
+	toSkills := IndexSteps(to)
+
+	for name, skill := range toSkills {
+		previous, existed := fromSkills[name]
+		switch {
+		case !existed:
+			added = append(added, skill)
+		case skill.Level > previous.Level:
+			raised = append(raised, model.SkillLevelChange{Name: name, FromLevel: previous.Level, ToLevel: skill.Level})
+		case skill.Level < previous.Level:
+			lowered = append(lowered, model.SkillLevelChange{Name: name, FromLevel: previous.Level, ToLevel: skill.Level})
+		}
+	}
+	for name, skill := range fromSkills {
+		if _, exists := toSkills[name]; !exists {
+			removed = append(removed, skill)
+		}
+	}
+
+	sort.Slice(added, func(i, j int) bool { return added[i].Name < added[j].Name })
+	sort.Slice(removed, func(i, j int) bool { return removed[i].Name < removed[j].Name })
+	sort.Slice(raised, func(i, j int) bool { return raised[i].Name < raised[j].Name })
+	sort.Slice(lowered, func(i, j int) bool { return lowered[i].Name < lowered[j].Name })
+	return added, removed, raised, lowered
+}
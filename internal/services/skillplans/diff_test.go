@@ -0,0 +1,21 @@
+package skillplans
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/guarzo/canifly/internal/model"
+)
+
+func TestDiffSteps(t *testing.T) {
+	from := ParsePlan("Gunnery 3\nDrones 5\nNavigation 4\nGunnery 4\nEngineering 2\n").Steps
+	to := ParsePlan("Gunnery 5\nDrones 3\nEngineering 2\nCPU Management 4\n").Steps
+
+	added, removed, raised, lowered := DiffSteps(from, to)
+
+	assert.Equal(t, []model.Skill{{Name: "CPU Management", Level: 4}}, added)
+	assert.Equal(t, []model.Skill{{Name: "Navigation", Level: 4}}, removed)
+	assert.Equal(t, []model.SkillLevelChange{{Name: "Gunnery", FromLevel: 4, ToLevel: 5}}, raised)
+	assert.Equal(t, []model.SkillLevelChange{{Name: "Drones", FromLevel: 5, ToLevel: 3}}, lowered)
+}
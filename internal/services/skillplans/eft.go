@@ -0,0 +1,78 @@
+package skillplans
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// EFTFitting is a fitting in the EFT text format used by the EVE client and Pyfa.
+type EFTFitting struct {
+	Ship  string
+	Name  string
+	Items []EFTItem
+}
+
+// EFTItem is a module, charge, drone or cargo item of a fitting.
+type EFTItem struct {
+	Name     string
+	Quantity int
+}
+
+// eftQuantity matches drone and cargo lines such as "Hobgoblin II x5".
+var eftQuantity = regexp.MustCompile(`^(.+?)\s+x(\d+)$`)
+
+// ParseEFT parses an EFT fitting: a "[Ship, Name]" header followed by modules
+// (optionally with a loaded charge after a comma), then drones and cargo as
+// "Item xN". Empty slot placeholders and the /OFFLINE marker are ignored.
+func ParseEFT(content string) (*EFTFitting, error) {
+	var fitting *EFTFitting
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		if fitting == nil {
+			if !strings.HasPrefix(line, "[") || !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("fitting must start with a [Ship, Name] header")
+			}
+			ship, name, _ := strings.Cut(strings.Trim(line, "[]"), ",")
+			fitting = &EFTFitting{Ship: strings.TrimSpace(ship), Name: strings.TrimSpace(name)}
+			if fitting.Ship == "" {
+				return nil, fmt.Errorf("fitting header has no ship type")
+			}
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			// [Empty High slot] and similar placeholders
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimSuffix(line, "/OFFLINE"))
+
+		if match := eftQuantity.FindStringSubmatch(line); match != nil {
+			quantity, _ := strconv.Atoi(match[2])
+			fitting.Items = append(fitting.Items, EFTItem{Name: strings.TrimSpace(match[1]), Quantity: quantity})
+			continue
+		}
+
+		module, charge, hasCharge := strings.Cut(line, ",")
+		fitting.Items = append(fitting.Items, EFTItem{Name: strings.TrimSpace(module), Quantity: 1})
+		if hasCharge && strings.TrimSpace(charge) != "" {
+			fitting.Items = append(fitting.Items, EFTItem{Name: strings.TrimSpace(charge), Quantity: 1})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read fitting: %w", err)
+	}
+	if fitting == nil {
+		return nil, fmt.Errorf("fitting is empty")
+	}
+
+	return fitting, nil
+}
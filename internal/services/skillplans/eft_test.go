@@ -0,0 +1,41 @@
+package skillplans
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEFT(t *testing.T) {
+	fitting, err := ParseEFT(`[Rifter, Tackle / Fleet]
+Damage Control II
+[Empty Low slot]
+
+1MN Afterburner II
+Warp Scrambler II /OFFLINE
+
+200mm AutoCannon II, Republic Fleet EMP S
+
+Warrior II x2
+
+Nanite Repair Paste x50
+`)
+	require.NoError(t, err)
+	assert.Equal(t, "Rifter", fitting.Ship)
+	assert.Equal(t, "Tackle / Fleet", fitting.Name)
+	assert.Equal(t, []EFTItem{
+		{Name: "Damage Control II", Quantity: 1},
+		{Name: "1MN Afterburner II", Quantity: 1},
+		{Name: "Warp Scrambler II", Quantity: 1},
+		{Name: "200mm AutoCannon II", Quantity: 1},
+		{Name: "Republic Fleet EMP S", Quantity: 1},
+		{Name: "Warrior II", Quantity: 2},
+		{Name: "Nanite Repair Paste", Quantity: 50},
+	}, fitting.Items)
+
+	_, err = ParseEFT("Damage Control II")
+	assert.Error(t, err)
+	_, err = ParseEFT("   ")
+	assert.Error(t, err)
+}
@@ -0,0 +1,77 @@
+package skillplans
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// EVEMonPlan is an EVEMon plan document (.xml, or gzip-compressed .emp).
+type EVEMonPlan struct {
+	XMLName xml.Name      `xml:"plan"`
+	Name    string        `xml:"name,attr"`
+	Entries []EVEMonEntry `xml:"entry"`
+}
+
+// EVEMonEntry is a single skill level in an EVEMon plan.
+type EVEMonEntry struct {
+	SkillID  int32  `xml:"skillID,attr"`
+	Skill    string `xml:"skill,attr"`
+	Level    int    `xml:"level,attr"`
+	Priority int    `xml:"priority,attr"`
+	Type     string `xml:"type,attr"`
+}
+
+// MaxEVEMonPlanSize caps the size of a decompressed EVEMon plan.
+const MaxEVEMonPlanSize = 10 << 20
+
+// ErrEVEMonPlanTooLarge is returned for a .emp plan that decompresses to more
+// than MaxEVEMonPlanSize.
+var ErrEVEMonPlanTooLarge = errors.New("EVEMon plan is too large")
+
+// evemonDefaultPriority is the priority EVEMon gives entries added by hand.
+const evemonDefaultPriority = 3
+
+// DecodeEVEMonPlan parses an EVEMon plan, transparently decompressing .emp files.
+func DecodeEVEMonPlan(data []byte) (*EVEMonPlan, error) {
+	if len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b {
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to open gzip plan: %w", err)
+		}
+		defer reader.Close()
+		if data, err = io.ReadAll(io.LimitReader(reader, MaxEVEMonPlanSize+1)); err != nil {
+			return nil, fmt.Errorf("failed to decompress plan: %w", err)
+		}
+		if len(data) > MaxEVEMonPlanSize {
+			return nil, ErrEVEMonPlanTooLarge
+		}
+	}
+
+	var plan EVEMonPlan
+	if err := xml.Unmarshal(data, &plan); err != nil {
+		return nil, fmt.Errorf("failed to parse EVEMon plan: %w", err)
+	}
+	return &plan, nil
+}
+
+// EncodeEVEMonPlan writes a plan as EVEMon XML.
+func EncodeEVEMonPlan(plan EVEMonPlan) ([]byte, error) {
+	for i := range plan.Entries {
+		if plan.Entries[i].Priority == 0 {
+			plan.Entries[i].Priority = evemonDefaultPriority
+		}
+		if plan.Entries[i].Type == "" {
+			plan.Entries[i].Type = "Planned"
+		}
+	}
+
+	body, err := xml.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode EVEMon plan: %w", err)
+	}
+	return append([]byte(xml.Header), append(body, '\n')...), nil
+}
@@ -0,0 +1,69 @@
+package skillplans
+
+import (
+	"bytes"
+	"compress/gzip"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const evemonSample = `<?xml version="1.0" encoding="utf-8"?>
+<plan xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" name="Gunnery Basics" revision="4067">
+  <sorting criteria="None" order="None" groupByPriority="false" />
+  <entry skillID="3300" skill="Gunnery" level="1" priority="3" type="Prerequisite">
+    <notes>Gunnery</notes>
+  </entry>
+  <entry skillID="3301" skill="Small Hybrid Turret" level="2" priority="1" type="Planned" />
+</plan>`
+
+func TestDecodeEVEMonPlan(t *testing.T) {
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	_, err := gz.Write([]byte(evemonSample))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+
+	for name, data := range map[string][]byte{"xml": []byte(evemonSample), "emp": compressed.Bytes()} {
+		t.Run(name, func(t *testing.T) {
+			plan, err := DecodeEVEMonPlan(data)
+			require.NoError(t, err)
+			assert.Equal(t, "Gunnery Basics", plan.Name)
+			assert.Equal(t, []EVEMonEntry{
+				{SkillID: 3300, Skill: "Gunnery", Level: 1, Priority: 3, Type: "Prerequisite"},
+				{SkillID: 3301, Skill: "Small Hybrid Turret", Level: 2, Priority: 1, Type: "Planned"},
+			}, plan.Entries)
+		})
+	}
+
+	_, err = DecodeEVEMonPlan([]byte("Gunnery 5"))
+	assert.Error(t, err)
+}
+
+func TestDecodeEVEMonPlan_TooLarge(t *testing.T) {
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	_, err := gz.Write(bytes.Repeat([]byte(" "), MaxEVEMonPlanSize+1))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+
+	_, err = DecodeEVEMonPlan(compressed.Bytes())
+	assert.ErrorIs(t, err, ErrEVEMonPlanTooLarge)
+}
+
+func TestEncodeEVEMonPlan_RoundTrip(t *testing.T) {
+	data, err := EncodeEVEMonPlan(EVEMonPlan{Name: "Drones", Entries: []EVEMonEntry{
+		{SkillID: 3436, Skill: "Drones", Level: 1},
+		{SkillID: 3436, Skill: "Drones", Level: 2},
+	}})
+	require.NoError(t, err)
+
+	plan, err := DecodeEVEMonPlan(data)
+	require.NoError(t, err)
+	assert.Equal(t, "Drones", plan.Name)
+	assert.Equal(t, []EVEMonEntry{
+		{SkillID: 3436, Skill: "Drones", Level: 1, Priority: 3, Type: "Planned"},
+		{SkillID: 3436, Skill: "Drones", Level: 2, Priority: 3, Type: "Planned"},
+	}, plan.Entries)
+}
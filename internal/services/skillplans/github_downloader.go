@@ -20,6 +20,7 @@ type GitHubDownloader struct {
 	httpClient     *http.Client
 	logger         interfaces.Logger
 	circuitBreaker *CircuitBreaker
+	onDownload     func(planName string, previous, content []byte)
 }
 
 // NewGitHubDownloader creates a new GitHub downloader service
@@ -34,8 +35,70 @@ func NewGitHubDownloader(repoURL string, logger interfaces.Logger) *GitHubDownlo
 	}
 }
 
-// DownloadPlans downloads all skill plans from GitHub to the specified directory
+// OnDownload registers a callback run after each plan is written, with the
+// content it replaced (nil for a new plan) and the downloaded content.
+func (g *GitHubDownloader) OnDownload(callback func(planName string, previous, content []byte)) {
+	g.onDownload = callback
+}
+
+// DownloadPlans downloads all skill plans from GitHub to the specified
+// directory, followed by any plans they include that are still missing.
 func (g *GitHubDownloader) DownloadPlans(destDir string) error {
+	err := g.downloadPlans(destDir)
+	if g.repoURL != "" {
+		g.downloadMissingIncludes(destDir)
+	}
+	return err
+}
+
+// maxIncludeRounds bounds how deep a chain of missing includes is followed.
+const maxIncludeRounds = 5
+
+// downloadMissingIncludes fetches plans referenced by "# include:" directives
+// in destDir that have no local file yet, so includes resolve on load.
+func (g *GitHubDownloader) downloadMissingIncludes(destDir string) {
+	attempted := make(map[string]bool)
+	for round := 0; round < maxIncludeRounds; round++ {
+		entries, err := os.ReadDir(destDir)
+		if err != nil {
+			g.logger.Warnf("Failed to scan plans for includes: %v", err)
+			return
+		}
+
+		downloaded := 0
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".txt") {
+				continue
+			}
+			data, err := os.ReadFile(filepath.Join(destDir, entry.Name()))
+			if err != nil {
+				continue
+			}
+			for _, include := range ParsePlan(string(data)).Includes {
+				if attempted[include.Name] || strings.ContainsAny(include.Name, `/\`) {
+					continue
+				}
+				attempted[include.Name] = true
+
+				destPath := filepath.Join(destDir, include.Name+".txt")
+				if _, err := os.Stat(destPath); err == nil {
+					continue
+				}
+				if err := g.DownloadPlan(include.Name, destPath); err != nil {
+					g.logger.Warnf("Failed to download included plan %s: %v", include.Name, err)
+					continue
+				}
+				g.logger.Debugf("Downloaded included plan %s", include.Name)
+				downloaded++
+			}
+		}
+		if downloaded == 0 {
+			return
+		}
+	}
+}
+
+func (g *GitHubDownloader) downloadPlans(destDir string) error {
 	if g.repoURL == "" {
 		return fmt.Errorf("GitHub repository URL not configured")
 	}
@@ -196,19 +259,19 @@ func (g *GitHubDownloader) DownloadPlan(planName, destPath string) error {
 			return fmt.Errorf("failed to create directory: %w", err)
 		}
 
-		// Create the file
-		file, err := os.Create(destPath)
+		content, err := io.ReadAll(resp.Body)
 		if err != nil {
-			return fmt.Errorf("failed to create file: %w", err)
+			return fmt.Errorf("failed to read plan: %w", err)
 		}
-		defer file.Close()
 
-		// Copy the response body to the file
-		_, err = io.Copy(file, resp.Body)
-		if err != nil {
+		previous, _ := os.ReadFile(destPath)
+		if err := os.WriteFile(destPath, content, 0644); err != nil {
 			return fmt.Errorf("failed to write file: %w", err)
 		}
 
+		if g.onDownload != nil {
+			g.onDownload(planName, previous, content)
+		}
 		return nil
 	})
 }
@@ -0,0 +1,57 @@
+package skillplans
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/guarzo/canifly/internal/model"
+)
+
+// IncludeError reports an include that cannot be resolved, with the chain of
+// plans that led to it.
+type IncludeError struct {
+	Chain  []string
+	Reason string
+}
+
+func (e *IncludeError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Reason, strings.Join(e.Chain, " -> "))
+}
+
+// ResolveSteps returns a plan's steps with every included plan's steps spliced
+// in at the include directive, recursively. Conflicting levels are kept as
+// separate steps; IndexSteps keeps the higher one.
+func ResolveSteps(name string, plans map[string]ParsedPlan) ([]model.Skill, error) {
+	return resolveSteps(name, plans, nil)
+}
+
+func resolveSteps(name string, plans map[string]ParsedPlan, chain []string) ([]model.Skill, error) {
+	chain = append(chain, name)
+	for _, seen := range chain[:len(chain)-1] {
+		if seen == name {
+			return nil, &IncludeError{Chain: chain, Reason: "include cycle"}
+		}
+	}
+
+	plan, exists := plans[name]
+	if !exists {
+		return nil, &IncludeError{Chain: chain, Reason: fmt.Sprintf("included plan %s not found", name)}
+	}
+	if len(plan.Includes) == 0 {
+		return plan.Steps, nil
+	}
+
+	var steps []model.Skill
+	next := 0
+	for _, include := range plan.Includes {
+		steps = append(steps, plan.Steps[next:include.Position]...)
+		next = include.Position
+
+		included, err := resolveSteps(include.Name, plans, chain)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, included...)
+	}
+	return append(steps, plan.Steps[next:]...), nil
+}
@@ -0,0 +1,45 @@
+package skillplans
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/guarzo/canifly/internal/model"
+)
+
+func TestResolveSteps(t *testing.T) {
+	plans := map[string]ParsedPlan{
+		"Base":     ParsePlan("CPU Management 4\nPower Grid Management 4\n"),
+		"Support":  ParsePlan("# include: Base\nCPU Management 5\n"),
+		"Doctrine": ParsePlan("Spaceship Command 3\n# include: Support\nLogistics Cruisers 4\n"),
+	}
+
+	steps, err := ResolveSteps("Doctrine", plans)
+	require.NoError(t, err)
+	assert.Equal(t, []model.Skill{
+		{Name: "Spaceship Command", Level: 3},
+		{Name: "CPU Management", Level: 4},
+		{Name: "Power Grid Management", Level: 4},
+		{Name: "CPU Management", Level: 5},
+		{Name: "Logistics Cruisers", Level: 4},
+	}, steps)
+	assert.Equal(t, 5, IndexSteps(steps)["CPU Management"].Level, "higher level wins on conflicts")
+}
+
+func TestResolveSteps_Errors(t *testing.T) {
+	plans := map[string]ParsedPlan{
+		"A": ParsePlan("# include: B\nGunnery 1\n"),
+		"B": ParsePlan("# include: C\n"),
+		"C": ParsePlan("# include: A\n"),
+		"D": ParsePlan("# include: Missing\n"),
+	}
+
+	_, err := ResolveSteps("A", plans)
+	require.Error(t, err)
+	assert.EqualError(t, err, "include cycle: A -> B -> C -> A")
+
+	_, err = ResolveSteps("D", plans)
+	assert.EqualError(t, err, "included plan Missing not found: D -> Missing")
+}
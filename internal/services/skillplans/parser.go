@@ -0,0 +1,209 @@
+package skillplans
+
+import (
+	"bufio"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/guarzo/canifly/internal/model"
+)
+
+// ParsedPlan is the result of parsing a plan file: its skill steps in the order
+// they were written, the plans it includes, plus any lines that could not be
+// understood.
+type ParsedPlan struct {
+	Icon      string
+	Metadata  model.PlanMetadata
+	Steps     []model.Skill
+	StepLines []int // line number of each step
+	Includes  []PlanInclude
+	Errors    []LineError
+}
+
+// PlanInclude is an "# include: <PlanName>" directive. Position is the number
+// of the plan's own steps that precede it, which is where the included plan's
+// steps are spliced in.
+type PlanInclude struct {
+	Name     string
+	Position int
+	Line     int
+}
+
+// LineError describes a plan line that was skipped while parsing.
+type LineError struct {
+	Line   int
+	Text   string
+	Reason string
+}
+
+func (e LineError) Error() string {
+	return fmt.Sprintf("line %d: %s: %q", e.Line, e.Reason, e.Text)
+}
+
+var romanLevels = map[string]int{
+	"I": 1, "II": 2, "III": 3, "IV": 4, "V": 5,
+}
+
+// ParseSkillLevel parses a skill level written as a number or Roman numeral.
+func ParseSkillLevel(levelStr string) (int, error) {
+	level, ok := romanLevels[strings.ToUpper(levelStr)]
+	if !ok {
+		var err error
+		if level, err = strconv.Atoi(levelStr); err != nil {
+			return 0, fmt.Errorf("invalid skill level %q", levelStr)
+		}
+	}
+	if level < 1 || level > 5 {
+		return 0, fmt.Errorf("skill level %d out of range", level)
+	}
+	return level, nil
+}
+
+// ParseStepLevel parses a step's level, either a single level or
+// "required/recommended" such as "3/5". Required is 0 for a single level.
+func ParseStepLevel(levelStr string) (level, required int, err error) {
+	requiredStr, recommendedStr, split := strings.Cut(levelStr, "/")
+	if !split {
+		level, err = ParseSkillLevel(levelStr)
+		return level, 0, err
+	}
+	if required, err = ParseSkillLevel(requiredStr); err != nil {
+		return 0, 0, err
+	}
+	if level, err = ParseSkillLevel(recommendedStr); err != nil {
+		return 0, 0, err
+	}
+	if required > level {
+		return 0, 0, fmt.Errorf("required level %d is above recommended level %d", required, level)
+	}
+	return level, required, nil
+}
+
+// parseMetadata applies a "# key: value" front-matter directive. It returns
+// false when key is not a metadata key, leaving the line a plain comment.
+func parseMetadata(metadata *model.PlanMetadata, key, value string) (bool, error) {
+	switch key {
+	case "description":
+		metadata.Description = value
+	case "tags":
+		metadata.Tags = splitList(value)
+	case "owner":
+		metadata.Owner = value
+	case "doctrine":
+		metadata.Doctrine = value
+	case "roles":
+		metadata.Roles = splitList(value)
+	case "ship":
+		typeID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || typeID <= 0 {
+			return true, fmt.Errorf("invalid ship type ID %q", value)
+		}
+		metadata.ShipTypeID = typeID
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// splitList splits a comma separated directive value, dropping empty items.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// ParsePlan parses "Skill Name Level" lines, where Level may also be written
+// "required/recommended". Blank lines and # comments are ignored, except for
+// the "icon:" directive, which may also be written without the leading #,
+// "# include: <PlanName>" and the front-matter directives description, tags,
+// owner, doctrine, ship and roles. Every skill line becomes a step, so a plan
+// listing levels 1, 2 and 3 of a skill keeps all three in order.
+func ParsePlan(content string) ParsedPlan {
+	var parsed ParsedPlan
+
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+
+		directive := strings.TrimSpace(strings.TrimPrefix(line, "#"))
+		if strings.HasPrefix(directive, "icon:") {
+			parsed.Icon = strings.TrimSpace(strings.TrimPrefix(directive, "icon:"))
+			continue
+		}
+		if strings.HasPrefix(line, "#") && strings.HasPrefix(directive, "include:") {
+			include := strings.TrimSpace(strings.TrimPrefix(directive, "include:"))
+			if include == "" {
+				parsed.Errors = append(parsed.Errors, LineError{Line: lineNumber, Text: line, Reason: "include has no plan name"})
+				continue
+			}
+			parsed.Includes = append(parsed.Includes, PlanInclude{Name: include, Position: len(parsed.Steps), Line: lineNumber})
+			continue
+		}
+		if strings.HasPrefix(line, "#") {
+			if key, value, ok := strings.Cut(directive, ":"); ok {
+				known, err := parseMetadata(&parsed.Metadata, strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value))
+				if err != nil {
+					parsed.Errors = append(parsed.Errors, LineError{Line: lineNumber, Text: line, Reason: err.Error()})
+				}
+				if known {
+					continue
+				}
+			}
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.Fields(line)
+		if len(parts) < 2 {
+			parsed.Errors = append(parsed.Errors, LineError{Line: lineNumber, Text: line, Reason: "expected skill name and level"})
+			continue
+		}
+
+		level, required, err := ParseStepLevel(parts[len(parts)-1])
+		if err != nil {
+			parsed.Errors = append(parsed.Errors, LineError{Line: lineNumber, Text: line, Reason: err.Error()})
+			continue
+		}
+
+		parsed.Steps = append(parsed.Steps, model.Skill{
+			Name:     strings.Join(parts[:len(parts)-1], " "),
+			Level:    level,
+			Required: required,
+		})
+		parsed.StepLines = append(parsed.StepLines, lineNumber)
+	}
+	if err := scanner.Err(); err != nil {
+		parsed.Errors = append(parsed.Errors, LineError{Line: lineNumber + 1, Reason: err.Error()})
+	}
+
+	return parsed
+}
+
+// IndexSteps derives the per-skill index of a plan from its ordered steps,
+// keeping the highest recommended and the highest required level listed for
+// each skill.
+func IndexSteps(steps []model.Skill) map[string]model.Skill {
+	skills := make(map[string]model.Skill, len(steps))
+	for _, step := range steps {
+		current, exists := skills[step.Name]
+		if !exists {
+			skills[step.Name] = step
+			continue
+		}
+		level := max(current.Level, step.Level)
+		required := max(current.MinimumLevel(), step.MinimumLevel())
+		if required == level {
+			required = 0
+		}
+		skills[step.Name] = model.Skill{Name: step.Name, Level: level, Required: required}
+	}
+	return skills
+}
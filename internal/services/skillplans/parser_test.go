@@ -0,0 +1,67 @@
+package skillplans
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/guarzo/canifly/internal/model"
+)
+
+func TestParsePlan(t *testing.T) {
+	parsed := ParsePlan("# icon: ship\r\nGunnery 1\n\nSmall Hybrid Turret IV\n# comment\nGunnery 3\nMotion Prediction\nDrones 7\n# include: Magic 14\n")
+
+	assert.Equal(t, "ship", parsed.Icon)
+	assert.Equal(t, []model.Skill{
+		{Name: "Gunnery", Level: 1},
+		{Name: "Small Hybrid Turret", Level: 4},
+		{Name: "Gunnery", Level: 3},
+	}, parsed.Steps)
+	assert.Equal(t, []int{2, 4, 6}, parsed.StepLines)
+	assert.Equal(t, []PlanInclude{{Name: "Magic 14", Position: 3, Line: 9}}, parsed.Includes)
+	if assert.Len(t, parsed.Errors, 2) {
+		assert.Equal(t, 7, parsed.Errors[0].Line)
+		assert.Equal(t, 8, parsed.Errors[1].Line)
+	}
+
+	assert.Equal(t, map[string]model.Skill{
+		"Gunnery":             {Name: "Gunnery", Level: 3},
+		"Small Hybrid Turret": {Name: "Small Hybrid Turret", Level: 4},
+	}, IndexSteps(parsed.Steps))
+}
+
+func TestParsePlan_Metadata(t *testing.T) {
+	parsed := ParsePlan(`# description: Fleet Cerberus
+# tags: HAC, Caldari ,
+# owner: Fleet Command
+# doctrine: Cerbs
+# ship: 11993
+# roles: DPS, Anchor
+# Just a comment: with a colon
+Heavy Assault Cruisers 3/5
+Caldari Cruiser 5/4
+# ship: Cerberus
+`)
+
+	assert.Equal(t, model.PlanMetadata{
+		Description: "Fleet Cerberus",
+		Tags:        []string{"HAC", "Caldari"},
+		Owner:       "Fleet Command",
+		Doctrine:    "Cerbs",
+		ShipTypeID:  11993,
+		Roles:       []string{"DPS", "Anchor"},
+	}, parsed.Metadata)
+	assert.Equal(t, []model.Skill{{Name: "Heavy Assault Cruisers", Level: 5, Required: 3}}, parsed.Steps)
+	if assert.Len(t, parsed.Errors, 2) {
+		assert.Equal(t, 9, parsed.Errors[0].Line)
+		assert.Equal(t, 10, parsed.Errors[1].Line)
+	}
+
+	assert.Equal(t, map[string]model.Skill{
+		"Gunnery": {Name: "Gunnery", Level: 5, Required: 4},
+	}, IndexSteps([]model.Skill{
+		{Name: "Gunnery", Level: 3},
+		{Name: "Gunnery", Level: 5, Required: 4},
+		{Name: "Gunnery", Level: 4, Required: 2},
+	}))
+}
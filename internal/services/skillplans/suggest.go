@@ -0,0 +1,64 @@
+package skillplans
+
+import (
+	"sort"
+	"strings"
+)
+
+// EditDistance is the case-insensitive Levenshtein distance between a and b.
+func EditDistance(a, b string) int {
+	ra := []rune(strings.ToLower(a))
+	rb := []rune(strings.ToLower(b))
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// Suggest returns up to limit candidates closest to name by edit distance,
+// ignoring candidates too far off to be a plausible typo.
+func Suggest(name string, candidates []string, limit int) []string {
+	maxDistance := max(2, len(name)/3)
+
+	type scored struct {
+		name     string
+		distance int
+	}
+	var matches []scored
+	for _, candidate := range candidates {
+		// Length difference is a lower bound on the distance; skip early.
+		if diff := len(candidate) - len(name); diff > maxDistance || -diff > maxDistance {
+			continue
+		}
+		if d := EditDistance(name, candidate); d <= maxDistance {
+			matches = append(matches, scored{candidate, d})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].distance != matches[j].distance {
+			return matches[i].distance < matches[j].distance
+		}
+		return matches[i].name < matches[j].name
+	})
+
+	suggestions := make([]string, 0, limit)
+	for i := 0; i < len(matches) && i < limit; i++ {
+		suggestions = append(suggestions, matches[i].name)
+	}
+	return suggestions
+}
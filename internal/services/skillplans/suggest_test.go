@@ -0,0 +1,23 @@
+package skillplans
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, EditDistance("Drones", "drones"))
+	assert.Equal(t, 1, EditDistance("Spaceshp Command", "Spaceship Command"))
+	assert.Equal(t, 3, EditDistance("kitten", "sitting"))
+	assert.Equal(t, 6, EditDistance("", "Drones"))
+}
+
+func TestSuggest(t *testing.T) {
+	candidates := []string{"Drones", "Drone Interfacing", "Gunnery", "Drone Avionics", "Dromes"}
+
+	assert.Equal(t, []string{"Drones"}, Suggest("Dornes", candidates, 3))
+	assert.Equal(t, []string{"Dromes", "Drones"}, Suggest("Drmes", candidates, 3))
+	assert.Equal(t, []string{"Drones"}, Suggest("drones", candidates, 1))
+	assert.Empty(t, Suggest("Capital Ships", candidates, 3))
+}
@@ -1,6 +1,7 @@
 package testutil
 
 import (
+	"context"
 	"net/http"
 	"time"
 
@@ -34,37 +35,81 @@ func (m *MockAssociationService) UnassociateCharacter(userId, charId string) err
 	return args.Error(0)
 }
 
-// MockESIService mocks interfaces.ESIService
+// MockESIService mocks interfaces.ESIService. Expectations are set without
+// the context argument.
 type MockESIService struct {
 	mock.Mock
 }
 
-func (m *MockESIService) GetUserInfo(token *oauth2.Token) (*model.UserInfoResponse, error) {
+func (m *MockESIService) GetUserInfo(_ context.Context, token *oauth2.Token) (*model.UserInfoResponse, error) {
 	args := m.Called(token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
 	return args.Get(0).(*model.UserInfoResponse), args.Error(1)
 }
 
-func (m *MockESIService) GetCharacter(id string) (*model.CharacterResponse, error) {
+func (m *MockESIService) GetCharacter(_ context.Context, id string) (*model.CharacterResponse, error) {
 	args := m.Called(id)
 	return args.Get(0).(*model.CharacterResponse), args.Error(1)
 }
 
-func (m *MockESIService) GetCharacterSkills(characterID int64, token *oauth2.Token) (*model.CharacterSkillsResponse, error) {
+func (m *MockESIService) GetCharacterSkills(_ context.Context, characterID int64, token *oauth2.Token) (*model.CharacterSkillsResponse, error) {
 	args := m.Called(characterID, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
 	return args.Get(0).(*model.CharacterSkillsResponse), args.Error(1)
 }
 
-func (m *MockESIService) GetCharacterSkillQueue(characterID int64, token *oauth2.Token) (*[]model.SkillQueue, error) {
+func (m *MockESIService) GetCharacterSkillQueue(_ context.Context, characterID int64, token *oauth2.Token) (*[]model.SkillQueue, error) {
 	args := m.Called(characterID, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
 	return args.Get(0).(*[]model.SkillQueue), args.Error(1)
 }
 
-func (m *MockESIService) GetCharacterLocation(characterID int64, token *oauth2.Token) (int64, error) {
+func (m *MockESIService) GetCharacterLocation(_ context.Context, characterID int64, token *oauth2.Token) (int64, error) {
 	args := m.Called(characterID, token)
 	return args.Get(0).(int64), args.Error(1)
 }
 
-func (m *MockESIService) ResolveCharacterNames(charIds []string) (map[string]string, error) {
+func (m *MockESIService) GetCharacterAttributes(_ context.Context, characterID int64, token *oauth2.Token) (*model.CharacterAttributes, error) {
+	args := m.Called(characterID, token)
+	return args.Get(0).(*model.CharacterAttributes), args.Error(1)
+}
+
+func (m *MockESIService) GetCharacterImplants(_ context.Context, characterID int64, token *oauth2.Token) ([]int32, error) {
+	args := m.Called(characterID, token)
+	return args.Get(0).([]int32), args.Error(1)
+}
+
+func (m *MockESIService) GetCharacterClones(_ context.Context, characterID int64, token *oauth2.Token) (*model.CloneLocation, error) {
+	args := m.Called(characterID, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.CloneLocation), args.Error(1)
+}
+
+func (m *MockESIService) GetStation(_ context.Context, id int64) (*model.Station, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Station), args.Error(1)
+}
+
+func (m *MockESIService) GetStructure(_ context.Context, id int64, token *oauth2.Token) (*model.Structure, error) {
+	args := m.Called(id, token)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Structure), args.Error(1)
+}
+
+func (m *MockESIService) ResolveCharacterNames(_ context.Context, charIds []string) (map[string]string, error) {
 	args := m.Called(charIds)
 	return args.Get(0).(map[string]string), args.Error(1)
 }
@@ -74,12 +119,12 @@ func (m *MockESIService) SaveEsiCache() error {
 	return args.Error(0)
 }
 
-func (m *MockESIService) GetCorporation(id int64, token *oauth2.Token) (*model.Corporation, error) {
+func (m *MockESIService) GetCorporation(_ context.Context, id int64, token *oauth2.Token) (*model.Corporation, error) {
 	args := m.Called(id, token)
 	return args.Get(0).(*model.Corporation), args.Error(1)
 }
 
-func (m *MockESIService) GetAlliance(id int64, token *oauth2.Token) (*model.Alliance, error) {
+func (m *MockESIService) GetAlliance(_ context.Context, id int64, token *oauth2.Token) (*model.Alliance, error) {
 	args := m.Called(id, token)
 	return args.Get(0).(*model.Alliance), args.Error(1)
 }
@@ -111,11 +156,55 @@ func (m *MockSkillService) GetSkillTypes() map[string]model.SkillType {
 	return args.Get(0).(map[string]model.SkillType)
 }
 
-func (m *MockSkillService) ParseAndSaveSkillPlan(contents, name string) error {
-	args := m.Called(contents, name)
+func (m *MockSkillService) ParseAndSaveSkillPlan(contents, name string, source model.PlanSource) error {
+	args := m.Called(contents, name, source)
+	return args.Error(0)
+}
+
+func (m *MockSkillService) GetSkillPlanHistory(name string) ([]model.PlanVersion, error) {
+	args := m.Called(name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.PlanVersion), args.Error(1)
+}
+
+func (m *MockSkillService) DiffSkillPlan(name, from, to string) (*model.PlanDiff, error) {
+	args := m.Called(name, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.PlanDiff), args.Error(1)
+}
+
+func (m *MockSkillService) RestoreSkillPlan(name, versionID string) error {
+	args := m.Called(name, versionID)
 	return args.Error(0)
 }
 
+func (m *MockSkillService) PlanTrainingQueue(character model.Character, status model.AccountStatus, planName string) ([]model.TrainingStep, error) {
+	args := m.Called(character, status, planName)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.TrainingStep), args.Error(1)
+}
+
+func (m *MockSkillService) RecommendNextSkills(character model.Character, status model.AccountStatus, request model.SkillRecommendationRequest) []model.SkillRecommendation {
+	args := m.Called(character, status, request)
+	return args.Get(0).([]model.SkillRecommendation)
+}
+
+func (m *MockSkillService) ValidateSkillPlan(name, contents string) model.PlanValidation {
+	args := m.Called(name, contents)
+	return args.Get(0).(model.PlanValidation)
+}
+
+func (m *MockSkillService) AuditSkillPlans() []model.PlanValidation {
+	args := m.Called()
+	return args.Get(0).([]model.PlanValidation)
+}
+
 func (m *MockSkillService) GetSkillPlanFile(name string) ([]byte, error) {
 	args := m.Called(name)
 	return args.Get(0).([]byte), args.Error(1)
@@ -131,6 +220,79 @@ func (m *MockSkillService) GetSkillTypeByID(id string) (model.SkillType, bool) {
 	return args.Get(0).(model.SkillType), args.Bool(1)
 }
 
+func (m *MockSkillService) GetExpandedSkillPlan(name string) (model.SkillPlan, bool) {
+	args := m.Called(name)
+	return args.Get(0).(model.SkillPlan), args.Bool(1)
+}
+
+func (m *MockSkillService) GetRemapAdvice(character model.Character, status model.AccountStatus, planName string) (*model.RemapAdvice, error) {
+	args := m.Called(character, status, planName)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.RemapAdvice), args.Error(1)
+}
+
+func (m *MockSkillService) PlanFromFitting(fitting string) (model.SkillPlan, []string, error) {
+	args := m.Called(fitting)
+	return args.Get(0).(model.SkillPlan), args.Get(1).([]string), args.Error(2)
+}
+
+func (m *MockSkillService) SaveFittingPlan(fitting, name string) (model.SkillPlan, []string, error) {
+	args := m.Called(fitting, name)
+	return args.Get(0).(model.SkillPlan), args.Get(1).([]string), args.Error(2)
+}
+
+func (m *MockSkillService) EvaluateFitting(accounts []model.Account, fitting string) (*model.FittingEvaluation, error) {
+	args := m.Called(accounts, fitting)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.FittingEvaluation), args.Error(1)
+}
+
+func (m *MockSkillService) GetShipStatus(accounts []model.Account, typeName string) (*model.SkillPlanWithStatus, error) {
+	args := m.Called(accounts, typeName)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.SkillPlanWithStatus), args.Error(1)
+}
+
+func (m *MockSkillService) ImportEVEMonPlan(data []byte, name string) (string, error) {
+	args := m.Called(data, name)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockSkillService) ExportEVEMonPlan(name string) ([]byte, error) {
+	args := m.Called(name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (m *MockSkillService) ExportTextPlan(name string) ([]byte, error) {
+	args := m.Called(name)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (m *MockSkillService) ExportSkillQueue(character model.Character, status model.AccountStatus, planName string) (string, error) {
+	args := m.Called(character, status, planName)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockSkillService) CompareTrainingScenario(character model.Character, status model.AccountStatus, planName string, scenario model.TrainingScenario) (*model.TrainingScenarioComparison, error) {
+	args := m.Called(character, status, planName, scenario)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.TrainingScenarioComparison), args.Error(1)
+}
+
 func (m *MockSkillService) GetPlanAndConversionData(
 	accounts []model.Account,
 	skillPlans map[string]model.SkillPlan,
@@ -142,6 +304,19 @@ func (m *MockSkillService) GetPlanAndConversionData(
 	return args.Get(0).(map[string]model.SkillPlanWithStatus), args.Get(1).(map[string]string)
 }
 
+func (m *MockSkillService) ListSkillPlans() ([]string, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockSkillService) RefreshRemotePlans() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
 // MockAccountService mocks interfaces.AccountService
 type MockAccountService struct {
 	mock.Mock
@@ -210,6 +385,24 @@ func (m *MockAccountManagementService) FindOrCreateAccount(accountName string, c
 	return args.Error(0)
 }
 
+func (m *MockAccountManagementService) GetAccountByID(accountID int64) (*model.Account, error) {
+	args := m.Called(accountID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Account), args.Error(1)
+}
+
+func (m *MockAccountManagementService) UpdateAccount(accountID int64, updates interfaces.AccountUpdateRequest) error {
+	args := m.Called(accountID, updates)
+	return args.Error(0)
+}
+
+func (m *MockAccountManagementService) RemoveAccountByID(accountID int64) error {
+	args := m.Called(accountID)
+	return args.Error(0)
+}
+
 func (m *MockAccountManagementService) UpdateAccountName(accountID int64, accountName string) error {
 	args := m.Called(accountID, accountName)
 	return args.Error(0)
@@ -279,6 +472,15 @@ func (m *MockAccountManagementService) GetAssociations() ([]model.Association, e
 	return args.Get(0).([]model.Association), args.Error(1)
 }
 
+// MockBroadcaster mocks interfaces.Broadcaster
+type MockBroadcaster struct {
+	mock.Mock
+}
+
+func (m *MockBroadcaster) BroadcastUpdate(updateType string, data interface{}) {
+	m.Called(updateType, data)
+}
+
 // MockConfigService mocks interfaces.ConfigService
 type MockConfigService struct {
 	mock.Mock
@@ -342,12 +544,32 @@ func (m *MockConfigService) FetchConfigData() (*model.ConfigData, error) {
 	return args.Get(0).(*model.ConfigData), args.Error(1)
 }
 
+func (m *MockConfigService) SaveInjectorPrices(prices model.InjectorPrices) error {
+	args := m.Called(prices)
+	return args.Error(0)
+}
+
+func (m *MockConfigService) NeedsEVEConfiguration() (bool, error) {
+	args := m.Called()
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockConfigService) SaveEVECredentials(clientID, clientSecret, callbackURL string) error {
+	args := m.Called(clientID, clientSecret, callbackURL)
+	return args.Error(0)
+}
+
+func (m *MockConfigService) GetEVECredentials() (clientID, clientSecret, callbackURL string, err error) {
+	args := m.Called()
+	return args.String(0), args.String(1), args.String(2), args.Error(3)
+}
+
 // MockEveProfilesService mocks interfaces.EveProfilesService
 type MockEveProfilesService struct {
 	mock.Mock
 }
 
-func (m *MockEveProfilesService) LoadCharacterSettings() ([]model.EveProfile, error) {
+func (m *MockEveProfilesService) LoadCharacterSettings(_ context.Context) ([]model.EveProfile, error) {
 	args := m.Called()
 	return args.Get(0).([]model.EveProfile), args.Error(1)
 }
@@ -452,6 +674,11 @@ func (m *MockCacheService) SaveCache() error {
 	return args.Error(0)
 }
 
+func (m *MockCacheService) SaveEsiCache() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
 // MockEveProfilesRepository is a mock implementation of the EveProfilesRepository interface.
 type MockEveProfilesRepository struct {
 	mock.Mock
@@ -501,11 +728,27 @@ func (m *MockSkillRepository) GetSkillTypes() map[string]model.SkillType {
 	return args.Get(0).(map[string]model.SkillType)
 }
 
-func (m *MockSkillRepository) SaveSkillPlan(planName string, skills map[string]model.Skill) error {
-	args := m.Called(planName, skills)
+func (m *MockSkillRepository) SaveSkillPlan(planName string, content []byte, source model.PlanSource) error {
+	args := m.Called(planName, content, source)
 	return args.Error(0)
 }
 
+func (m *MockSkillRepository) GetSkillPlanHistory(planName string) ([]model.PlanVersion, error) {
+	args := m.Called(planName)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.PlanVersion), args.Error(1)
+}
+
+func (m *MockSkillRepository) GetSkillPlanVersion(planName, versionID string) ([]byte, error) {
+	args := m.Called(planName, versionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
 func (m *MockSkillRepository) DeleteSkillPlan(planName string) error {
 	args := m.Called(planName)
 	return args.Error(0)
@@ -516,6 +759,36 @@ func (m *MockSkillRepository) GetSkillTypeByID(id string) (model.SkillType, bool
 	return args.Get(0).(model.SkillType), args.Bool(1)
 }
 
+func (m *MockSkillRepository) GetSkillAttributes(typeID int32) (model.SkillAttributes, bool) {
+	args := m.Called(typeID)
+	return args.Get(0).(model.SkillAttributes), args.Bool(1)
+}
+
+func (m *MockSkillRepository) GetAttributeBonus(typeID int32) (model.CharacterAttributes, bool) {
+	args := m.Called(typeID)
+	return args.Get(0).(model.CharacterAttributes), args.Bool(1)
+}
+
+func (m *MockSkillRepository) GetAlphaSkillCap(typeID int32) (int32, bool) {
+	args := m.Called(typeID)
+	return args.Get(0).(int32), args.Bool(1)
+}
+
+func (m *MockSkillRepository) IsShipType(typeID int32) (bool, bool) {
+	args := m.Called(typeID)
+	return args.Bool(0), args.Bool(1)
+}
+
+func (m *MockSkillRepository) LoadSkillPlans() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *MockSkillRepository) GetSkillPrerequisites(typeID int32) []model.SkillPrerequisite {
+	args := m.Called(typeID)
+	return args.Get(0).([]model.SkillPrerequisite)
+}
+
 // MockSessionService simulates the behavior of SessionService.
 // Now it returns a real *sessions.Session instead of a mock session.
 type MockSessionService struct {